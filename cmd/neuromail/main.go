@@ -1,17 +1,31 @@
+// Команда neuromail - CLI для временных почтовых ящиков MailSlurp. Без
+// аргументов запускает пример отправки и получения письма.
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"os"
 	"time"
+
+	mailslurp "mailslurp-example"
 )
 
 func main() {
-	// Ваш API ключ MailSlurp
-	apiKey := "fac5b6d2020a14edc74b54e9f1b09513df1c2ca3fc1901ec9e5933df11052d5a"
+	// Если переданы аргументы, работаем как CLI (например, neuromail wait ...)
+	if len(os.Args) > 1 {
+		os.Exit(mailslurp.RunCLI(os.Args[1:], os.Stdout, os.Stderr))
+	}
+	
+	// API ключ MailSlurp берется из профиля конфигурации или NEUROMAIL_API_KEY
+	profile, err := mailslurp.ResolveProfile("")
+	if err != nil {
+		log.Fatalf("Ошибка конфигурации: %v", err)
+	}
 	
 	// Создаем клиент MailSlurp
-	client := NewMailSlurpClient(apiKey)
+	client := mailslurp.NewMailSlurpClientFromProfile(profile)
 	
 	fmt.Println("Начинаем работу с MailSlurp API...")
 	
@@ -27,8 +41,7 @@ func main() {
 		if err != nil {
 			log.Printf("Ошибка при создании почтового ящика: %v", err)
 			
-			if err.Error() == "ошибка API: ограничение бесплатного плана" || 
-			   err.Error() == "ошибка API: PLAN_LIMIT_EXCEEDED" {
+			if errors.Is(err, mailslurp.ErrPlanLimitExceeded) {
 				fmt.Println("\nПохоже, что вы достигли лимита бесплатного плана MailSlurp.")
 				fmt.Println("Бесплатный план имеет ограничения на количество почтовых ящиков и операций.")
 				fmt.Println("Для продолжения работы вы можете:")
@@ -63,8 +76,7 @@ func main() {
 		if err != nil {
 			log.Printf("Ошибка при создании почтового ящика: %v", err)
 			
-			if err.Error() == "ошибка API: ограничение бесплатного плана" || 
-			   err.Error() == "ошибка API: PLAN_LIMIT_EXCEEDED" {
+			if errors.Is(err, mailslurp.ErrPlanLimitExceeded) {
 				fmt.Println("\nПохоже, что вы достигли лимита бесплатного плана MailSlurp.")
 				fmt.Println("Бесплатный план имеет ограничения на количество почтовых ящиков и операций.")
 				fmt.Println("Для продолжения работы вы можете:")
@@ -86,7 +98,7 @@ func main() {
 }
 
 // Функция для отправки и получения тестового письма
-func sendAndReceiveEmail(client MailSlurpClient, inboxID, emailAddress string) {
+func sendAndReceiveEmail(client mailslurp.MailSlurpClient, inboxID, emailAddress string) {
 	// Отправляем тестовое письмо
 	fmt.Println("\nОтправляем тестовое письмо...")
 	err := client.SendEmail(inboxID, emailAddress, "Тестовое письмо", "Это тестовое письмо, отправленное через MailSlurp API")
@@ -97,9 +109,8 @@ func sendAndReceiveEmail(client MailSlurpClient, inboxID, emailAddress string) {
 	
 	fmt.Println("Письмо отправлено. Ожидаем его получения...")
 	
-	// Ждем и получаем письмо
+	// Ждем и получаем письмо: WaitForLatestEmail сам ждет доставки до таймаута
 	fmt.Println("\nОжидаем получения письма (до 30 секунд)...")
-	time.Sleep(5 * time.Second) // Даем время на доставку письма
 	
 	email, err := client.WaitForLatestEmail(inboxID, 30*time.Second)
 	if err != nil {
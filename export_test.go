@@ -0,0 +1,67 @@
+package mailslurp
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Внутренности пакета для внешних тестов mailslurp_test, которые используют
+// mailslurptest и поэтому не могут находиться в самом пакете.
+
+const (
+	ExitOK      = exitOK
+	ExitFailure = exitFailure
+	ExitUsage   = exitUsage
+)
+
+var (
+	HasTag                = hasTag
+	RussianFemaleLastName = russianFemaleLastName
+	Transliterate         = transliterate
+)
+
+type JUnitTestSuites = junitTestSuites
+
+// UseCLIClient подменяет клиент команд CLI до конца теста и направляет
+// конфигурацию во временный файл
+func UseCLIClient(t *testing.T, client MailSlurpClient) {
+	t.Setenv(envConfigPath, filepath.Join(t.TempDir(), "config.json"))
+	orig := newCLIClient
+	newCLIClient = func(*Profile) MailSlurpClient { return client }
+	t.Cleanup(func() { newCLIClient = orig })
+}
+
+// SetClock подменяет часы ограничителя
+func (l *RateLimiter) SetClock(now func() time.Time) {
+	l.now = now
+}
+
+// Reserve резервирует токен и возвращает задержку
+func (l *RateLimiter) Reserve() time.Duration {
+	return l.reserve()
+}
+
+// SetClock подменяет часы кэширующего клиента
+func (c *CachingMailSlurpClient) SetClock(now func() time.Time) {
+	c.now = now
+}
+
+// SetClock подменяет часы клиента с метриками
+func (c *InstrumentedMailSlurpClient) SetClock(now func() time.Time) {
+	c.now = now
+}
+
+// PendingDeliveries возвращает число отправленных писем, ожидающих получения
+func (c *InstrumentedMailSlurpClient) PendingDeliveries() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.pending)
+}
+
+// Closed сообщает, что пул закрыт
+func (p *InboxPool) Closed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
@@ -0,0 +1,173 @@
+package mailslurp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// PlanLimits описывает лимиты тарифного плана (совпадают с ApiKeyManager.getPlanLimits во frontend)
+type PlanLimits struct {
+	MaxInboxes             int  `json:"maxInboxes"`
+	MaxEmailsPerDay        int  `json:"maxEmailsPerDay"`
+	MaxStorageDays         int  `json:"maxStorageDays"`
+	MaxAttachmentSizeMB    int  `json:"maxAttachmentSize"`
+	CanCreateCustomDomains bool `json:"canCreateCustomDomains"`
+	HasAdvancedAnalytics   bool `json:"hasAdvancedAnalytics"`
+}
+
+// planLimits - лимиты тарифных планов
+var planLimits = map[string]PlanLimits{
+	"free":         {MaxInboxes: 2, MaxEmailsPerDay: 20, MaxStorageDays: 1, MaxAttachmentSizeMB: 1},
+	"basic":        {MaxInboxes: 10, MaxEmailsPerDay: 100, MaxStorageDays: 7, MaxAttachmentSizeMB: 5},
+	"professional": {MaxInboxes: 50, MaxEmailsPerDay: 500, MaxStorageDays: 30, MaxAttachmentSizeMB: 20, CanCreateCustomDomains: true, HasAdvancedAnalytics: true},
+	"enterprise":   {MaxInboxes: 500, MaxEmailsPerDay: 5000, MaxStorageDays: 90, MaxAttachmentSizeMB: 100, CanCreateCustomDomains: true, HasAdvancedAnalytics: true},
+}
+
+// GetPlanLimits возвращает лимиты плана или лимиты basic, если план неизвестен
+func GetPlanLimits(plan string) PlanLimits {
+	if limits, ok := planLimits[plan]; ok {
+		return limits
+	}
+	return planLimits["basic"]
+}
+
+// AccountInfo представляет собой информацию об аккаунте и использовании квоты
+type AccountInfo struct {
+	ID               string    `json:"id"`
+	EmailAddress     string    `json:"emailAddress"`
+	AccountState     string    `json:"accountState"`
+	SubscriptionType string    `json:"subscriptionType"`
+	CreatedAt        time.Time `json:"createdAt"`
+
+	// Plan - тарифный план из профиля, по нему определяются Limits
+	Plan   string     `json:"plan"`
+	Limits PlanLimits `json:"limits"`
+
+	InboxCount int `json:"inboxCount"`
+	EmailCount int `json:"emailCount"`
+}
+
+// InboxesRemaining возвращает, сколько еще ящиков можно создать по лимиту плана
+func (a *AccountInfo) InboxesRemaining() int {
+	if remaining := a.Limits.MaxInboxes - a.InboxCount; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// ErrAccountInfoNotSupported - декорируемый клиент не умеет получать
+// информацию об аккаунте
+var ErrAccountInfoNotSupported = errors.New("клиент не поддерживает получение информации об аккаунте")
+
+// AccountInfoProvider определяет клиент, который умеет получать информацию об аккаунте.
+// Декораторы реализуют его всегда и возвращают ErrAccountInfoNotSupported, если
+// обернутый клиент его не поддерживает.
+type AccountInfoProvider interface {
+	GetAccountInfo() (*AccountInfo, error)
+}
+
+// getAccountInfo получает информацию об аккаунте, если клиент это поддерживает
+func getAccountInfo(client MailSlurpClient) (*AccountInfo, error) {
+	provider, ok := client.(AccountInfoProvider)
+	if !ok {
+		return nil, ErrAccountInfoNotSupported
+	}
+	return provider.GetAccountInfo()
+}
+
+// GetAccountInfo получает информацию об аккаунте, количество ящиков и писем
+func (c *DefaultMailSlurpClient) GetAccountInfo() (*AccountInfo, error) {
+	var user struct {
+		ID               string `json:"id"`
+		EmailAddress     string `json:"emailAddress"`
+		AccountState     string `json:"accountState"`
+		SubscriptionType string `json:"subscriptionType"`
+		CreatedAt        string `json:"createdAt"`
+	}
+	if err := c.execute(apiCall{operation: "GetAccountInfo", method: "GET", path: "/user/info"}, &user); err != nil {
+		return nil, err
+	}
+
+	var inboxCount, emailCount struct {
+		TotalElements int `json:"totalElements"`
+	}
+	if err := c.execute(apiCall{operation: "GetInboxCount", method: "GET", path: "/inboxes/count"}, &inboxCount); err != nil {
+		return nil, err
+	}
+	if err := c.execute(apiCall{operation: "GetEmailCount", method: "GET", path: "/emails/count"}, &emailCount); err != nil {
+		return nil, err
+	}
+
+	plan := c.plan
+	if plan == "" {
+		plan = "basic"
+	}
+	createdAt, _ := time.Parse(time.RFC3339, user.CreatedAt)
+	return &AccountInfo{
+		ID:               user.ID,
+		EmailAddress:     user.EmailAddress,
+		AccountState:     user.AccountState,
+		SubscriptionType: user.SubscriptionType,
+		CreatedAt:        createdAt,
+		Plan:             plan,
+		Limits:           GetPlanLimits(plan),
+		InboxCount:       inboxCount.TotalElements,
+		EmailCount:       emailCount.TotalElements,
+	}, nil
+}
+
+// ConnectionStatus представляет собой состояние подключения к API
+// (аналог объекта connectionStatus во frontend)
+type ConnectionStatus struct {
+	Connected   bool          `json:"isConnected"`
+	APIType     string        `json:"apiType"`
+	LastChecked time.Time     `json:"lastChecked"`
+	Latency     time.Duration `json:"latency"`
+	Error       string        `json:"error,omitempty"`
+	Account     *AccountInfo  `json:"data,omitempty"`
+}
+
+// CheckConnection проверяет доступность API и валидность ключа.
+// apiType - тип ключа для отображения: "personal" или "public".
+// Если клиент умеет получать информацию об аккаунте, она попадает в статус.
+func CheckConnection(client MailSlurpClient, apiType string) ConnectionStatus {
+	status := ConnectionStatus{APIType: apiType, LastChecked: time.Now()}
+
+	var err error
+	status.Account, err = getAccountInfo(client)
+	if errors.Is(err, ErrAccountInfoNotSupported) {
+		_, err = client.GetInboxes()
+	}
+	status.Latency = time.Since(status.LastChecked)
+
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Connected = true
+	return status
+}
+
+// Ping проверяет доступность API и возвращает ошибку, если подключиться не удалось
+func Ping(client MailSlurpClient) error {
+	_, err := getAccountInfo(client)
+	if errors.Is(err, ErrAccountInfoNotSupported) {
+		_, err = client.GetInboxes()
+	}
+	return err
+}
+
+// HealthHandler возвращает HTTP обработчик для проверок здоровья:
+// 200 и статус подключения в JSON, если API доступен, иначе 503
+func HealthHandler(client MailSlurpClient, apiType string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := CheckConnection(client, apiType)
+		w.Header().Set("Content-Type", "application/json")
+		if !status.Connected {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(status)
+	})
+}
@@ -0,0 +1,63 @@
+package mailslurp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "mailslurp-example"
+)
+
+// TestGetAccountInfo проверяет получение информации об аккаунте на фейковом сервере
+func TestGetAccountInfo(t *testing.T) {
+	server := newFakeMailSlurpServer(t)
+	client := NewMailSlurpClientFromProfile(&Profile{APIKey: server.apiKey, BaseURL: server.URL, Plan: "professional"})
+
+	inbox, err := client.CreateInbox()
+	if err != nil {
+		t.Fatalf("Ошибка при создании почтового ящика: %v", err)
+	}
+	server.deliver(inbox.ID, "sender@example.com", "Привет", "Тело")
+
+	info, err := client.(AccountInfoProvider).GetAccountInfo()
+	if err != nil {
+		t.Fatalf("Ошибка при получении информации об аккаунте: %v", err)
+	}
+	if info.ID != "user-1" || info.SubscriptionType != "PRO_MONTHLY" || info.CreatedAt.IsZero() {
+		t.Errorf("Неверная информация об аккаунте: %+v", info)
+	}
+	if info.InboxCount != 1 || info.EmailCount != 1 {
+		t.Errorf("Неверные счетчики: ящиков %d, писем %d", info.InboxCount, info.EmailCount)
+	}
+	if info.Plan != "professional" || info.Limits.MaxInboxes != 50 || !info.Limits.CanCreateCustomDomains {
+		t.Errorf("Неверные лимиты плана: %+v", info.Limits)
+	}
+	if info.InboxesRemaining() != 49 {
+		t.Errorf("Неверный остаток ящиков: %d", info.InboxesRemaining())
+	}
+}
+
+// TestCheckConnection проверяет статус подключения и обработчик проверки здоровья
+func TestCheckConnection(t *testing.T) {
+	server := newFakeMailSlurpServer(t)
+
+	status := CheckConnection(server.client(), "personal")
+	if !status.Connected || status.APIType != "personal" || status.LastChecked.IsZero() || status.Account == nil {
+		t.Errorf("Неверный статус подключения: %+v", status)
+	}
+
+	badClient := NewMailSlurpClientFromProfile(&Profile{APIKey: "wrong", BaseURL: server.URL})
+	status = CheckConnection(badClient, "public")
+	if status.Connected || status.Error == "" {
+		t.Errorf("Ожидалась ошибка подключения: %+v", status)
+	}
+	if err := Ping(badClient); err == nil {
+		t.Error("Ping должен вернуть ошибку для неверного ключа")
+	}
+
+	rec := httptest.NewRecorder()
+	HealthHandler(badClient, "public").ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Неверный код ответа: %d", rec.Code)
+	}
+}
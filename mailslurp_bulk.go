@@ -0,0 +1,245 @@
+package mailslurp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultBulkConcurrency - количество параллельных запросов в пакетных операциях
+const defaultBulkConcurrency = 4
+
+var (
+	// ErrBulkAborted - операция над элементом не выполнялась, потому что пакет
+	// был прерван из-за исчерпания лимита плана
+	ErrBulkAborted = errors.New("операция прервана: исчерпан лимит плана")
+	// ErrEmptyInboxFilter - фильтр DeleteAllInboxes пуст, удаление всех ящиков запрещено
+	ErrEmptyInboxFilter = errors.New("фильтр ящиков пуст: укажите префикс, возраст или тег")
+)
+
+// BulkOptions задает параметры пакетных операций
+type BulkOptions struct {
+	// Concurrency - количество параллельных запросов, по умолчанию 4.
+	// Частоту запросов дополнительно ограничивает WithRateLimit клиента.
+	Concurrency int
+}
+
+// InboxResult - результат операции над одним ящиком
+type InboxResult struct {
+	InboxID string
+	// Inbox заполняется при создании
+	Inbox *Inbox
+	Err   error
+}
+
+// EmailsResult - письма одного ящика
+type EmailsResult struct {
+	InboxID string
+	Emails  []Email
+	Err     error
+}
+
+// BulkError объединяет ошибки отдельных элементов пакетной операции.
+// Поддерживает errors.Is и errors.As для каждой из ошибок.
+type BulkError struct {
+	Operation string
+	Total     int
+	Errors    []error
+}
+
+func (e *BulkError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%s: ошибок %d из %d: %s", e.Operation, len(e.Errors), e.Total, strings.Join(msgs, "; "))
+}
+
+// Unwrap возвращает ошибки отдельных элементов
+func (e *BulkError) Unwrap() []error {
+	return e.Errors
+}
+
+// newBulkError возвращает *BulkError, если хотя бы один элемент завершился ошибкой
+func newBulkError(operation string, total int, errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &BulkError{Operation: operation, Total: total, Errors: errs}
+}
+
+// runBulk выполняет fn для индексов 0..n-1 не более чем в concurrency горутин.
+// Если fn вернула ошибку лимита плана, оставшиеся элементы не запускаются
+// и получают ErrBulkAborted.
+func runBulk(n int, opts BulkOptions, fn func(i int) error) []error {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBulkConcurrency
+	}
+
+	errs := make([]error, n)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	aborted := false
+
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		mu.Lock()
+		if aborted {
+			mu.Unlock()
+			<-sem
+			errs[i] = ErrBulkAborted
+			continue
+		}
+		mu.Unlock()
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() { <-sem; wg.Done() }()
+			err := fn(i)
+			errs[i] = err
+			if errors.Is(err, ErrPlanLimitExceeded) {
+				mu.Lock()
+				aborted = true
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+// CreateInboxes создает n ящиков с параметрами opts. Если задано opts.Name,
+// ящики получают имена name-1, name-2 и т.д. При исчерпании лимита плана
+// оставшиеся ящики не создаются.
+func CreateInboxes(client MailSlurpClient, n int, opts CreateInboxOptions, bulk BulkOptions) ([]InboxResult, error) {
+	results := make([]InboxResult, n)
+	errs := runBulk(n, bulk, func(i int) error {
+		itemOpts := opts
+		if opts.Name != "" {
+			itemOpts.Name = fmt.Sprintf("%s-%d", opts.Name, i+1)
+		}
+		inbox, err := client.CreateInboxWithOptions(itemOpts)
+		if err != nil {
+			return err
+		}
+		results[i] = InboxResult{InboxID: inbox.ID, Inbox: inbox}
+		return nil
+	})
+	return results, collectInboxErrors("CreateInboxes", results, errs)
+}
+
+// DeleteInboxes удаляет ящики по ID. Уже удаленные ящики не считаются ошибкой.
+func DeleteInboxes(client MailSlurpClient, ids []string, bulk BulkOptions) ([]InboxResult, error) {
+	results := make([]InboxResult, len(ids))
+	for i, id := range ids {
+		results[i].InboxID = id
+	}
+	errs := runBulk(len(ids), bulk, func(i int) error {
+		if err := client.DeleteInbox(ids[i]); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		return nil
+	})
+	return results, collectInboxErrors("DeleteInboxes", results, errs)
+}
+
+// InboxFilter отбирает ящики для DeleteAllInboxes. Условия объединяются через И.
+type InboxFilter struct {
+	// NamePrefix - имя ящика начинается с префикса
+	NamePrefix string
+	// OlderThan - ящик создан раньше, чем OlderThan назад
+	OlderThan time.Duration
+	// Tag - у ящика есть тег
+	Tag string
+}
+
+// IsEmpty сообщает, что фильтр не задает ни одного условия
+func (f InboxFilter) IsEmpty() bool {
+	return f.NamePrefix == "" && f.OlderThan <= 0 && f.Tag == ""
+}
+
+// Match проверяет ящик на соответствие фильтру на момент now
+func (f InboxFilter) Match(inbox Inbox, now time.Time) bool {
+	if f.NamePrefix != "" && !strings.HasPrefix(inbox.Name, f.NamePrefix) {
+		return false
+	}
+	if f.OlderThan > 0 && now.Sub(inbox.CreatedAt) < f.OlderThan {
+		return false
+	}
+	if f.Tag != "" && !hasTag(inbox.Tags, f.Tag) {
+		return false
+	}
+	return true
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteAllInboxes удаляет все ящики, подходящие под фильтр. Пустой фильтр
+// запрещен, чтобы случайно не удалить все ящики аккаунта.
+func DeleteAllInboxes(client MailSlurpClient, filter InboxFilter, bulk BulkOptions) ([]InboxResult, error) {
+	if filter.IsEmpty() {
+		return nil, ErrEmptyInboxFilter
+	}
+	inboxes, err := client.GetInboxes()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var ids []string
+	for _, inbox := range inboxes {
+		if filter.Match(inbox, now) {
+			ids = append(ids, inbox.ID)
+		}
+	}
+	return DeleteInboxes(client, ids, bulk)
+}
+
+// GetEmailsForInboxes получает письма нескольких ящиков параллельно
+func GetEmailsForInboxes(client MailSlurpClient, ids []string, bulk BulkOptions) ([]EmailsResult, error) {
+	results := make([]EmailsResult, len(ids))
+	errs := runBulk(len(ids), bulk, func(i int) error {
+		emails, err := client.GetEmails(ids[i])
+		results[i].Emails = emails
+		return err
+	})
+
+	var failed []error
+	for i := range results {
+		results[i].InboxID = ids[i]
+		if errs[i] != nil {
+			results[i].Err = errs[i]
+			failed = append(failed, fmt.Errorf("ящик %s: %w", ids[i], errs[i]))
+		}
+	}
+	return results, newBulkError("GetEmailsForInboxes", len(ids), failed)
+}
+
+// collectInboxErrors записывает ошибки в результаты и объединяет их
+func collectInboxErrors(operation string, results []InboxResult, errs []error) error {
+	var failed []error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		results[i].Err = err
+		if results[i].InboxID != "" {
+			err = fmt.Errorf("ящик %s: %w", results[i].InboxID, err)
+		} else {
+			err = fmt.Errorf("элемент %d: %w", i+1, err)
+		}
+		failed = append(failed, err)
+	}
+	return newBulkError(operation, len(results), failed)
+}
@@ -0,0 +1,158 @@
+package mailslurp_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	. "mailslurp-example"
+	"mailslurp-example/mailslurptest"
+)
+
+// TestCreateInboxes проверяет пакетное создание и прерывание на лимите плана
+func TestCreateInboxes(t *testing.T) {
+	mock := mailslurptest.NewMockClient()
+	results, err := CreateInboxes(mock, 3, CreateInboxOptions{Name: "ci", Tags: []string{"ci"}}, BulkOptions{})
+	if err != nil {
+		t.Fatalf("Ошибка CreateInboxes: %v", err)
+	}
+	names := map[string]bool{}
+	for _, r := range results {
+		names[r.Inbox.Name] = true
+	}
+	if !names["ci-1"] || !names["ci-3"] || len(names) != 3 {
+		t.Errorf("Неверные имена ящиков: %v", names)
+	}
+
+	mock = mailslurptest.NewMockClient()
+	mock.FailOn("CreateInboxWithOptions", 3, ErrPlanLimitExceeded)
+	results, err = CreateInboxes(mock, 5, CreateInboxOptions{}, BulkOptions{Concurrency: 1})
+	var bulkErr *BulkError
+	if !errors.As(err, &bulkErr) || len(bulkErr.Errors) != 3 || bulkErr.Total != 5 {
+		t.Fatalf("Ожидалась BulkError с тремя ошибками, получено: %v", err)
+	}
+	if !errors.Is(err, ErrPlanLimitExceeded) || !errors.Is(err, ErrBulkAborted) {
+		t.Errorf("BulkError должна содержать ошибку лимита и прерванные элементы: %v", err)
+	}
+	if results[1].Inbox == nil || !errors.Is(results[2].Err, ErrPlanLimitExceeded) || !errors.Is(results[4].Err, ErrBulkAborted) {
+		t.Errorf("Неверные результаты: %+v", results)
+	}
+	if got := mock.CallCount("CreateInboxWithOptions"); got != 3 {
+		t.Errorf("После ошибки лимита ящики не должны создаваться, вызовов: %d", got)
+	}
+}
+
+// TestDeleteInboxes проверяет пакетное удаление с частичными ошибками
+func TestDeleteInboxes(t *testing.T) {
+	mock := mailslurptest.NewMockClient()
+	created, _ := CreateInboxes(mock, 4, CreateInboxOptions{}, BulkOptions{})
+	ids := []string{"missing"}
+	for _, r := range created {
+		ids = append(ids, r.InboxID)
+	}
+	mock.FailOn("DeleteInbox", 3, ErrRateLimited)
+
+	results, err := DeleteInboxes(mock, ids, BulkOptions{Concurrency: 1})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Ожидалась ошибка ErrRateLimited, получено: %v", err)
+	}
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			if r.InboxID != ids[2] {
+				t.Errorf("Ошибка не у того ящика: %+v", r)
+			}
+		}
+	}
+	if failed != 1 {
+		t.Errorf("Ожидалась одна ошибка, получено %d", failed)
+	}
+	if inboxes, _ := mock.GetInboxes(); len(inboxes) != 1 || inboxes[0].ID != ids[2] {
+		t.Errorf("Должен остаться только ящик с ошибкой: %+v", inboxes)
+	}
+}
+
+// TestDeleteAllInboxes проверяет фильтры по префиксу, тегу и возрасту
+func TestDeleteAllInboxes(t *testing.T) {
+	now := time.Now()
+	mock := mailslurptest.NewMockClient(
+		Inbox{ID: "old-ci", Name: "ci_run1", Tags: []string{"ci"}, CreatedAt: now.Add(-3 * time.Hour)},
+		Inbox{ID: "new-ci", Name: "ci_run2", Tags: []string{"ci"}, CreatedAt: now},
+		Inbox{ID: "old-manual", Name: "manual", CreatedAt: now.Add(-3 * time.Hour)},
+	)
+
+	if _, err := DeleteAllInboxes(mock, InboxFilter{}, BulkOptions{}); !errors.Is(err, ErrEmptyInboxFilter) {
+		t.Errorf("Пустой фильтр должен быть запрещен, получено: %v", err)
+	}
+
+	results, err := DeleteAllInboxes(mock, InboxFilter{NamePrefix: "ci_", Tag: "CI", OlderThan: time.Hour}, BulkOptions{})
+	if err != nil {
+		t.Fatalf("Ошибка DeleteAllInboxes: %v", err)
+	}
+	if len(results) != 1 || results[0].InboxID != "old-ci" {
+		t.Errorf("Должен быть удален только old-ci: %+v", results)
+	}
+	if inboxes, _ := mock.GetInboxes(); len(inboxes) != 2 {
+		t.Errorf("Должно остаться 2 ящика, осталось %d", len(inboxes))
+	}
+}
+
+// TestGetEmailsForInboxes проверяет получение писем нескольких ящиков
+func TestGetEmailsForInboxes(t *testing.T) {
+	server := newFakeMailSlurpServer(t)
+	client := server.client()
+	created, err := CreateInboxes(client, 3, CreateInboxOptions{}, BulkOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for i, r := range created {
+		ids = append(ids, r.InboxID)
+		for j := 0; j <= i; j++ {
+			server.deliver(r.InboxID, "app@example.com", fmt.Sprintf("Письмо %d", j), "тело")
+		}
+	}
+
+	results, err := GetEmailsForInboxes(client, ids, BulkOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("Ошибка GetEmailsForInboxes: %v", err)
+	}
+	for i, r := range results {
+		if r.InboxID != ids[i] || len(r.Emails) != i+1 {
+			t.Errorf("Неверный результат %d: %s, писем %d", i, r.InboxID, len(r.Emails))
+		}
+	}
+}
+
+// TestRateLimiter проверяет ограничение частоты запросов клиента
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(10, 2)
+	current := time.Unix(0, 0)
+	limiter.SetClock(func() time.Time { return current })
+
+	if limiter.Reserve() != 0 || limiter.Reserve() != 0 {
+		t.Fatal("Первые burst запросов должны проходить сразу")
+	}
+	if delay := limiter.Reserve(); delay != 100*time.Millisecond {
+		t.Errorf("Ожидалась задержка 100ms, получено %s", delay)
+	}
+	current = current.Add(100 * time.Millisecond)
+	if limiter.Reserve() != 0 {
+		t.Error("Через 100ms должен появиться токен")
+	}
+
+	server := newFakeMailSlurpServer(t)
+	client := NewMailSlurpClientFromProfile(&Profile{APIKey: server.apiKey, BaseURL: server.URL},
+		WithRateLimit(NewRateLimiter(50, 1)))
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := client.GetInboxes(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 55*time.Millisecond {
+		t.Errorf("Ограничение частоты не соблюдено: 4 запроса за %s", elapsed)
+	}
+}
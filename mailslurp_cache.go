@@ -0,0 +1,262 @@
+package mailslurp
+
+import (
+	"errors"
+	"time"
+)
+
+// Ключи времени синхронизации в MessageStore
+const (
+	syncKeyInboxes      = "inboxes"
+	syncKeyEmailsPrefix = "emails:"
+)
+
+// ErrAttachmentsNotSupported - декорируемый клиент не умеет скачивать вложения
+var ErrAttachmentsNotSupported = errors.New("клиент не поддерживает скачивание вложений")
+
+// AttachmentDownloader - клиент, который умеет скачивать вложения писем.
+// Декораторы реализуют его всегда и возвращают ErrAttachmentsNotSupported,
+// если обернутый клиент вложения не поддерживает.
+type AttachmentDownloader interface {
+	DownloadAttachment(emailID, attachmentID string) ([]byte, error)
+}
+
+// downloadAttachment скачивает вложение, если клиент это поддерживает
+func downloadAttachment(client MailSlurpClient, emailID, attachmentID string) ([]byte, error) {
+	downloader, ok := client.(AttachmentDownloader)
+	if !ok {
+		return nil, ErrAttachmentsNotSupported
+	}
+	return downloader.DownloadAttachment(emailID, attachmentID)
+}
+
+// CachingMailSlurpClient представляет собой декоратор MailSlurpClient,
+// который сохраняет ящики, письма и вложения в локальное хранилище.
+// Повторные чтения в пределах freshness обслуживаются из хранилища
+// и не расходуют квоту API.
+type CachingMailSlurpClient struct {
+	MailSlurpClient
+	store     *MessageStore
+	freshness time.Duration
+	now       func() time.Time
+}
+
+// NewCachingMailSlurpClient создает кэширующий клиент поверх client.
+// Если freshness равен 0, данные всегда запрашиваются из API,
+// но все равно сохраняются для просмотра истории.
+func NewCachingMailSlurpClient(client MailSlurpClient, store *MessageStore, freshness time.Duration) *CachingMailSlurpClient {
+	return &CachingMailSlurpClient{
+		MailSlurpClient: client,
+		store:           store,
+		freshness:       freshness,
+		now:             time.Now,
+	}
+}
+
+// Store возвращает локальное хранилище клиента
+func (c *CachingMailSlurpClient) Store() *MessageStore {
+	return c.store
+}
+
+// GetInboxes получает список почтовых ящиков из кэша или из API
+func (c *CachingMailSlurpClient) GetInboxes() ([]Inbox, error) {
+	if c.isFresh(syncKeyInboxes) {
+		return c.cachedInboxes()
+	}
+
+	inboxes, err := c.MailSlurpClient.GetInboxes()
+	if err != nil {
+		return nil, err
+	}
+
+	// Ящики, которых больше нет в API, остаются в истории как удаленные
+	active := make(map[string]bool, len(inboxes))
+	for _, inbox := range inboxes {
+		active[inbox.ID] = true
+		if err := c.store.SaveInbox(inbox); err != nil {
+			return nil, err
+		}
+	}
+	cached, err := c.store.Inboxes()
+	if err != nil {
+		return nil, err
+	}
+	for _, stored := range cached {
+		if !active[stored.ID] && stored.DeletedAt == nil {
+			if err := c.store.MarkInboxDeleted(stored.ID, c.now()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := c.store.MarkSynced(syncKeyInboxes, c.now()); err != nil {
+		return nil, err
+	}
+	return inboxes, nil
+}
+
+// CreateInbox создает почтовый ящик и сохраняет его в хранилище
+func (c *CachingMailSlurpClient) CreateInbox() (*Inbox, error) {
+	inbox, err := c.MailSlurpClient.CreateInbox()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.store.SaveInbox(*inbox); err != nil {
+		return nil, err
+	}
+	return inbox, nil
+}
+
+// CreateInboxWithOptions создает почтовый ящик с параметрами и сохраняет его в хранилище
+func (c *CachingMailSlurpClient) CreateInboxWithOptions(opts CreateInboxOptions) (*Inbox, error) {
+	inbox, err := c.MailSlurpClient.CreateInboxWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.store.SaveInbox(*inbox); err != nil {
+		return nil, err
+	}
+	return inbox, nil
+}
+
+// DeleteInbox удаляет почтовый ящик, оставляя его письма в истории
+func (c *CachingMailSlurpClient) DeleteInbox(inboxID string) error {
+	if err := c.MailSlurpClient.DeleteInbox(inboxID); err != nil {
+		return err
+	}
+	return c.store.MarkInboxDeleted(inboxID, c.now())
+}
+
+// SendHTMLEmail отправляет HTML письмо через обернутый клиент
+func (c *CachingMailSlurpClient) SendHTMLEmail(inboxID, to, subject, html string) error {
+	return sendHTMLEmail(c.MailSlurpClient, inboxID, to, subject, html)
+}
+
+// DeleteAllInboxEmails очищает ящик через обернутый клиент и удаляет его
+// сохраненные письма, чтобы кэш не возвращал их после очистки
+func (c *CachingMailSlurpClient) DeleteAllInboxEmails(inboxID string) error {
+	if err := deleteAllInboxEmails(c.MailSlurpClient, inboxID); err != nil {
+		return err
+	}
+	return c.store.DeleteEmails(inboxID)
+}
+
+// WaitForLatestEmail ожидает письмо и сохраняет его в хранилище
+func (c *CachingMailSlurpClient) WaitForLatestEmail(inboxID string, timeout time.Duration) (*Email, error) {
+	email, err := c.MailSlurpClient.WaitForLatestEmail(inboxID, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.saveEmails(inboxID, []Email{*email}); err != nil {
+		return nil, err
+	}
+	return email, nil
+}
+
+// GetEmails получает список писем из кэша или из API
+func (c *CachingMailSlurpClient) GetEmails(inboxID string) ([]Email, error) {
+	key := syncKeyEmailsPrefix + inboxID
+	if c.isFresh(key) {
+		return c.store.Emails(inboxID)
+	}
+
+	emails, err := c.MailSlurpClient.GetEmails(inboxID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.saveEmails(inboxID, emails); err != nil {
+		return nil, err
+	}
+	if err := c.store.MarkSynced(key, c.now()); err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+// DownloadAttachment возвращает вложение из хранилища, а если его там нет -
+// скачивает через обернутый клиент и сохраняет. Если обернутый клиент не
+// скачивает вложения, возвращается ErrNotCached.
+func (c *CachingMailSlurpClient) DownloadAttachment(emailID, attachmentID string) ([]byte, error) {
+	data, err := c.store.Attachment(emailID, attachmentID)
+	if !errors.Is(err, ErrNotCached) {
+		return data, err
+	}
+	data, downloadErr := c.fetchAttachment(emailID, attachmentID)
+	if errors.Is(downloadErr, ErrAttachmentsNotSupported) {
+		return nil, err
+	}
+	return data, downloadErr
+}
+
+// GetAccountInfo получает информацию об аккаунте через обернутый клиент
+func (c *CachingMailSlurpClient) GetAccountInfo() (*AccountInfo, error) {
+	return getAccountInfo(c.MailSlurpClient)
+}
+
+// History возвращает все сохраненные ящики, включая удаленные в MailSlurp.
+// Не обращается к API, поэтому работает без сети.
+func (c *CachingMailSlurpClient) History() ([]StoredInbox, error) {
+	return c.store.Inboxes()
+}
+
+// CachedEmails возвращает сохраненные письма ящика без обращения к API
+func (c *CachingMailSlurpClient) CachedEmails(inboxID string) ([]Email, error) {
+	return c.store.Emails(inboxID)
+}
+
+// saveEmails сохраняет письма и скачивает еще не сохраненные вложения,
+// чтобы они были доступны без сети
+func (c *CachingMailSlurpClient) saveEmails(inboxID string, emails []Email) error {
+	if err := c.store.SaveEmails(inboxID, emails); err != nil {
+		return err
+	}
+	for _, email := range emails {
+		for _, attachmentID := range email.Attachments {
+			if c.store.HasAttachment(email.ID, attachmentID) {
+				continue
+			}
+			_, err := c.fetchAttachment(email.ID, attachmentID)
+			if errors.Is(err, ErrAttachmentsNotSupported) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fetchAttachment скачивает вложение через обернутый клиент и сохраняет его
+func (c *CachingMailSlurpClient) fetchAttachment(emailID, attachmentID string) ([]byte, error) {
+	data, err := downloadAttachment(c.MailSlurpClient, emailID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.store.SaveAttachment(emailID, attachmentID, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (c *CachingMailSlurpClient) isFresh(key string) bool {
+	if c.freshness <= 0 {
+		return false
+	}
+	syncedAt, ok := c.store.SyncedAt(key)
+	return ok && c.now().Sub(syncedAt) < c.freshness
+}
+
+func (c *CachingMailSlurpClient) cachedInboxes() ([]Inbox, error) {
+	stored, err := c.store.Inboxes()
+	if err != nil {
+		return nil, err
+	}
+	inboxes := make([]Inbox, 0, len(stored))
+	for _, s := range stored {
+		if s.DeletedAt == nil {
+			inboxes = append(inboxes, s.Inbox)
+		}
+	}
+	return inboxes, nil
+}
@@ -0,0 +1,193 @@
+package mailslurp_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "mailslurp-example"
+	"mailslurp-example/mailslurptest"
+)
+
+// countingClient считает обращения к GetEmails и GetInboxes
+type countingClient struct {
+	MailSlurpClient
+	getEmails  int
+	getInboxes int
+}
+
+func (c *countingClient) GetEmails(inboxID string) ([]Email, error) {
+	c.getEmails++
+	return c.MailSlurpClient.GetEmails(inboxID)
+}
+
+func (c *countingClient) GetInboxes() ([]Inbox, error) {
+	c.getInboxes++
+	return c.MailSlurpClient.GetInboxes()
+}
+
+// TestCachingClientFreshness проверяет, что свежие данные берутся из хранилища
+func TestCachingClientFreshness(t *testing.T) {
+	store, err := OpenMessageStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Ошибка при открытии хранилища: %v", err)
+	}
+	counter := &countingClient{MailSlurpClient: &mailslurptest.MockClient{}}
+	client := NewCachingMailSlurpClient(counter, store, time.Minute)
+
+	now := time.Date(2024, 3, 29, 12, 0, 0, 0, time.UTC)
+	client.SetClock(func() time.Time { return now })
+
+	inbox, err := client.CreateInbox()
+	if err != nil {
+		t.Fatalf("Ошибка при создании почтового ящика: %v", err)
+	}
+	client.SendEmail(inbox.ID, inbox.EmailAddress, "Тема", "Тело письма")
+
+	for i := 0; i < 3; i++ {
+		emails, err := client.GetEmails(inbox.ID)
+		if err != nil {
+			t.Fatalf("Ошибка при получении писем: %v", err)
+		}
+		if len(emails) != 1 || emails[0].Body != "Тело письма" {
+			t.Fatalf("Неверные письма: %+v", emails)
+		}
+	}
+	if counter.getEmails != 1 {
+		t.Errorf("Ожидался 1 запрос к API, получено %d", counter.getEmails)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := client.GetEmails(inbox.ID); err != nil {
+		t.Fatalf("Ошибка при получении писем: %v", err)
+	}
+	if counter.getEmails != 2 {
+		t.Errorf("После устаревания ожидалось 2 запроса к API, получено %d", counter.getEmails)
+	}
+}
+
+// TestCachingClientKeepsHistory проверяет, что письма удаленного ящика остаются в истории
+func TestCachingClientKeepsHistory(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenMessageStore(dir)
+	if err != nil {
+		t.Fatalf("Ошибка при открытии хранилища: %v", err)
+	}
+	client := NewCachingMailSlurpClient(&mailslurptest.MockClient{}, store, 0)
+
+	inbox, err := client.CreateInbox()
+	if err != nil {
+		t.Fatalf("Ошибка при создании почтового ящика: %v", err)
+	}
+	client.SendEmail(inbox.ID, inbox.EmailAddress, "Заказ 1234", "Ваш заказ оформлен")
+	if _, err := client.WaitForLatestEmail(inbox.ID, time.Second); err != nil {
+		t.Fatalf("Ошибка при ожидании письма: %v", err)
+	}
+	if err := client.DeleteInbox(inbox.ID); err != nil {
+		t.Fatalf("Ошибка при удалении почтового ящика: %v", err)
+	}
+
+	// Открываем хранилище заново, как при следующем запуске без сети
+	reopened, err := OpenMessageStore(dir)
+	if err != nil {
+		t.Fatalf("Ошибка при открытии хранилища: %v", err)
+	}
+	history, err := reopened.Inboxes()
+	if err != nil {
+		t.Fatalf("Ошибка при чтении истории: %v", err)
+	}
+	if len(history) != 1 || history[0].ID != inbox.ID || history[0].DeletedAt == nil {
+		t.Fatalf("Неверная история ящиков: %+v", history)
+	}
+	emails, err := reopened.Emails(inbox.ID)
+	if err != nil {
+		t.Fatalf("Ошибка при чтении писем: %v", err)
+	}
+	if len(emails) != 1 || emails[0].Subject != "Заказ 1234" {
+		t.Errorf("Неверные письма в истории: %+v", emails)
+	}
+
+	if err := reopened.SaveAttachment("email/1", "счет.pdf", []byte("%PDF")); err != nil {
+		t.Fatalf("Ошибка при сохранении вложения: %v", err)
+	}
+	if data, err := reopened.Attachment("email/1", "счет.pdf"); err != nil || string(data) != "%PDF" {
+		t.Errorf("Неверное вложение: %q, %v", data, err)
+	}
+	if _, err := reopened.Inbox("missing"); !errors.Is(err, ErrNotCached) {
+		t.Errorf("Ожидалась ошибка ErrNotCached, получено: %v", err)
+	}
+}
+
+// TestCachingClientAttachments проверяет, что вложения скачиваются вместе с
+// письмами один раз и доступны из хранилища без сети
+func TestCachingClientAttachments(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenMessageStore(dir)
+	if err != nil {
+		t.Fatalf("Ошибка при открытии хранилища: %v", err)
+	}
+	server := newFakeMailSlurpServer(t)
+	api := server.client()
+	inbox, err := api.CreateInbox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.deliver(inbox.ID, "shop@example.com", "Счет", "Счет во вложении")
+	server.attach(inbox.ID, "att-1", []byte("%PDF-1.4"))
+
+	client := NewCachingMailSlurpClient(api, store, 0)
+	emails, err := client.GetEmails(inbox.ID)
+	if err != nil {
+		t.Fatalf("Ошибка при получении писем: %v", err)
+	}
+	if len(emails) != 1 || len(emails[0].Attachments) != 1 {
+		t.Fatalf("Неверные письма: %+v", emails)
+	}
+	requests := server.requestCount()
+	if _, err := client.GetEmails(inbox.ID); err != nil {
+		t.Fatalf("Ошибка при получении писем: %v", err)
+	}
+	if got := server.requestCount() - requests; got != 1 {
+		t.Errorf("Сохраненное вложение не должно скачиваться повторно: %d запросов", got)
+	}
+
+	reopened, err := OpenMessageStore(dir)
+	if err != nil {
+		t.Fatalf("Ошибка при открытии хранилища: %v", err)
+	}
+	if data, err := reopened.Attachment(emails[0].ID, "att-1"); err != nil || string(data) != "%PDF-1.4" {
+		t.Errorf("Вложение не сохранено: %q, %v", data, err)
+	}
+
+	// Без сети вложение отдается из хранилища
+	offline := NewCachingMailSlurpClient(&mailslurptest.MockClient{}, reopened, 0)
+	if data, err := offline.DownloadAttachment(emails[0].ID, "att-1"); err != nil || string(data) != "%PDF-1.4" {
+		t.Errorf("Неверное вложение из хранилища: %q, %v", data, err)
+	}
+	if _, err := offline.DownloadAttachment(emails[0].ID, "missing"); err == nil {
+		t.Error("Ожидалась ошибка для отсутствующего вложения")
+	}
+
+	// Вложения и информация об аккаунте проходят через другие декораторы
+	stackedStore, err := OpenMessageStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Ошибка при открытии хранилища: %v", err)
+	}
+	metrics := NewClientMetrics()
+	stacked := NewCachingMailSlurpClient(NewInstrumentedMailSlurpClient(api, metrics), stackedStore, 0)
+	if _, err := stacked.GetEmails(inbox.ID); err != nil {
+		t.Fatalf("Ошибка при получении писем: %v", err)
+	}
+	if !stackedStore.HasAttachment(emails[0].ID, "att-1") {
+		t.Error("Вложение не сохранено через InstrumentedMailSlurpClient")
+	}
+	if status := CheckConnection(stacked, "personal"); status.Account == nil {
+		t.Errorf("Информация об аккаунте не получена через декораторы: %+v", status)
+	}
+	var out strings.Builder
+	metrics.WriteTo(&out)
+	if !strings.Contains(out.String(), `mailslurp_client_requests_total{method="DownloadAttachment",status="ok"} 1`) {
+		t.Errorf("Скачивание вложения не учтено в метриках:\n%s", out.String())
+	}
+}
@@ -0,0 +1,369 @@
+package mailslurp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ErrCassetteUnmatched - в кассете нет записи для запроса в режиме воспроизведения
+var ErrCassetteUnmatched = errors.New("запрос не найден в кассете")
+
+// CassetteMode - режим работы CassetteTransport
+type CassetteMode int
+
+const (
+	// CassetteReplay отдает ответы из файла и не обращается к сети
+	CassetteReplay CassetteMode = iota
+	// CassetteRecord выполняет запросы и записывает пары запрос-ответ в файл
+	CassetteRecord
+)
+
+// MatchOn - набор полей запроса, по которым ищется запись в кассете
+type MatchOn int
+
+const (
+	MatchMethod MatchOn = 1 << iota
+	MatchPath
+	MatchQuery
+	MatchBody
+
+	// MatchDefault - метод, путь и query
+	MatchDefault = MatchMethod | MatchPath | MatchQuery
+)
+
+// CassetteOptions задает параметры записи и воспроизведения
+type CassetteOptions struct {
+	Mode CassetteMode
+	// MatchOn - поля для сопоставления запросов, по умолчанию MatchDefault
+	MatchOn MatchOn
+	// IgnoreQuery - параметры query, которые не участвуют в сопоставлении,
+	// например timeout у waitForLatestEmail
+	IgnoreQuery []string
+	// Transport - транспорт для режима записи, по умолчанию http.DefaultTransport
+	Transport http.RoundTripper
+}
+
+// CassetteRequest - записанный запрос
+type CassetteRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Body   string `json:"body,omitempty"`
+	// BodyBase64 - тело, которое не является текстом UTF-8
+	BodyBase64 string `json:"bodyBase64,omitempty"`
+}
+
+// CassetteResponse - записанный ответ
+type CassetteResponse struct {
+	StatusCode int               `json:"statusCode"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+	// BodyBase64 - тело, которое не является текстом UTF-8, например вложение
+	BodyBase64 string `json:"bodyBase64,omitempty"`
+}
+
+// Interaction - пара запрос-ответ
+type Interaction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// Cassette - содержимое файла с записанными запросами
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// CassetteTransport представляет собой http.RoundTripper, который записывает
+// запросы к API в JSON-файл или воспроизводит их из него. API ключи в файл
+// не попадают. Подключается через WithTransport.
+type CassetteTransport struct {
+	path string
+	opts CassetteOptions
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+	secrets  map[string]bool
+}
+
+// NewCassetteTransport открывает кассету. В режиме воспроизведения файл должен
+// существовать; в режиме записи он будет перезаписан при вызове Save.
+func NewCassetteTransport(path string, opts CassetteOptions) (*CassetteTransport, error) {
+	if opts.MatchOn == 0 {
+		opts.MatchOn = MatchDefault
+	}
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultTransport
+	}
+	t := &CassetteTransport{path: path, opts: opts, secrets: map[string]bool{}}
+
+	if opts.Mode == CassetteReplay {
+		if err := readJSONFile(path, &t.cassette); err != nil {
+			return nil, fmt.Errorf("не удалось загрузить кассету %s: %v", path, err)
+		}
+		t.used = make([]bool, len(t.cassette.Interactions))
+	}
+	return t, nil
+}
+
+// Interactions возвращает записанные или загруженные пары запрос-ответ
+func (t *CassetteTransport) Interactions() []Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Interaction(nil), t.cassette.Interactions...)
+}
+
+// Unused возвращает записи, которые не были воспроизведены
+func (t *CassetteTransport) Unused() []Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var unused []Interaction
+	for i, used := range t.used {
+		if !used {
+			unused = append(unused, t.cassette.Interactions[i])
+		}
+	}
+	return unused
+}
+
+// Save записывает кассету в файл (только в режиме записи)
+func (t *CassetteTransport) Save() error {
+	if t.opts.Mode != CassetteRecord {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(t.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(t.path, append(data, '\n'))
+}
+
+// RoundTrip записывает или воспроизводит запрос
+func (t *CassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if key := req.Header.Get("x-api-key"); key != "" {
+		t.mu.Lock()
+		t.secrets[key] = true
+		t.mu.Unlock()
+	}
+	recorded := t.scrubRequest(req, body)
+
+	if t.opts.Mode == CassetteRecord {
+		if body != nil {
+			req = req.Clone(req.Context())
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		return t.record(req, recorded)
+	}
+	return t.replay(req, recorded)
+}
+
+func (t *CassetteTransport) record(req *http.Request, recorded CassetteRequest) (*http.Response, error) {
+	resp, err := t.opts.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	interaction := Interaction{
+		Request:  recorded,
+		Response: CassetteResponse{StatusCode: resp.StatusCode},
+	}
+	interaction.Response.Body, interaction.Response.BodyBase64 = t.encodeBody(data)
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		interaction.Response.Headers = map[string]string{"Content-Type": contentType}
+	}
+
+	t.mu.Lock()
+	t.cassette.Interactions = append(t.cassette.Interactions, interaction)
+	t.used = append(t.used, true)
+	t.mu.Unlock()
+	return resp, nil
+}
+
+func (t *CassetteTransport) replay(req *http.Request, recorded CassetteRequest) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// Берем первую неиспользованную подходящую запись, чтобы одинаковые
+	// запросы (например, повторный GET /emails) отдавались по порядку записи
+	for i, interaction := range t.cassette.Interactions {
+		if !t.used[i] && t.matches(interaction.Request, recorded) {
+			t.used[i] = true
+			return interaction.Response.toHTTP(req)
+		}
+	}
+	return nil, t.unmatchedError(recorded)
+}
+
+// unmatchedError описывает запрос и ближайшие записи, чтобы было видно,
+// какое поле не совпало
+func (t *CassetteTransport) unmatchedError(recorded CassetteRequest) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n  кассета: %s", describeCassetteRequest(recorded), t.path)
+	candidates := 0
+	for i, interaction := range t.cassette.Interactions {
+		if interaction.Request.Method != recorded.Method || interaction.Request.Path != recorded.Path {
+			continue
+		}
+		state := "не использована"
+		if t.used[i] {
+			state = "уже использована"
+		}
+		fmt.Fprintf(&b, "\n  запись #%d (%s): %s", i+1, state, describeCassetteRequest(interaction.Request))
+		candidates++
+	}
+	if candidates == 0 {
+		fmt.Fprintf(&b, "\n  записей с методом %s и путем %s нет", recorded.Method, recorded.Path)
+	}
+	return fmt.Errorf("%w: %s", ErrCassetteUnmatched, b.String())
+}
+
+func describeCassetteRequest(r CassetteRequest) string {
+	s := r.Method + " " + r.Path
+	if r.Query != "" {
+		s += "?" + r.Query
+	}
+	if r.Body != "" {
+		s += " body=" + r.Body
+	}
+	if r.BodyBase64 != "" {
+		s += " bodyBase64=" + r.BodyBase64
+	}
+	return s
+}
+
+func (t *CassetteTransport) matches(recorded, actual CassetteRequest) bool {
+	if t.opts.MatchOn&MatchMethod != 0 && recorded.Method != actual.Method {
+		return false
+	}
+	if t.opts.MatchOn&MatchPath != 0 && recorded.Path != actual.Path {
+		return false
+	}
+	if t.opts.MatchOn&MatchQuery != 0 && t.normalizeQuery(recorded.Query) != t.normalizeQuery(actual.Query) {
+		return false
+	}
+	if t.opts.MatchOn&MatchBody != 0 && (normalizeJSON(recorded.Body) != normalizeJSON(actual.Body) || recorded.BodyBase64 != actual.BodyBase64) {
+		return false
+	}
+	return true
+}
+
+// normalizeQuery сортирует параметры и убирает игнорируемые
+func (t *CassetteTransport) normalizeQuery(raw string) string {
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return raw
+	}
+	for _, name := range t.opts.IgnoreQuery {
+		values.Del(name)
+	}
+	for _, v := range values {
+		sort.Strings(v)
+	}
+	return values.Encode()
+}
+
+// normalizeJSON приводит JSON к каноническому виду, чтобы порядок ключей не влиял на сравнение
+func normalizeJSON(body string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return body
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// scrubRequest формирует запись запроса без API ключей
+func (t *CassetteTransport) scrubRequest(req *http.Request, body []byte) CassetteRequest {
+	query := ""
+	if req.URL.RawQuery != "" {
+		redacted, _ := url.Parse(RedactURL(&url.URL{RawQuery: req.URL.RawQuery}))
+		query = t.scrub(redacted.RawQuery)
+	}
+	recorded := CassetteRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  query,
+	}
+	recorded.Body, recorded.BodyBase64 = t.encodeBody(body)
+	return recorded
+}
+
+// encodeBody возвращает текстовое тело без API ключей или, если тело не
+// является текстом UTF-8, его base64, чтобы при воспроизведении вернуть
+// те же байты. В двоичных телах ключи не ищутся.
+func (t *CassetteTransport) encodeBody(data []byte) (text, encoded string) {
+	if !utf8.Valid(data) {
+		return "", base64.StdEncoding.EncodeToString(data)
+	}
+	return t.scrub(string(data)), ""
+}
+
+func (t *CassetteTransport) scrub(s string) string {
+	t.mu.Lock()
+	secrets := make([]string, 0, len(t.secrets))
+	for secret := range t.secrets {
+		secrets = append(secrets, secret)
+	}
+	t.mu.Unlock()
+	return RedactSecrets(s, secrets...)
+}
+
+func (r CassetteResponse) toHTTP(req *http.Request) (*http.Response, error) {
+	body := []byte(r.Body)
+	if r.BodyBase64 != "" {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(r.BodyBase64); err != nil {
+			return nil, fmt.Errorf("некорректное поле bodyBase64 в кассете: %v", err)
+		}
+	}
+	header := http.Header{}
+	for name, value := range r.Headers {
+		header.Set(name, value)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// readRequestBody читает тело запроса
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	return io.ReadAll(req.Body)
+}
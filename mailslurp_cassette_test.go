@@ -0,0 +1,147 @@
+package mailslurp_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "mailslurp-example"
+)
+
+// TestCassetteRecordReplay записывает обмен с фейковым сервером и воспроизводит его без сети
+func TestCassetteRecordReplay(t *testing.T) {
+	server := newFakeMailSlurpServer(t)
+	path := filepath.Join(t.TempDir(), "cassettes", "signup.json")
+
+	recorder, err := NewCassetteTransport(path, CassetteOptions{Mode: CassetteRecord})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewMailSlurpClientFromProfile(&Profile{APIKey: server.apiKey, BaseURL: server.URL}, WithTransport(recorder))
+
+	inbox, err := client.CreateInbox()
+	if err != nil {
+		t.Fatalf("Ошибка при создании почтового ящика: %v", err)
+	}
+	if err := client.SendEmail(inbox.ID, inbox.EmailAddress, "Запись", "Тело письма"); err != nil {
+		t.Fatalf("Ошибка при отправке письма: %v", err)
+	}
+	recordedEmail, err := client.WaitForLatestEmail(inbox.ID, time.Second)
+	if err != nil {
+		t.Fatalf("Ошибка при ожидании письма: %v", err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Ошибка при сохранении кассеты: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), server.apiKey) {
+		t.Fatalf("API ключ попал в кассету:\n%s", data)
+	}
+	server.Close()
+
+	// Воспроизведение: timeout отличается, но исключен из сопоставления
+	player, err := NewCassetteTransport(path, CassetteOptions{MatchOn: MatchDefault | MatchBody, IgnoreQuery: []string{"timeout"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := NewMailSlurpClient("other-key", WithTransport(player))
+
+	replayedInbox, err := replayed.CreateInbox()
+	if err != nil || replayedInbox.ID != inbox.ID {
+		t.Fatalf("Ящик не воспроизведен: %+v, %v", replayedInbox, err)
+	}
+	if err := replayed.SendEmail(inbox.ID, inbox.EmailAddress, "Запись", "Тело письма"); err != nil {
+		t.Fatalf("Отправка не воспроизведена: %v", err)
+	}
+	email, err := replayed.WaitForLatestEmail(inbox.ID, 5*time.Second)
+	if err != nil || email.ID != recordedEmail.ID || email.Subject != "Запись" {
+		t.Fatalf("Письмо не воспроизведено: %+v, %v", email, err)
+	}
+	if unused := player.Unused(); len(unused) != 0 {
+		t.Errorf("Остались неиспользованные записи: %+v", unused)
+	}
+
+	// Запись используется один раз, повторный запрос не находится
+	_, err = replayed.WaitForLatestEmail(inbox.ID, time.Second)
+	if !errors.Is(err, ErrCassetteUnmatched) || !strings.Contains(err.Error(), "уже использована") {
+		t.Errorf("Ожидалась понятная ошибка несовпадения, получено: %v", err)
+	}
+
+	// Тело запроса участвует в сопоставлении
+	player, _ = NewCassetteTransport(path, CassetteOptions{MatchOn: MatchDefault | MatchBody})
+	replayed = NewMailSlurpClient("other-key", WithTransport(player))
+	replayed.CreateInbox()
+	err = replayed.SendEmail(inbox.ID, inbox.EmailAddress, "Другая тема", "Тело письма")
+	if !errors.Is(err, ErrCassetteUnmatched) || !strings.Contains(err.Error(), `"subject":"Запись"`) {
+		t.Errorf("Ожидалась ошибка с описанием записанного тела, получено: %v", err)
+	}
+}
+
+// TestCassetteBinaryBody проверяет, что двоичное тело ответа воспроизводится
+// байт в байт
+func TestCassetteBinaryBody(t *testing.T) {
+	server := newFakeMailSlurpServer(t)
+	path := filepath.Join(t.TempDir(), "binary.json")
+	attachment := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff, 0xfe, 0x80}
+
+	recorder, err := NewCassetteTransport(path, CassetteOptions{Mode: CassetteRecord})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewMailSlurpClientFromProfile(&Profile{APIKey: server.apiKey, BaseURL: server.URL}, WithTransport(recorder))
+	inbox, err := client.CreateInbox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.deliver(inbox.ID, "shop@example.com", "Картинка", "Во вложении")
+	server.attach(inbox.ID, "att-1", attachment)
+	emails, err := client.GetEmails(inbox.ID)
+	if err != nil || len(emails) != 1 {
+		t.Fatalf("Ошибка при получении писем: %+v, %v", emails, err)
+	}
+	if _, err := client.(AttachmentDownloader).DownloadAttachment(emails[0].ID, "att-1"); err != nil {
+		t.Fatalf("Ошибка при скачивании вложения: %v", err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"bodyBase64"`) {
+		t.Errorf("Двоичное тело должно храниться в bodyBase64:\n%s", data)
+	}
+
+	player, err := NewCassetteTransport(path, CassetteOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := NewMailSlurpClient("other-key", WithTransport(player))
+	replayed.CreateInbox()
+	replayed.GetEmails(inbox.ID)
+	got, err := replayed.(AttachmentDownloader).DownloadAttachment(emails[0].ID, "att-1")
+	if err != nil {
+		t.Fatalf("Вложение не воспроизведено: %v", err)
+	}
+	if !bytes.Equal(got, attachment) {
+		t.Errorf("Вложение изменилось при воспроизведении: %v, ожидалось %v", got, attachment)
+	}
+}
+
+// TestCassetteMissingFile проверяет ошибку при отсутствии кассеты
+func TestCassetteMissingFile(t *testing.T) {
+	if _, err := NewCassetteTransport(filepath.Join(t.TempDir(), "missing.json"), CassetteOptions{}); err == nil {
+		t.Error("Ожидалась ошибка для отсутствующей кассеты")
+	}
+}
@@ -0,0 +1,162 @@
+package mailslurp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnknownCharset возвращается для кодировок, которые не поддерживаются
+var ErrUnknownCharset = errors.New("неподдерживаемая кодировка")
+
+// charsetAliases сопоставляет названия кодировок из писем каноническим
+var charsetAliases = map[string]string{
+	"utf-8":        "utf-8",
+	"utf8":         "utf-8",
+	"us-ascii":     "utf-8",
+	"ascii":        "utf-8",
+	"koi8-r":       "koi8-r",
+	"koi8r":        "koi8-r",
+	"cskoi8r":      "koi8-r",
+	"windows-1251": "windows-1251",
+	"cp1251":       "windows-1251",
+	"win-1251":     "windows-1251",
+	"x-cp1251":     "windows-1251",
+	"iso-8859-5":   "iso-8859-5",
+	"iso8859-5":    "iso-8859-5",
+	"iso_8859-5":   "iso-8859-5",
+	"cyrillic":     "iso-8859-5",
+	"iso-8859-1":   "iso-8859-1",
+	"latin1":       "iso-8859-1",
+}
+
+// Таблицы однобайтовых кодировок: символы для байтов 0x80-0xFF.
+// Байты 0x00-0x7F во всех кодировках совпадают с ASCII.
+var charsetTables = map[string]*[128]rune{
+	"koi8-r":       &koi8rTable,
+	"windows-1251": &windows1251Table,
+	"iso-8859-5":   &iso88595Table,
+}
+
+var koi8rTable = [128]rune{
+	0x2500, 0x2502, 0x250C, 0x2510, 0x2514, 0x2518, 0x251C, 0x2524,
+	0x252C, 0x2534, 0x253C, 0x2580, 0x2584, 0x2588, 0x258C, 0x2590,
+	0x2591, 0x2592, 0x2593, 0x2320, 0x25A0, 0x2219, 0x221A, 0x2248,
+	0x2264, 0x2265, 0x00A0, 0x2321, 0x00B0, 0x00B2, 0x00B7, 0x00F7,
+	0x2550, 0x2551, 0x2552, 0x0451, 0x2553, 0x2554, 0x2555, 0x2556,
+	0x2557, 0x2558, 0x2559, 0x255A, 0x255B, 0x255C, 0x255D, 0x255E,
+	0x255F, 0x2560, 0x2561, 0x0401, 0x2562, 0x2563, 0x2564, 0x2565,
+	0x2566, 0x2567, 0x2568, 0x2569, 0x256A, 0x256B, 0x256C, 0x00A9,
+	0x044E, 0x0430, 0x0431, 0x0446, 0x0434, 0x0435, 0x0444, 0x0433,
+	0x0445, 0x0438, 0x0439, 0x043A, 0x043B, 0x043C, 0x043D, 0x043E,
+	0x043F, 0x044F, 0x0440, 0x0441, 0x0442, 0x0443, 0x0436, 0x0432,
+	0x044C, 0x044B, 0x0437, 0x0448, 0x044D, 0x0449, 0x0447, 0x044A,
+	0x042E, 0x0410, 0x0411, 0x0426, 0x0414, 0x0415, 0x0424, 0x0413,
+	0x0425, 0x0418, 0x0419, 0x041A, 0x041B, 0x041C, 0x041D, 0x041E,
+	0x041F, 0x042F, 0x0420, 0x0421, 0x0422, 0x0423, 0x0416, 0x0412,
+	0x042C, 0x042B, 0x0417, 0x0428, 0x042D, 0x0429, 0x0427, 0x042A,
+}
+
+// В windows-1251 байт 0x98 не определен и заменяется на U+FFFD
+var windows1251Table = [128]rune{
+	0x0402, 0x0403, 0x201A, 0x0453, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x20AC, 0x2030, 0x0409, 0x2039, 0x040A, 0x040C, 0x040B, 0x040F,
+	0x0452, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0xFFFD, 0x2122, 0x0459, 0x203A, 0x045A, 0x045C, 0x045B, 0x045F,
+	0x00A0, 0x040E, 0x045E, 0x0408, 0x00A4, 0x0490, 0x00A6, 0x00A7,
+	0x0401, 0x00A9, 0x0404, 0x00AB, 0x00AC, 0x00AD, 0x00AE, 0x0407,
+	0x00B0, 0x00B1, 0x0406, 0x0456, 0x0491, 0x00B5, 0x00B6, 0x00B7,
+	0x0451, 0x2116, 0x0454, 0x00BB, 0x0458, 0x0405, 0x0455, 0x0457,
+	0x0410, 0x0411, 0x0412, 0x0413, 0x0414, 0x0415, 0x0416, 0x0417,
+	0x0418, 0x0419, 0x041A, 0x041B, 0x041C, 0x041D, 0x041E, 0x041F,
+	0x0420, 0x0421, 0x0422, 0x0423, 0x0424, 0x0425, 0x0426, 0x0427,
+	0x0428, 0x0429, 0x042A, 0x042B, 0x042C, 0x042D, 0x042E, 0x042F,
+	0x0430, 0x0431, 0x0432, 0x0433, 0x0434, 0x0435, 0x0436, 0x0437,
+	0x0438, 0x0439, 0x043A, 0x043B, 0x043C, 0x043D, 0x043E, 0x043F,
+	0x0440, 0x0441, 0x0442, 0x0443, 0x0444, 0x0445, 0x0446, 0x0447,
+	0x0448, 0x0449, 0x044A, 0x044B, 0x044C, 0x044D, 0x044E, 0x044F,
+}
+
+var iso88595Table = [128]rune{
+	0x0080, 0x0081, 0x0082, 0x0083, 0x0084, 0x0085, 0x0086, 0x0087,
+	0x0088, 0x0089, 0x008A, 0x008B, 0x008C, 0x008D, 0x008E, 0x008F,
+	0x0090, 0x0091, 0x0092, 0x0093, 0x0094, 0x0095, 0x0096, 0x0097,
+	0x0098, 0x0099, 0x009A, 0x009B, 0x009C, 0x009D, 0x009E, 0x009F,
+	0x00A0, 0x0401, 0x0402, 0x0403, 0x0404, 0x0405, 0x0406, 0x0407,
+	0x0408, 0x0409, 0x040A, 0x040B, 0x040C, 0x00AD, 0x040E, 0x040F,
+	0x0410, 0x0411, 0x0412, 0x0413, 0x0414, 0x0415, 0x0416, 0x0417,
+	0x0418, 0x0419, 0x041A, 0x041B, 0x041C, 0x041D, 0x041E, 0x041F,
+	0x0420, 0x0421, 0x0422, 0x0423, 0x0424, 0x0425, 0x0426, 0x0427,
+	0x0428, 0x0429, 0x042A, 0x042B, 0x042C, 0x042D, 0x042E, 0x042F,
+	0x0430, 0x0431, 0x0432, 0x0433, 0x0434, 0x0435, 0x0436, 0x0437,
+	0x0438, 0x0439, 0x043A, 0x043B, 0x043C, 0x043D, 0x043E, 0x043F,
+	0x0440, 0x0441, 0x0442, 0x0443, 0x0444, 0x0445, 0x0446, 0x0447,
+	0x0448, 0x0449, 0x044A, 0x044B, 0x044C, 0x044D, 0x044E, 0x044F,
+	0x2116, 0x0451, 0x0452, 0x0453, 0x0454, 0x0455, 0x0456, 0x0457,
+	0x0458, 0x0459, 0x045A, 0x045B, 0x045C, 0x00A7, 0x045E, 0x045F,
+}
+
+// normalizeCharset возвращает каноническое название кодировки
+func normalizeCharset(charset string) (string, error) {
+	name := strings.ToLower(strings.Trim(strings.TrimSpace(charset), `"`))
+	if name == "" {
+		return "utf-8", nil
+	}
+	if canonical, ok := charsetAliases[name]; ok {
+		return canonical, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownCharset, charset)
+}
+
+// DecodeCharset преобразует текст в кодировке charset в UTF-8. Пустая
+// кодировка считается UTF-8; некорректные UTF-8 последовательности
+// заменяются на U+FFFD.
+func DecodeCharset(data []byte, charset string) (string, error) {
+	name, err := normalizeCharset(charset)
+	if err != nil {
+		return "", err
+	}
+
+	switch name {
+	case "utf-8":
+		return strings.ToValidUTF8(string(data), string(utf8.RuneError)), nil
+	case "iso-8859-1":
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes), nil
+	}
+
+	table := charsetTables[name]
+	var b strings.Builder
+	b.Grow(len(data) * 2)
+	for _, c := range data {
+		if c < 0x80 {
+			b.WriteByte(c)
+		} else {
+			b.WriteRune(table[c-0x80])
+		}
+	}
+	return b.String(), nil
+}
+
+// CharsetReader возвращает reader, перекодирующий r из charset в UTF-8.
+// Подходит для mime.WordDecoder.CharsetReader.
+func CharsetReader(charset string, r io.Reader) (io.Reader, error) {
+	if _, err := normalizeCharset(charset); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text, err := DecodeCharset(data, charset)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader([]byte(text)), nil
+}
@@ -0,0 +1,433 @@
+package mailslurp
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Коды завершения CLI
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// newCLIClient создает клиент для команд CLI; подменяется в тестах
+var newCLIClient = func(profile *Profile) MailSlurpClient {
+	return NewMailSlurpClientFromProfile(profile)
+}
+
+// resolveCLIProfile выбирает профиль по флагу --profile и переопределяет
+// API ключ значением флага --api-key, если он задан
+func resolveCLIProfile(name, apiKey string) (*Profile, error) {
+	profile, err := LoadProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey != "" {
+		profile.APIKey = apiKey
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// RunCLI разбирает аргументы командной строки neuromail и выполняет команду.
+// Возвращает код завершения процесса.
+func RunCLI(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "wait":
+		return runWaitCommand(args[1:], stdout, stderr)
+	case "get":
+		return runGetCommand(args[1:], stdout, stderr)
+	case "search":
+		return runSearchCommand(args[1:], stdout, stderr)
+	case "sweep":
+		return runSweepCommand(args[1:], stdout, stderr)
+	case "scenario":
+		return runScenarioCommand(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		printUsage(stdout)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "неизвестная команда: %s\n\n", args[0])
+		printUsage(stderr)
+		return exitUsage
+	}
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Использование: neuromail <команда> [флаги]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Команды:")
+	fmt.Fprintln(w, "  wait      дождаться письма и вывести извлеченный код или ссылку")
+	fmt.Fprintln(w, "  get       вывести письмо из ящика в виде текста")
+	fmt.Fprintln(w, "  search    найти письма в локальном хранилище")
+	fmt.Fprintln(w, "  sweep     удалить брошенные почтовые ящики пользователей")
+	fmt.Fprintln(w, "  scenario  выполнить YAML сценарии проверки писем")
+}
+
+// runWaitCommand реализует команду wait: ждет письмо и печатает в stdout
+// только извлеченное значение, чтобы его было удобно использовать в скриптах.
+func runWaitCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("wait", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	profileName := fs.String("profile", "", "профиль из файла конфигурации")
+	apiKey := fs.String("api-key", "", "API ключ MailSlurp (переопределяет профиль)")
+	inboxID := fs.String("inbox", "", "ID почтового ящика (обязательно)")
+	timeout := fs.Duration("timeout", 0, "максимальное время ожидания письма (по умолчанию из профиля)")
+	subject := fs.String("subject-contains", "", "тема письма должна содержать строку")
+	from := fs.String("from-contains", "", "отправитель должен содержать строку")
+	body := fs.String("body-contains", "", "тело письма должно содержать строку")
+	extract := fs.String("extract", "code", "что извлечь из письма: code, link, subject, body (текст), html (исходное тело), id")
+	length := fs.Int("length", 0, "длина кода для --extract code (0 - от 4 до 8 цифр)")
+	host := fs.String("host", "", "домен ссылки для --extract link")
+	onlyNew := fs.Bool("new", false, "ждать только письмо, пришедшее после запуска команды; без флага подходящее письмо, которое уже есть в ящике, возвращается сразу")
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *inboxID == "" {
+		fmt.Fprintln(stderr, "не указан --inbox")
+		return exitUsage
+	}
+	switch *extract {
+	case "code", "link", "subject", "body", "html", "id":
+	default:
+		fmt.Fprintf(stderr, "неизвестное значение --extract: %s\n", *extract)
+		return exitUsage
+	}
+
+	var matchers []EmailMatcher
+	if *subject != "" {
+		matchers = append(matchers, SubjectContains(*subject))
+	}
+	if *from != "" {
+		matchers = append(matchers, FromContains(*from))
+	}
+	if *body != "" {
+		matchers = append(matchers, BodyContains(*body))
+	}
+
+	profile, err := resolveCLIProfile(*profileName, *apiKey)
+	if err != nil {
+		fmt.Fprintf(stderr, "ошибка конфигурации: %v\n", err)
+		return exitUsage
+	}
+	if *timeout == 0 {
+		*timeout = time.Duration(profile.WaitTimeout)
+	}
+
+	client := newCLIClient(profile)
+	wait := WaitForMatchingEmail
+	if *onlyNew {
+		wait = WaitForNewMatchingEmail
+	}
+	email, err := wait(client, *inboxID, *timeout, matchers...)
+	if err != nil {
+		fmt.Fprintf(stderr, "ошибка: %v\n", err)
+		return exitFailure
+	}
+
+	var value string
+	switch *extract {
+	case "code":
+		value, err = ExtractCode(email, *length)
+	case "link":
+		value, err = ExtractLink(email, *host)
+	case "subject":
+		value = email.Subject
+	case "body":
+		value = email.TextBody()
+	case "html":
+		value = email.Body
+	case "id":
+		value = email.ID
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "ошибка: %v (письмо %s)\n", err, email.ID)
+		return exitFailure
+	}
+
+	fmt.Fprintln(stdout, value)
+	return exitOK
+}
+
+// runGetCommand реализует команду get: печатает заголовки и тело письма.
+// HTML тело выводится в виде текста через TextBody, с --html - как есть.
+func runGetCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	profileName := fs.String("profile", "", "профиль из файла конфигурации")
+	apiKey := fs.String("api-key", "", "API ключ MailSlurp (переопределяет профиль)")
+	inboxID := fs.String("inbox", "", "ID почтового ящика (обязательно)")
+	emailID := fs.String("email", "", "ID письма (по умолчанию самое новое)")
+	raw := fs.Bool("html", false, "вывести исходное тело письма без преобразования в текст")
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *inboxID == "" {
+		fmt.Fprintln(stderr, "не указан --inbox")
+		return exitUsage
+	}
+	profile, err := resolveCLIProfile(*profileName, *apiKey)
+	if err != nil {
+		fmt.Fprintf(stderr, "ошибка конфигурации: %v\n", err)
+		return exitUsage
+	}
+
+	emails, err := newCLIClient(profile).GetEmails(*inboxID)
+	if err != nil {
+		fmt.Fprintf(stderr, "ошибка: %v\n", err)
+		return exitFailure
+	}
+	var email *Email
+	for i := range emails {
+		if *emailID != "" && emails[i].ID != *emailID {
+			continue
+		}
+		if email == nil || emails[i].Created.After(email.Created) {
+			email = &emails[i]
+		}
+	}
+	if email == nil {
+		fmt.Fprintln(stderr, "письмо не найдено")
+		return exitFailure
+	}
+
+	body := email.TextBody()
+	if *raw {
+		body = email.Body
+	}
+	fmt.Fprintf(stdout, "ID: %s\nОт: %s\nКому: %s\nТема: %s\nДата: %s\n\n%s\n",
+		email.ID, email.From, strings.Join(email.To, ", "), email.Subject, email.Created.Format(time.RFC3339), body)
+	return exitOK
+}
+
+// runSearchCommand реализует команду search: ищет письма в локальном хранилище.
+// С флагом --sync хранилище предварительно обновляется из API.
+func runSearchCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	storeDir := fs.String("store", DefaultStoreDir(), "каталог локального хранилища")
+	sync := fs.Bool("sync", false, "загрузить ящики и письма из API перед поиском")
+	profileName := fs.String("profile", "", "профиль из файла конфигурации (для --sync)")
+	apiKey := fs.String("api-key", "", "API ключ MailSlurp (для --sync)")
+	limit := fs.Int("limit", 20, "максимальное количество результатов (0 - без ограничения)")
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	query := strings.Join(fs.Args(), " ")
+
+	store, err := OpenMessageStore(*storeDir)
+	if err != nil {
+		fmt.Fprintf(stderr, "ошибка: %v\n", err)
+		return exitFailure
+	}
+
+	if *sync {
+		profile, err := resolveCLIProfile(*profileName, *apiKey)
+		if err != nil {
+			fmt.Fprintf(stderr, "ошибка конфигурации: %v\n", err)
+			return exitUsage
+		}
+		if err := syncStore(NewCachingMailSlurpClient(newCLIClient(profile), store, 0)); err != nil {
+			fmt.Fprintf(stderr, "ошибка синхронизации: %v\n", err)
+			return exitFailure
+		}
+	}
+
+	index, err := BuildSearchIndex(store)
+	if err != nil {
+		fmt.Fprintf(stderr, "ошибка: %v\n", err)
+		return exitFailure
+	}
+	results, err := index.Search(query)
+	if err != nil {
+		fmt.Fprintf(stderr, "ошибка запроса: %v\n", err)
+		return exitUsage
+	}
+
+	if *limit > 0 && len(results) > *limit {
+		results = results[:*limit]
+	}
+	for _, r := range results {
+		fmt.Fprintf(stdout, "%s\t%s\t%s\t%s\t%s\n",
+			r.Email.Created.Format(time.RFC3339), r.InboxID, r.Email.ID, r.Email.From, r.Email.Subject)
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(stderr, "ничего не найдено")
+		return exitFailure
+	}
+	return exitOK
+}
+
+// syncStore загружает все ящики и их письма в локальное хранилище
+func syncStore(client *CachingMailSlurpClient) error {
+	inboxes, err := client.GetInboxes()
+	if err != nil {
+		return err
+	}
+	for _, inbox := range inboxes {
+		if _, err := client.GetEmails(inbox.ID); err != nil {
+			return fmt.Errorf("ящик %s: %v", inbox.ID, err)
+		}
+	}
+	return nil
+}
+
+// runSweepCommand реализует команду sweep: удаляет старые и неактивные ящики,
+// сгруппированные по префиксам пользователей, и печатает отчет.
+func runSweepCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("sweep", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	profileName := fs.String("profile", "", "профиль из файла конфигурации")
+	apiKey := fs.String("api-key", "", "API ключ MailSlurp (переопределяет профиль)")
+	maxAge := fs.Duration("max-age", 0, "удалять ящики старше указанного возраста")
+	idle := fs.Duration("idle", 0, "удалять ящики без писем за указанный период")
+	tenants := fs.String("tenants", "", "префиксы пользователей через запятую (по умолчанию все)")
+	untenanted := fs.Bool("include-untenanted", false, "проверять и ящики без префикса")
+	dryRun := fs.Bool("dry-run", false, "только показать, какие ящики будут удалены")
+	asJSON := fs.Bool("json", false, "вывести отчет в формате JSON")
+	concurrency := fs.Int("concurrency", defaultBulkConcurrency, "количество параллельных запросов")
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	opts := SweepOptions{
+		MaxAge:            *maxAge,
+		IdleFor:           *idle,
+		IncludeUntenanted: *untenanted,
+		DryRun:            *dryRun,
+		Bulk:              BulkOptions{Concurrency: *concurrency},
+	}
+	if *tenants != "" {
+		for _, tenant := range strings.Split(*tenants, ",") {
+			if tenant = strings.TrimSpace(tenant); tenant != "" {
+				opts.Tenants = append(opts.Tenants, tenant)
+			}
+		}
+	}
+	if opts.MaxAge <= 0 && opts.IdleFor <= 0 {
+		fmt.Fprintln(stderr, "укажите --max-age и/или --idle")
+		return exitUsage
+	}
+
+	profile, err := resolveCLIProfile(*profileName, *apiKey)
+	if err != nil {
+		fmt.Fprintf(stderr, "ошибка конфигурации: %v\n", err)
+		return exitUsage
+	}
+
+	report, err := Sweep(newCLIClient(profile), opts)
+	if report == nil {
+		fmt.Fprintf(stderr, "ошибка: %v\n", err)
+		return exitFailure
+	}
+	if *asJSON {
+		report.WriteJSON(stdout)
+	} else {
+		report.WriteText(stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "ошибка: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// runScenarioCommand реализует команду scenario: выполняет YAML сценарии
+// и выводит результат; с --junit дополнительно пишет отчет JUnit XML
+func runScenarioCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("scenario", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	profileName := fs.String("profile", "", "профиль из файла конфигурации")
+	apiKey := fs.String("api-key", "", "API ключ MailSlurp (переопределяет профиль)")
+	asJSON := fs.Bool("json", false, "вывести результат в формате JSON")
+	junitPath := fs.String("junit", "", "записать отчет JUnit XML в файл")
+	vars := map[string]string{}
+	fs.Func("var", "переменная сценария name=value (можно указать несколько раз)", func(s string) error {
+		name, value, ok := strings.Cut(s, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("ожидалось name=value")
+		}
+		vars[name] = value
+		return nil
+	})
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "укажите файлы сценариев")
+		return exitUsage
+	}
+	var scenarios []*Scenario
+	for _, path := range fs.Args() {
+		scenario, err := LoadScenario(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "ошибка сценария: %v\n", err)
+			return exitUsage
+		}
+		scenarios = append(scenarios, scenario)
+	}
+
+	profile, err := resolveCLIProfile(*profileName, *apiKey)
+	if err != nil {
+		fmt.Fprintf(stderr, "ошибка конфигурации: %v\n", err)
+		return exitUsage
+	}
+
+	// Ctrl+C или SIGTERM от CI прерывает текущий шаг, но созданные ящики
+	// все равно удаляются, а отчет записывается
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runner := &ScenarioRunner{Client: newCLIClient(profile), Vars: vars}
+	var results []*ScenarioResult
+	failed := false
+	for _, scenario := range scenarios {
+		result := runner.Run(ctx, scenario)
+		failed = failed || !result.Passed
+		results = append(results, result)
+	}
+
+	if *asJSON {
+		WriteScenarioJSON(stdout, results)
+	} else {
+		WriteScenarioText(stdout, results)
+	}
+	if *junitPath != "" {
+		var buf bytes.Buffer
+		WriteScenarioJUnit(&buf, results)
+		if err := os.WriteFile(*junitPath, buf.Bytes(), 0o644); err != nil {
+			fmt.Fprintf(stderr, "не удалось записать отчет JUnit: %v\n", err)
+			return exitFailure
+		}
+	}
+	if failed {
+		return exitFailure
+	}
+	return exitOK
+}
@@ -0,0 +1,193 @@
+package mailslurp_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "mailslurp-example"
+	"mailslurp-example/mailslurptest"
+)
+
+// TestExtractCode проверяет извлечение кода подтверждения
+func TestExtractCode(t *testing.T) {
+	email := &Email{
+		Subject: "Подтвердите регистрацию",
+		Body:    `<p style="color:#123456">Ваш код: <b>482913</b>. Заказ 12</p>`,
+	}
+
+	code, err := ExtractCode(email, 6)
+	if err != nil {
+		t.Fatalf("Ошибка при извлечении кода: %v", err)
+	}
+	if code != "482913" {
+		t.Errorf("Неверный код: ожидалось '482913', получено '%s'", code)
+	}
+
+	if _, err := ExtractCode(email, 8); !errors.Is(err, ErrNothingExtracted) {
+		t.Errorf("Ожидалась ошибка ErrNothingExtracted, получено: %v", err)
+	}
+
+	// Цифры из адресов картинок не считаются кодом
+	tracked := &Email{
+		Subject: "Код 9999",
+		Body:    `<img src="https://t.example.com/open?uid=84721933" alt=""><p>Ваш код: <b>4821</b></p>`,
+	}
+	if code, err := ExtractCode(tracked, 0); err != nil || code != "4821" {
+		t.Errorf("Ожидался код из текста письма 4821, получено %q, %v", code, err)
+	}
+}
+
+// TestExtractLink проверяет извлечение ссылки с фильтром по домену
+func TestExtractLink(t *testing.T) {
+	email := &Email{
+		Body: `<a href="https://tracker.example.org/x">x</a>
+<a href="https://app.example.com/confirm?token=abc&amp;u=1">Подтвердить</a>.`,
+	}
+
+	link, err := ExtractLink(email, "example.com")
+	if err != nil {
+		t.Fatalf("Ошибка при извлечении ссылки: %v", err)
+	}
+	if link != "https://app.example.com/confirm?token=abc&u=1" {
+		t.Errorf("Неверная ссылка: %s", link)
+	}
+
+	if _, err := ExtractLink(email, "other.net"); !errors.Is(err, ErrNothingExtracted) {
+		t.Errorf("Ожидалась ошибка ErrNothingExtracted, получено: %v", err)
+	}
+
+	// В HTML письме подходят только ссылки <a href>, а не стили и картинки
+	page := &Email{
+		Subject: "Подтвердите адрес",
+		Body: `<html><head><link href="https://fonts.googleapis.com/css?family=Roboto" rel="stylesheet"></head>
+<body><img src="https://t.example.com/open?uid=1"><p>Перейдите по <a href="https://app.example.com/confirm">ссылке</a>
+или откройте <a href="https://app.example.com/help">https://app.example.com/help</a></p></body></html>`,
+	}
+	if link, err := ExtractLink(page, ""); err != nil || link != "https://app.example.com/confirm" {
+		t.Errorf("Ожидалась ссылка подтверждения, получено %q, %v", link, err)
+	}
+	if _, err := ExtractLink(page, "googleapis.com"); !errors.Is(err, ErrNothingExtracted) {
+		t.Errorf("Адрес стиля не должен считаться ссылкой, получено: %v", err)
+	}
+
+	// В текстовом письме ссылка ищется в тексте
+	text := &Email{Body: "Подтвердите: https://app.example.com/confirm?id=1."}
+	if link, err := ExtractLink(text, ""); err != nil || link != "https://app.example.com/confirm?id=1" {
+		t.Errorf("Неверная ссылка из текста: %q, %v", link, err)
+	}
+}
+
+// TestWaitCommand проверяет команду wait на мок-клиенте
+func TestWaitCommand(t *testing.T) {
+	mockClient := mailslurptest.NewMockClient(Inbox{ID: "inbox-1"})
+	mockClient.DeliverMessage("inbox-1", "app@example.com", "Welcome", "Привет!")
+	mockClient.DeliverMessage("inbox-1", "app@example.com", "Verify your email", "Ваш код 123456")
+
+	UseCLIClient(t, mockClient)
+
+	var stdout, stderr bytes.Buffer
+	code := RunCLI([]string{"wait", "--api-key", "key", "--inbox", "inbox-1",
+		"--subject-contains", "Verify", "--extract", "code", "--length", "6"}, &stdout, &stderr)
+	if code != ExitOK {
+		t.Fatalf("Неверный код завершения: %d, stderr: %s", code, stderr.String())
+	}
+	if got := strings.TrimSpace(stdout.String()); got != "123456" {
+		t.Errorf("Неверный вывод: ожидалось '123456', получено '%s'", got)
+	}
+}
+
+// TestWaitCommandNew проверяет, что с --new письмо, которое уже было в
+// ящике, не возвращается
+func TestWaitCommandNew(t *testing.T) {
+	mockClient := mailslurptest.NewMockClient(Inbox{ID: "inbox-1"})
+	mockClient.DeliverMessage("inbox-1", "app@example.com", "Verify your email", "Ваш код 111111")
+	UseCLIClient(t, mockClient)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		mockClient.DeliverMessage("inbox-1", "app@example.com", "Verify your email", "Ваш код 222222")
+	}()
+
+	var stdout, stderr bytes.Buffer
+	code := RunCLI([]string{"wait", "--api-key", "key", "--inbox", "inbox-1", "--new",
+		"--subject-contains", "Verify", "--timeout", "5s"}, &stdout, &stderr)
+	if code != ExitOK {
+		t.Fatalf("Неверный код завершения: %d, stderr: %s", code, stderr.String())
+	}
+	if got := strings.TrimSpace(stdout.String()); got != "222222" {
+		t.Errorf("Ожидался код из нового письма, получено '%s'", got)
+	}
+}
+
+// TestGetCommand проверяет вывод письма в виде текста и исходного HTML
+func TestGetCommand(t *testing.T) {
+	mockClient := mailslurptest.NewMockClient(Inbox{ID: "inbox-1", EmailAddress: "user@example.com"})
+	mockClient.DeliverMessage("inbox-1", "app@example.com", "Старое", "Первое письмо")
+	html := `<style>p{}</style><p>Привет, <a href="https://example.com/start">начнем</a>!</p>`
+	latest := mockClient.DeliverMessage("inbox-1", "app@example.com", "Добро пожаловать", html)
+	UseCLIClient(t, mockClient)
+
+	var stdout, stderr bytes.Buffer
+	if code := RunCLI([]string{"get", "--api-key", "key", "--inbox", "inbox-1"}, &stdout, &stderr); code != ExitOK {
+		t.Fatalf("Неверный код завершения: %d, stderr: %s", code, stderr.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{"ID: " + latest.ID + "\n", "Тема: Добро пожаловать\n",
+		"\n\nПривет, начнем [1]!\n\n[1] https://example.com/start\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("В выводе нет %q:\n%s", want, out)
+		}
+	}
+
+	stdout.Reset()
+	if code := RunCLI([]string{"get", "--api-key", "key", "--inbox", "inbox-1", "--email", latest.ID, "--html"}, &stdout, &stderr); code != ExitOK {
+		t.Fatalf("Неверный код завершения: %d, stderr: %s", code, stderr.String())
+	}
+	if !strings.HasSuffix(stdout.String(), "\n\n"+html+"\n") {
+		t.Errorf("С --html тело должно выводиться как есть:\n%s", stdout.String())
+	}
+
+	if code := RunCLI([]string{"get", "--api-key", "key", "--inbox", "inbox-1", "--email", "missing"}, &stdout, &stderr); code != ExitFailure {
+		t.Errorf("Для отсутствующего письма ожидался код %d, получено %d", ExitFailure, code)
+	}
+}
+
+// TestWaitCommandTimeout проверяет ненулевой код завершения по таймауту
+func TestWaitCommandTimeout(t *testing.T) {
+	mockClient := mailslurptest.NewMockClient(Inbox{ID: "inbox-1"})
+	mockClient.DeliverMessage("inbox-1", "app@example.com", "Welcome", "Привет!")
+
+	UseCLIClient(t, mockClient)
+
+	var stdout, stderr bytes.Buffer
+	code := RunCLI([]string{"wait", "--api-key", "key", "--inbox", "inbox-1",
+		"--subject-contains", "Verify", "--timeout", (50 * time.Millisecond).String()}, &stdout, &stderr)
+	if code != ExitFailure {
+		t.Errorf("Неверный код завершения: ожидалось %d, получено %d", ExitFailure, code)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("stdout должен быть пустым, получено: %s", stdout.String())
+	}
+}
+
+// TestWaitCommandTextBody проверяет вывод HTML письма в виде текста
+func TestWaitCommandTextBody(t *testing.T) {
+	mockClient := mailslurptest.NewMockClient(Inbox{ID: "inbox-1"})
+	mockClient.DeliverMessage("inbox-1", "app@example.com", "Welcome",
+		`<style>p{}</style><p>Привет, <a href="https://example.com/start">начнем</a>!</p>`)
+
+	UseCLIClient(t, mockClient)
+
+	var stdout, stderr bytes.Buffer
+	code := RunCLI([]string{"wait", "--api-key", "key", "--inbox", "inbox-1", "--extract", "body"}, &stdout, &stderr)
+	if code != ExitOK {
+		t.Fatalf("Неверный код завершения: %d, stderr: %s", code, stderr.String())
+	}
+	want := "Привет, начнем [1]!\n\n[1] https://example.com/start\n"
+	if stdout.String() != want {
+		t.Errorf("Неверный вывод:\n%s\nожидалось:\n%s", stdout.String(), want)
+	}
+}
@@ -1,10 +1,14 @@
-package main
+// Package mailslurp - клиент MailSlurp API для временных почтовых ящиков:
+// DefaultMailSlurpClient, декораторы (кэш, метрики, изоляция пользователей)
+// и вспомогательные функции. Тестовые двойники находятся в пакетах
+// mailslurptest и mailtest.
+package mailslurp
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -16,6 +20,33 @@ type InboxResponse struct {
 	CreatedAt    string `json:"createdAt"`
 	Name         string `json:"name,omitempty"`
 	Description  string `json:"description,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Favourite    bool `json:"favourite,omitempty"`
+	ExpiresAt    string `json:"expiresAt,omitempty"`
+	InboxType    InboxType `json:"inboxType,omitempty"`
+	DomainID     string `json:"domainId,omitempty"`
+}
+
+// toInbox преобразует ответ API в Inbox
+func (r InboxResponse) toInbox() Inbox {
+	createdAt, _ := time.Parse(time.RFC3339, r.CreatedAt)
+	inbox := Inbox{
+		ID:           r.ID,
+		EmailAddress: r.EmailAddress,
+		CreatedAt:    createdAt,
+		Name:         r.Name,
+		Description:  r.Description,
+		Tags:         r.Tags,
+		Favourite:    r.Favourite,
+		InboxType:    r.InboxType,
+		DomainID:     r.DomainID,
+	}
+	if r.ExpiresAt != "" {
+		if expiresAt, err := time.Parse(time.RFC3339, r.ExpiresAt); err == nil {
+			inbox.ExpiresAt = &expiresAt
+		}
+	}
+	return inbox
 }
 
 // ErrorResponse представляет собой структуру для парсинга ответа с ошибкой
@@ -35,6 +66,7 @@ type Email struct {
 	To      []string `json:"to"`
 	Created time.Time `json:"createdAt"`
 	Read    bool `json:"read"`
+	Attachments []string `json:"attachments,omitempty"`
 }
 
 // Inbox представляет собой структуру для работы с почтовыми ящиками
@@ -44,6 +76,94 @@ type Inbox struct {
 	CreatedAt    time.Time `json:"createdAt"`
 	Name         string `json:"name,omitempty"`
 	Description  string `json:"description,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Favourite    bool `json:"favourite,omitempty"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	InboxType    InboxType `json:"inboxType,omitempty"`
+	DomainID     string `json:"domainId,omitempty"`
+}
+
+// InboxType определяет тип почтового ящика MailSlurp
+type InboxType string
+
+const (
+	// InboxTypeHTTP - ящик, письма которого доступны через HTTP API
+	InboxTypeHTTP InboxType = "HTTP_INBOX"
+	// InboxTypeSMTP - ящик с доступом по SMTP/IMAP
+	InboxTypeSMTP InboxType = "SMTP_INBOX"
+)
+
+// CreateInboxOptions задает параметры создаваемого почтового ящика.
+// Незаполненные поля не передаются в API.
+type CreateInboxOptions struct {
+	Name        string
+	Description string
+	Tags        []string
+	// EmailAddress - желаемый полный адрес ящика
+	EmailAddress string
+	// LocalPart - желаемая часть адреса до @, используется вместе с Domain
+	LocalPart string
+	// Domain - домен ящика, например подтвержденный собственный домен
+	Domain string
+	// DomainID - ID подтвержденного собственного домена (см. DomainManager)
+	DomainID string
+	// ExpiresAt - момент удаления ящика; имеет приоритет над ExpiresIn
+	ExpiresAt time.Time
+	// ExpiresIn - время жизни ящика
+	ExpiresIn time.Duration
+	Favourite bool
+	InboxType InboxType
+}
+
+// Validate проверяет, что параметры не противоречат друг другу
+func (o CreateInboxOptions) Validate() error {
+	_, err := o.query()
+	return err
+}
+
+// query формирует параметры запроса создания ящика
+func (o CreateInboxOptions) query() (url.Values, error) {
+	q := url.Values{}
+	if o.Name != "" {
+		q.Set("name", o.Name)
+	}
+	if o.Description != "" {
+		q.Set("description", o.Description)
+	}
+	for _, tag := range o.Tags {
+		q.Add("tags", tag)
+	}
+
+	switch {
+	case o.EmailAddress != "" && o.LocalPart != "":
+		return nil, fmt.Errorf("нельзя одновременно указать EmailAddress и LocalPart")
+	case o.EmailAddress != "":
+		q.Set("emailAddress", o.EmailAddress)
+	case o.LocalPart != "":
+		if o.Domain == "" {
+			return nil, fmt.Errorf("для LocalPart необходимо указать Domain")
+		}
+		q.Set("emailAddress", o.LocalPart+"@"+o.Domain)
+	}
+	if o.DomainID != "" {
+		q.Set("domainId", o.DomainID)
+	} else if o.Domain != "" && o.LocalPart == "" {
+		q.Set("domainName", o.Domain)
+	}
+
+	switch {
+	case !o.ExpiresAt.IsZero():
+		q.Set("expiresAt", o.ExpiresAt.UTC().Format(time.RFC3339))
+	case o.ExpiresIn > 0:
+		q.Set("expiresIn", fmt.Sprintf("%d", o.ExpiresIn.Milliseconds()))
+	}
+	if o.Favourite {
+		q.Set("favourite", "true")
+	}
+	if o.InboxType != "" {
+		q.Set("inboxType", string(o.InboxType))
+	}
+	return q, nil
 }
 
 // MailSlurpClient определяет интерфейс для работы с API MailSlurp
@@ -51,6 +171,7 @@ type MailSlurpClient interface {
 	// Методы для работы с почтовыми ящиками
 	GetInboxes() ([]Inbox, error)
 	CreateInbox() (*Inbox, error)
+	CreateInboxWithOptions(opts CreateInboxOptions) (*Inbox, error)
 	DeleteInbox(inboxID string) error
 	
 	// Методы для работы с письмами
@@ -63,20 +184,77 @@ type MailSlurpClient interface {
 	GetBaseURL() string
 }
 
+// defaultBaseURL - адрес API MailSlurp по умолчанию
+const defaultBaseURL = "https://api.mailslurp.com"
+
 // DefaultMailSlurpClient представляет собой реализацию интерфейса MailSlurpClient
 type DefaultMailSlurpClient struct {
 	apiKey  string
 	baseURL string
 	client  *http.Client
+	// defaultTTL - время жизни ящика, если в опциях оно не задано
+	defaultTTL time.Duration
+	// plan - тарифный план ключа из профиля
+	plan string
+	// transport и middleware - цепочка, через которую уходят запросы к API
+	transport  http.RoundTripper
+	middleware []Middleware
+	// before и after - хуки, которые вызываются для каждого запроса к API
+	before []BeforeRequestHook
+	after  []AfterResponseHook
+	// logAttempts - логирование отдельных попыток, включается через WithLogger
+	logAttempts Middleware
+	// limiter - ограничение частоты запросов, включается через WithRateLimit
+	limiter *RateLimiter
+	// tracer - трассировка вызовов, включается через WithTracer
+	tracer *Tracer
+	// ctx - контекст вызывающего кода, задается через WithContext
+	ctx context.Context
 }
 
 // NewMailSlurpClient создает новый экземпляр клиента MailSlurp
-func NewMailSlurpClient(apiKey string) MailSlurpClient {
-	return &DefaultMailSlurpClient{
+func NewMailSlurpClient(apiKey string, opts ...ClientOption) MailSlurpClient {
+	c := &DefaultMailSlurpClient{
 		apiKey:  apiKey,
-		baseURL: "https://api.mailslurp.com",
+		baseURL: defaultBaseURL,
 		client:  &http.Client{},
 	}
+	return c.apply(opts)
+}
+
+// NewMailSlurpClientFromProfile создает клиент MailSlurp с настройками профиля
+func NewMailSlurpClientFromProfile(profile *Profile, opts ...ClientOption) MailSlurpClient {
+	baseURL := strings.TrimRight(profile.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	c := &DefaultMailSlurpClient{
+		apiKey:  profile.APIKey,
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: time.Duration(profile.Timeout)},
+		defaultTTL: time.Duration(profile.DefaultTTL),
+		plan:       profile.Plan,
+	}
+	return c.apply(opts)
+}
+
+// WithContext возвращает копию клиента, вызовы которой выполняются в ctx:
+// отмена ctx прерывает запросы, а спан из ctx становится родителем спанов клиента
+func (c *DefaultMailSlurpClient) WithContext(ctx context.Context) MailSlurpClient {
+	cp := *c
+	cp.ctx = ctx
+	return &cp
+}
+
+// ClientWithContext привязывает клиент к ctx, если клиент это поддерживает
+// (см. DefaultMailSlurpClient.WithContext), иначе возвращает его без изменений
+func ClientWithContext(ctx context.Context, client MailSlurpClient) MailSlurpClient {
+	if c, ok := client.(interface {
+		WithContext(ctx context.Context) MailSlurpClient
+	}); ok {
+		return c.WithContext(ctx)
+	}
+	return client
 }
 
 // GetAPIKey возвращает API ключ
@@ -91,46 +269,16 @@ func (c *DefaultMailSlurpClient) GetBaseURL() string {
 
 // GetInboxes получает список почтовых ящиков
 func (c *DefaultMailSlurpClient) GetInboxes() ([]Inbox, error) {
-	req, err := http.NewRequest("GET", c.baseURL+"/inboxes", nil)
-	if err != nil {
-		return nil, err
-	}
-	
-	req.Header.Add("x-api-key", c.apiKey)
-	req.Header.Add("Accept", "application/json")
-	
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	
-	body, err := ioutil.ReadAll(resp.Body)
+	var inboxResponses []InboxResponse
+	err := c.execute(apiCall{operation: "GetInboxes", method: "GET", path: "/inboxes"}, &inboxResponses)
 	if err != nil {
 		return nil, err
 	}
 	
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("ошибка API: %s, код: %d", string(body), resp.StatusCode)
-	}
-	
-	// Парсим ответ как массив
-	var inboxResponses []InboxResponse
-	if err := json.Unmarshal(body, &inboxResponses); err != nil {
-		return nil, fmt.Errorf("не удалось распарсить ответ API: %v", err)
-	}
-	
 	// Преобразуем InboxResponse в Inbox
 	inboxes := make([]Inbox, len(inboxResponses))
 	for i, resp := range inboxResponses {
-		createdAt, _ := time.Parse(time.RFC3339, resp.CreatedAt)
-		inboxes[i] = Inbox{
-			ID:           resp.ID,
-			EmailAddress: resp.EmailAddress,
-			CreatedAt:    createdAt,
-			Name:         resp.Name,
-			Description:  resp.Description,
-		}
+		inboxes[i] = resp.toInbox()
 	}
 	
 	return inboxes, nil
@@ -138,179 +286,180 @@ func (c *DefaultMailSlurpClient) GetInboxes() ([]Inbox, error) {
 
 // CreateInbox создает новый временный почтовый ящик
 func (c *DefaultMailSlurpClient) CreateInbox() (*Inbox, error) {
-	req, err := http.NewRequest("POST", c.baseURL+"/inboxes", nil)
-	if err != nil {
-		return nil, err
+	return c.CreateInboxWithOptions(CreateInboxOptions{})
+}
+
+// CreateInboxWithOptions создает почтовый ящик с заданными параметрами
+func (c *DefaultMailSlurpClient) CreateInboxWithOptions(opts CreateInboxOptions) (*Inbox, error) {
+	if opts.ExpiresAt.IsZero() && opts.ExpiresIn == 0 {
+		opts.ExpiresIn = c.defaultTTL
 	}
-	
-	req.Header.Add("x-api-key", c.apiKey)
-	req.Header.Add("Accept", "application/json")
-	
-	resp, err := c.client.Do(req)
+	q, err := opts.query()
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 	
-	body, err := ioutil.ReadAll(resp.Body)
+	var inboxResp InboxResponse
+	err = c.execute(apiCall{
+		operation: "CreateInbox",
+		method:    "POST",
+		path:      "/inboxes",
+		query:     q,
+		onResult:  func(info *RequestInfo) { info.InboxID = inboxResp.ID },
+	}, &inboxResp)
 	if err != nil {
 		return nil, err
 	}
 	
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		var errorResp ErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Message != "" {
-			return nil, fmt.Errorf("ошибка API: %s - %s, код: %d", 
-				errorResp.ErrorCode, errorResp.Message, resp.StatusCode)
-		}
-		return nil, fmt.Errorf("ошибка API: %s, код: %d", string(body), resp.StatusCode)
-	}
-	
-	// Парсим ответ
-	var inboxResp InboxResponse
-	if err := json.Unmarshal(body, &inboxResp); err != nil {
-		return nil, fmt.Errorf("не удалось распарсить ответ API: %v", err)
-	}
-	
-	createdAt, _ := time.Parse(time.RFC3339, inboxResp.CreatedAt)
-	inbox := &Inbox{
-		ID:           inboxResp.ID,
-		EmailAddress: inboxResp.EmailAddress,
-		CreatedAt:    createdAt,
-		Name:         inboxResp.Name,
-		Description:  inboxResp.Description,
-	}
-	
-	return inbox, nil
+	inbox := inboxResp.toInbox()
+	return &inbox, nil
 }
 
 // DeleteInbox удаляет почтовый ящик
 func (c *DefaultMailSlurpClient) DeleteInbox(inboxID string) error {
-	req, err := http.NewRequest("DELETE", c.baseURL+"/inboxes/"+inboxID, nil)
-	if err != nil {
-		return err
-	}
-	
-	req.Header.Add("x-api-key", c.apiKey)
-	
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		body, _ := ioutil.ReadAll(resp.Body)
-		var errorResp ErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Message != "" {
-			return fmt.Errorf("ошибка API: %s - %s, код: %d", 
-				errorResp.ErrorCode, errorResp.Message, resp.StatusCode)
-		}
-		return fmt.Errorf("ошибка API: %s, код: %d", string(body), resp.StatusCode)
-	}
-	
-	return nil
+	return c.execute(apiCall{
+		operation: "DeleteInbox",
+		method:    "DELETE",
+		path:      "/inboxes/" + url.PathEscape(inboxID),
+		inboxID:   inboxID,
+	}, nil)
 }
 
 // SendEmail отправляет электронное письмо
 func (c *DefaultMailSlurpClient) SendEmail(inboxID, to, subject, body string) error {
-	payload := strings.NewReader(fmt.Sprintf(`{
-		"to": ["%s"],
-		"subject": "%s",
-		"body": "%s"
-	}`, to, subject, body))
-	
-	req, err := http.NewRequest("POST", c.baseURL+"/inboxes/"+inboxID, payload)
-	if err != nil {
-		return err
-	}
-	
-	req.Header.Add("x-api-key", c.apiKey)
-	req.Header.Add("Content-Type", "application/json")
-	
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	
-	respBody, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
-	
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		var errorResp ErrorResponse
-		if err := json.Unmarshal(respBody, &errorResp); err == nil && errorResp.Message != "" {
-			return fmt.Errorf("ошибка API: %s - %s, код: %d", 
-				errorResp.ErrorCode, errorResp.Message, resp.StatusCode)
-		}
-		return fmt.Errorf("ошибка API: %s, код: %d", string(respBody), resp.StatusCode)
+	payload := map[string]interface{}{
+		"to":      []string{to},
+		"subject": subject,
+		"body":    body,
+	}
+	
+	return c.execute(apiCall{
+		operation: "SendEmail",
+		method:    "POST",
+		path:      "/inboxes/" + url.PathEscape(inboxID),
+		payload:   payload,
+		inboxID:   inboxID,
+	}, nil)
+}
+
+// SendHTMLEmail отправляет письмо с HTML телом
+func (c *DefaultMailSlurpClient) SendHTMLEmail(inboxID, to, subject, html string) error {
+	payload := map[string]interface{}{
+		"to":      []string{to},
+		"subject": subject,
+		"body":    html,
+		"isHTML":  true,
+		"charset": "UTF-8",
 	}
-	
-	return nil
+
+	return c.execute(apiCall{
+		operation: "SendHTMLEmail",
+		method:    "POST",
+		path:      "/inboxes/" + url.PathEscape(inboxID),
+		payload:   payload,
+		inboxID:   inboxID,
+	}, nil)
 }
 
 // WaitForLatestEmail ожидает и получает последнее входящее письмо
 func (c *DefaultMailSlurpClient) WaitForLatestEmail(inboxID string, timeout time.Duration) (*Email, error) {
-	req, err := http.NewRequest("GET", c.baseURL+"/waitForLatestEmail", nil)
-	if err != nil {
-		return nil, err
-	}
-	
 	// Добавляем параметры в URL запроса
-	q := req.URL.Query()
+	q := url.Values{}
 	q.Add("inboxId", inboxID)
 	q.Add("timeout", fmt.Sprintf("%d", int(timeout.Milliseconds())))
 	q.Add("unreadOnly", "true")
-	req.URL.RawQuery = q.Encode()
 	
-	req.Header.Add("x-api-key", c.apiKey)
-	req.Header.Add("Accept", "application/json")
-	
-	resp, err := c.client.Do(req)
+	var emailData map[string]interface{}
+	err := c.execute(apiCall{
+		operation: "WaitForLatestEmail",
+		method:    "GET",
+		path:      "/waitForLatestEmail",
+		query:     q,
+		inboxID:   inboxID,
+		onResult:  func(info *RequestInfo) { info.EmailID = getStringValue(emailData, "id") },
+	}, &emailData)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 	
-	body, err := ioutil.ReadAll(resp.Body)
+	email := parseEmail(emailData)
+	return &email, nil
+}
+
+// GetEmails получает список писем в почтовом ящике
+func (c *DefaultMailSlurpClient) GetEmails(inboxID string) ([]Email, error) {
+	// Добавляем параметры в URL запроса
+	q := url.Values{}
+	q.Add("inboxId", inboxID)
+	
+	var emailsData map[string]interface{}
+	err := c.execute(apiCall{
+		operation: "GetEmails",
+		method:    "GET",
+		path:      "/emails",
+		query:     q,
+		inboxID:   inboxID,
+	}, &emailsData)
 	if err != nil {
 		return nil, err
 	}
 	
-	if resp.StatusCode != http.StatusOK {
-		var errorResp ErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Message != "" {
-			return nil, fmt.Errorf("ошибка API: %s - %s, код: %d", 
-				errorResp.ErrorCode, errorResp.Message, resp.StatusCode)
+	// Извлекаем содержимое
+	var emails []Email
+	if content, ok := emailsData["content"].([]interface{}); ok {
+		emails = make([]Email, len(content))
+		for i, item := range content {
+			if emailData, ok := item.(map[string]interface{}); ok {
+				emails[i] = parseEmail(emailData)
+			}
 		}
-		return nil, fmt.Errorf("ошибка API: %s, код: %d", string(body), resp.StatusCode)
 	}
 	
-	// Парсим ответ в структуру Email
-	var emailData map[string]interface{}
-	if err := json.Unmarshal(body, &emailData); err != nil {
-		return nil, fmt.Errorf("не удалось распарсить ответ API: %v", err)
+	return emails, nil
+}
+
+// DeleteAllInboxEmails удаляет все письма почтового ящика, сам ящик остается
+func (c *DefaultMailSlurpClient) DeleteAllInboxEmails(inboxID string) error {
+	return c.execute(apiCall{
+		operation: "DeleteAllInboxEmails",
+		method:    "DELETE",
+		path:      "/inboxes/" + url.PathEscape(inboxID) + "/deleteAllInboxEmails",
+		inboxID:   inboxID,
+	}, nil)
+}
+
+// DownloadAttachment скачивает содержимое вложения письма по его ID из Email.Attachments
+func (c *DefaultMailSlurpClient) DownloadAttachment(emailID, attachmentID string) ([]byte, error) {
+	var data []byte
+	err := c.execute(apiCall{
+		operation: "DownloadAttachment",
+		method:    "GET",
+		path:      "/emails/" + url.PathEscape(emailID) + "/attachments/" + url.PathEscape(attachmentID),
+		emailID:   emailID,
+	}, &data)
+	if err != nil {
+		return nil, err
 	}
-	
-	// Извлекаем нужные поля из ответа
-	email := &Email{
+	return data, nil
+}
+
+// parseEmail извлекает поля письма из ответа API
+func parseEmail(emailData map[string]interface{}) Email {
+	email := Email{
 		ID:      getStringValue(emailData, "id"),
 		Subject: getStringValue(emailData, "subject"),
 		Body:    getStringValue(emailData, "body"),
 		From:    getStringValue(emailData, "from"),
+		To:      getStringSliceValue(emailData, "to"),
 		Read:    getBoolValue(emailData, "read"),
+		Attachments: getStringSliceValue(emailData, "attachments"),
 	}
 	
-	// Извлекаем получателей
-	if to, ok := emailData["to"].([]interface{}); ok {
-		email.To = make([]string, len(to))
-		for i, t := range to {
-			if str, ok := t.(string); ok {
-				email.To[i] = str
-			}
-		}
+	// Декодируем RFC 2047 слова, если API вернул заголовки без декодирования
+	email.Subject = DecodeHeader(email.Subject)
+	email.From = DecodeHeader(email.From)
+	for i, to := range email.To {
+		email.To[i] = DecodeHeader(to)
 	}
 	
 	// Парсим время создания
@@ -320,87 +469,7 @@ func (c *DefaultMailSlurpClient) WaitForLatestEmail(inboxID string, timeout time
 		}
 	}
 	
-	return email, nil
-}
-
-// GetEmails получает список писем в почтовом ящике
-func (c *DefaultMailSlurpClient) GetEmails(inboxID string) ([]Email, error) {
-	req, err := http.NewRequest("GET", c.baseURL+"/emails", nil)
-	if err != nil {
-		return nil, err
-	}
-	
-	// Добавляем параметры в URL запроса
-	q := req.URL.Query()
-	q.Add("inboxId", inboxID)
-	req.URL.RawQuery = q.Encode()
-	
-	req.Header.Add("x-api-key", c.apiKey)
-	req.Header.Add("Accept", "application/json")
-	
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	
-	if resp.StatusCode != http.StatusOK {
-		var errorResp ErrorResponse
-		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Message != "" {
-			return nil, fmt.Errorf("ошибка API: %s - %s, код: %d", 
-				errorResp.ErrorCode, errorResp.Message, resp.StatusCode)
-		}
-		return nil, fmt.Errorf("ошибка API: %s, код: %d", string(body), resp.StatusCode)
-	}
-	
-	// Парсим ответ
-	var emailsData map[string]interface{}
-	if err := json.Unmarshal(body, &emailsData); err != nil {
-		return nil, fmt.Errorf("не удалось распарсить ответ API: %v", err)
-	}
-	
-	// Извлекаем содержимое
-	var emails []Email
-	if content, ok := emailsData["content"].([]interface{}); ok {
-		emails = make([]Email, len(content))
-		for i, item := range content {
-			if emailData, ok := item.(map[string]interface{}); ok {
-				email := Email{
-					ID:      getStringValue(emailData, "id"),
-					Subject: getStringValue(emailData, "subject"),
-					Body:    getStringValue(emailData, "body"),
-					From:    getStringValue(emailData, "from"),
-					Read:    getBoolValue(emailData, "read"),
-				}
-				
-				// Извлекаем получателей
-				if to, ok := emailData["to"].([]interface{}); ok {
-					email.To = make([]string, len(to))
-					for j, t := range to {
-						if str, ok := t.(string); ok {
-							email.To[j] = str
-						}
-					}
-				}
-				
-				// Парсим время создания
-				if createdAtStr, ok := emailData["createdAt"].(string); ok {
-					if createdAt, err := time.Parse(time.RFC3339, createdAtStr); err == nil {
-						email.Created = createdAt
-					}
-				}
-				
-				emails[i] = email
-			}
-		}
-	}
-	
-	return emails, nil
+	return email
 }
 
 // Вспомогательные функции для извлечения значений из map[string]interface{}
@@ -416,4 +485,18 @@ func getBoolValue(data map[string]interface{}, key string) bool {
 		return value
 	}
 	return false
-} 
\ No newline at end of file
+}
+
+func getStringSliceValue(data map[string]interface{}, key string) []string {
+	items, ok := data[key].([]interface{})
+	if !ok {
+		return nil
+	}
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if str, ok := item.(string); ok {
+			values = append(values, str)
+		}
+	}
+	return values
+}
@@ -0,0 +1,361 @@
+package mailslurp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+var (
+	// ErrTemplateNotFound возвращается, если шаблона нет в библиотеке
+	ErrTemplateNotFound = errors.New("шаблон письма не найден")
+	// ErrEmptyTemplate - у шаблона нет ни одного тела
+	ErrEmptyTemplate = errors.New("у шаблона письма нет тела")
+	// ErrHTMLNotSupported - декорируемый клиент не умеет отправлять HTML письма
+	ErrHTMLNotSupported = errors.New("клиент не поддерживает отправку HTML писем")
+)
+
+// Расширения файлов библиотеки шаблонов: <имя>.subject, <имя>.md,
+// <имя>.html и <имя>.txt
+const (
+	templateSubjectExt  = ".subject"
+	templateMarkdownExt = ".md"
+	templateHTMLExt     = ".html"
+	templateTextExt     = ".txt"
+)
+
+// ComposedEmail - письмо с текстовой и HTML версиями тела
+type ComposedEmail struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// ComposeMarkdown создает письмо из Markdown: HTML версия получается из
+// разметки, текстовая - из HTML через HTMLToText
+func ComposeMarkdown(subject, markdown string) *ComposedEmail {
+	body := MarkdownToHTML(markdown)
+	return &ComposedEmail{Subject: subject, HTML: body, Text: HTMLToText(body)}
+}
+
+// ComposeHTML создает письмо из HTML с автоматической текстовой версией
+func ComposeHTML(subject, body string) *ComposedEmail {
+	return &ComposedEmail{Subject: subject, HTML: body, Text: HTMLToText(body)}
+}
+
+// MIME возвращает письмо в формате RFC 5322: multipart/alternative с
+// текстовой и HTML частями в UTF-8 (quoted-printable) или одну текстовую
+// часть, если HTML нет. Подходит для SMTP и отправки исходного текста.
+func (e *ComposedEmail) MIME(from string, to ...string) ([]byte, error) {
+	var buf bytes.Buffer
+	writeHeader := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	writeHeader("From", from)
+	if len(to) > 0 {
+		writeHeader("To", strings.Join(to, ", "))
+	}
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", e.Subject))
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	writeHeader("MIME-Version", "1.0")
+
+	if e.HTML == "" {
+		writeHeader("Content-Type", `text/plain; charset="utf-8"`)
+		writeHeader("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, e.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	writeHeader("Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": mw.Boundary()}))
+	buf.WriteString("\r\n")
+
+	// Версии идут от простой к сложной: почтовый клиент показывает последнюю
+	for _, part := range []struct{ contentType, text string }{
+		{"text/plain", e.Text},
+		{"text/html", e.HTML},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + `; charset="utf-8"`},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.text); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, text string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// HTMLEmailSender - клиент, который умеет отправлять HTML письма.
+// Декораторы реализуют его всегда и возвращают ErrHTMLNotSupported, если
+// обернутый клиент HTML не поддерживает.
+type HTMLEmailSender interface {
+	SendHTMLEmail(inboxID, to, subject, html string) error
+}
+
+// sendHTMLEmail передает HTML письмо клиенту, если тот его поддерживает
+func sendHTMLEmail(client MailSlurpClient, inboxID, to, subject, html string) error {
+	sender, ok := client.(HTMLEmailSender)
+	if !ok {
+		return ErrHTMLNotSupported
+	}
+	return sender.SendHTMLEmail(inboxID, to, subject, html)
+}
+
+// SendSingleBody отправляет из ящика inboxID только одну версию письма, а не
+// multipart/alternative: API отправки MailSlurp принимает одно тело. Если
+// клиент поддерживает HTMLEmailSender, отправляется HTML версия, и текстовая
+// получателю не попадает; иначе отправляется текстовая. Письмо с обеими
+// версиями собирает MIME - его нужно отправлять через SMTP.
+func SendSingleBody(client MailSlurpClient, inboxID, to string, email *ComposedEmail) error {
+	if email.HTML != "" {
+		err := sendHTMLEmail(client, inboxID, to, email.Subject, email.HTML)
+		if !errors.Is(err, ErrHTMLNotSupported) {
+			return err
+		}
+	}
+	return client.SendEmail(inboxID, to, email.Subject, email.Text)
+}
+
+// EmailTemplateSource - исходные тексты шаблона письма. Тело задается
+// Markdown или HTML (html/template); Text переопределяет автоматическую
+// текстовую версию. Все части - шаблоны с именованными переменными
+// {{.Name}}; отсутствующая переменная считается ошибкой.
+type EmailTemplateSource struct {
+	Subject  string
+	Markdown string
+	HTML     string
+	Text     string
+}
+
+// EmailTemplate - разобранный шаблон письма
+type EmailTemplate struct {
+	Name     string
+	subject  *texttemplate.Template
+	markdown *texttemplate.Template
+	html     *htmltemplate.Template
+	text     *texttemplate.Template
+}
+
+// ParseEmailTemplate разбирает шаблон письма
+func ParseEmailTemplate(name string, src EmailTemplateSource) (*EmailTemplate, error) {
+	if src.Markdown == "" && src.HTML == "" && src.Text == "" {
+		return nil, fmt.Errorf("шаблон %s: %w", name, ErrEmptyTemplate)
+	}
+	if src.Markdown != "" && src.HTML != "" {
+		return nil, fmt.Errorf("шаблон %s: задайте тело либо в Markdown, либо в HTML", name)
+	}
+
+	t := &EmailTemplate{Name: name}
+	var err error
+	parseText := func(part, text string) *texttemplate.Template {
+		if text == "" || err != nil {
+			return nil
+		}
+		var tmpl *texttemplate.Template
+		tmpl, err = texttemplate.New(name + part).Option("missingkey=error").Parse(text)
+		if err != nil {
+			err = fmt.Errorf("шаблон %s%s: %v", name, part, err)
+		}
+		return tmpl
+	}
+	t.subject = parseText(templateSubjectExt, strings.TrimSpace(src.Subject))
+	t.markdown = parseText(templateMarkdownExt, src.Markdown)
+	t.text = parseText(templateTextExt, src.Text)
+	if err != nil {
+		return nil, err
+	}
+	if src.HTML != "" {
+		t.html, err = htmltemplate.New(name + templateHTMLExt).Option("missingkey=error").Parse(src.HTML)
+		if err != nil {
+			return nil, fmt.Errorf("шаблон %s%s: %v", name, templateHTMLExt, err)
+		}
+	}
+	return t, nil
+}
+
+// Render подставляет переменные и возвращает готовое письмо
+func (t *EmailTemplate) Render(vars map[string]interface{}) (*ComposedEmail, error) {
+	if vars == nil {
+		vars = map[string]interface{}{}
+	}
+	email := &ComposedEmail{}
+	var err error
+	if t.subject != nil {
+		if email.Subject, err = executeTextTemplate(t.subject, vars); err != nil {
+			return nil, err
+		}
+		// Тема письма - одна строка
+		email.Subject = strings.Join(strings.Fields(email.Subject), " ")
+	}
+	switch {
+	case t.markdown != nil:
+		markdown, err := executeTextTemplate(t.markdown, vars)
+		if err != nil {
+			return nil, err
+		}
+		email.HTML = MarkdownToHTML(markdown)
+	case t.html != nil:
+		var buf bytes.Buffer
+		if err := t.html.Execute(&buf, vars); err != nil {
+			return nil, fmt.Errorf("шаблон %s: %v", t.Name, err)
+		}
+		email.HTML = buf.String()
+	}
+
+	if t.text != nil {
+		if email.Text, err = executeTextTemplate(t.text, vars); err != nil {
+			return nil, err
+		}
+	} else {
+		email.Text = HTMLToText(email.HTML)
+	}
+	return email, nil
+}
+
+func executeTextTemplate(tmpl *texttemplate.Template, vars map[string]interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("шаблон %s: %v", tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
+
+// TemplateLibrary - набор шаблонов писем из каталога. Шаблон name состоит
+// из файлов name.subject, name.md или name.html и, при необходимости,
+// name.txt.
+type TemplateLibrary struct {
+	templates map[string]*EmailTemplate
+	// incomplete - шаблоны без тела, например только с name.subject
+	incomplete map[string]error
+}
+
+// LoadTemplateLibrary загружает все шаблоны из каталога dir. Шаблоны без
+// тела не прерывают загрузку остальных: они перечислены в Incomplete, а
+// Template возвращает для них ErrEmptyTemplate. Ошибки в тексте шаблона
+// по-прежнему прерывают загрузку.
+func LoadTemplateLibrary(dir string) (*TemplateLibrary, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать каталог шаблонов: %v", err)
+	}
+
+	sources := map[string]*EmailTemplateSource{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		name := strings.TrimSuffix(entry.Name(), ext)
+		var field *string
+		src := sources[name]
+		if src == nil {
+			src = &EmailTemplateSource{}
+		}
+		switch ext {
+		case templateSubjectExt:
+			field = &src.Subject
+		case templateMarkdownExt:
+			field = &src.Markdown
+		case templateHTMLExt:
+			field = &src.HTML
+		case templateTextExt:
+			field = &src.Text
+		default:
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("не удалось прочитать шаблон: %v", err)
+		}
+		*field = string(data)
+		sources[name] = src
+	}
+
+	lib := &TemplateLibrary{templates: map[string]*EmailTemplate{}, incomplete: map[string]error{}}
+	for name, src := range sources {
+		tmpl, err := ParseEmailTemplate(name, *src)
+		if errors.Is(err, ErrEmptyTemplate) {
+			lib.incomplete[name] = err
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		lib.templates[name] = tmpl
+	}
+	return lib, nil
+}
+
+// Names возвращает имена шаблонов по алфавиту
+func (l *TemplateLibrary) Names() []string {
+	names := make([]string, 0, len(l.templates))
+	for name := range l.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Incomplete возвращает по алфавиту имена шаблонов, пропущенных при
+// загрузке из-за отсутствия тела
+func (l *TemplateLibrary) Incomplete() []string {
+	names := make([]string, 0, len(l.incomplete))
+	for name := range l.incomplete {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Template возвращает шаблон по имени
+func (l *TemplateLibrary) Template(name string) (*EmailTemplate, error) {
+	if err, ok := l.incomplete[name]; ok {
+		return nil, err
+	}
+	tmpl, ok := l.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	return tmpl, nil
+}
+
+// Render подставляет переменные в шаблон name
+func (l *TemplateLibrary) Render(name string, vars map[string]interface{}) (*ComposedEmail, error) {
+	tmpl, err := l.Template(name)
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.Render(vars)
+}
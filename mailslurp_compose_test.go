@@ -0,0 +1,189 @@
+package mailslurp_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "mailslurp-example"
+	"mailslurp-example/mailslurptest"
+)
+
+// TestMarkdownToHTML проверяет преобразование Markdown в HTML
+func TestMarkdownToHTML(t *testing.T) {
+	tests := []struct {
+		name, markdown, want string
+	}{
+		{"Заголовок и абзац", "# Привет, **Иван**\n\nПервая строка\nвторая строка",
+			"<h1>Привет, <strong>Иван</strong></h1>\n<p>Первая строка\nвторая строка</p>"},
+		{"Перенос строки", "строка  \nследующая", "<p>строка<br>\nследующая</p>"},
+		{"Ссылки", "[Подтвердить](https://example.com/a_b_c?x=1&y=2) и <https://example.com>",
+			`<p><a href="https://example.com/a_b_c?x=1&amp;y=2">Подтвердить</a> и <a href="https://example.com">https://example.com</a></p>`},
+		{"Опасная ссылка", "[клик](javascript:alert(1))", "<p>клик)</p>"},
+		{"Экранирование", "<script>x</script> & *курсив* _тоже_ ~~нет~~ `<b>`",
+			"<p>&lt;script&gt;x&lt;/script&gt; &amp; <em>курсив</em> <em>тоже</em> <del>нет</del> <code>&lt;b&gt;</code></p>"},
+		{"Списки", "- один\n- два\n  продолжение\n\n1. первый\n2. второй",
+			"<ul>\n<li>один</li>\n<li>два\nпродолжение</li>\n</ul>\n<ol>\n<li>первый</li>\n<li>второй</li>\n</ol>"},
+		{"Цитата и линия", "> цитата\n> **жирная**\n\n---", "<blockquote>\n<p>цитата\n<strong>жирная</strong></p>\n</blockquote>\n<hr>"},
+		{"Код", "```go\nif a < b {}\n```", "<pre><code class=\"language-go\">if a &lt; b {}</code></pre>"},
+		{"Изображение", "![Логотип](https://example.com/logo.png)", `<p><img src="https://example.com/logo.png" alt="Логотип"></p>`},
+	}
+	for _, tt := range tests {
+		if got := MarkdownToHTML(tt.markdown); got != tt.want {
+			t.Errorf("%s:\nполучено:\n%s\nожидалось:\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestComposedEmailMIME проверяет MIME структуру и обратный разбор письма
+func TestComposedEmailMIME(t *testing.T) {
+	email := ComposeMarkdown("Добро пожаловать!", "Ваш код: **482913**\n\n[Подтвердить](https://example.com/confirm)")
+	if email.Text != "Ваш код: 482913\n\nПодтвердить [1]\n\n[1] https://example.com/confirm" {
+		t.Errorf("Неверная текстовая версия: %q", email.Text)
+	}
+
+	raw, err := email.MIME("app@example.com", "user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := ParseMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("Письмо не разбирается: %v\n%s", err, raw)
+	}
+	// Quoted-printable в текстовом режиме переводит строки в CRLF, как требует MIME
+	crlf := strings.NewReplacer("\r\n", "\n")
+	if msg.Subject != email.Subject || crlf.Replace(msg.Text) != email.Text || crlf.Replace(msg.HTML) != email.HTML {
+		t.Errorf("Письмо изменилось после разбора: %+v", msg)
+	}
+	if ct := msg.Header.Get("Content-Type"); !strings.HasPrefix(ct, "multipart/alternative") {
+		t.Errorf("Неверный Content-Type: %s", ct)
+	}
+	if len(msg.Parts) != 2 || msg.Parts[0].ContentType != "text/plain" || msg.Parts[1].ContentType != "text/html" {
+		t.Errorf("Неверный порядок частей: %+v", msg.Parts)
+	}
+
+	plain, _ := (&ComposedEmail{Subject: "Тема", Text: "Только текст"}).MIME("app@example.com")
+	if msg, err := ParseMessage(bytes.NewReader(plain)); err != nil || msg.Text != "Только текст" || len(msg.Parts) != 1 {
+		t.Errorf("Неверное текстовое письмо: %+v, %v", msg, err)
+	}
+}
+
+// TestTemplateLibrary проверяет загрузку шаблонов из каталога и подстановку переменных
+func TestTemplateLibrary(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"welcome.subject": "Добро пожаловать, {{.Name}}!\n",
+		"welcome.md":      "Здравствуйте, **{{.Name}}**!\n\nВаш код: {{.Code}}",
+		"reset.subject":   "Сброс пароля",
+		"reset.html":      `<p>Перейдите по <a href="{{.Link}}">ссылке</a>, {{.Name}}</p>`,
+		"reset.txt":       "Ссылка для сброса: {{.Link}}",
+		"draft.subject":   "Черновик без тела",
+		"README":          "не шаблон",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lib, err := LoadTemplateLibrary(dir)
+	if err != nil {
+		t.Fatalf("Ошибка загрузки шаблонов: %v", err)
+	}
+	if names := lib.Names(); len(names) != 2 || names[0] != "reset" || names[1] != "welcome" {
+		t.Errorf("Неверный список шаблонов: %v", names)
+	}
+
+	welcome, err := lib.Render("welcome", map[string]interface{}{"Name": "<Иван>", "Code": 482913})
+	if err != nil {
+		t.Fatalf("Ошибка подстановки: %v", err)
+	}
+	if welcome.Subject != "Добро пожаловать, <Иван>!" {
+		t.Errorf("Неверная тема: %q", welcome.Subject)
+	}
+	if !strings.Contains(welcome.HTML, "<strong>&lt;Иван&gt;</strong>") || welcome.Text != "Здравствуйте, <Иван>!\n\nВаш код: 482913" {
+		t.Errorf("Неверное письмо: %+v", welcome)
+	}
+
+	reset, err := lib.Render("reset", map[string]interface{}{"Name": "<b>Иван</b>", "Link": "https://example.com/reset?t=1"})
+	if err != nil {
+		t.Fatalf("Ошибка подстановки: %v", err)
+	}
+	if !strings.Contains(reset.HTML, "&lt;b&gt;Иван&lt;/b&gt;") || reset.Text != "Ссылка для сброса: https://example.com/reset?t=1" {
+		t.Errorf("Неверное письмо: %+v", reset)
+	}
+
+	if _, err := lib.Render("welcome", map[string]interface{}{"Name": "Иван"}); err == nil || !strings.Contains(err.Error(), "Code") {
+		t.Errorf("Отсутствующая переменная должна быть ошибкой, получено: %v", err)
+	}
+	if _, err := lib.Render("missing", nil); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("Ожидалась ошибка ErrTemplateNotFound, получено: %v", err)
+	}
+	// Шаблон без тела не мешает загрузке остальных
+	if incomplete := lib.Incomplete(); len(incomplete) != 1 || incomplete[0] != "draft" {
+		t.Errorf("Неверный список неполных шаблонов: %v", incomplete)
+	}
+	if _, err := lib.Render("draft", nil); !errors.Is(err, ErrEmptyTemplate) {
+		t.Errorf("Ожидалась ошибка ErrEmptyTemplate, получено: %v", err)
+	}
+}
+
+// TestSendSingleBody проверяет отправку HTML версии и запасной текстовый вариант
+func TestSendSingleBody(t *testing.T) {
+	email := ComposeMarkdown("Привет", "**Жирный** текст")
+
+	server := newFakeMailSlurpServer(t)
+	client := server.client()
+	inbox, err := client.CreateInbox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SendSingleBody(client, inbox.ID, inbox.EmailAddress, email); err != nil {
+		t.Fatalf("Ошибка отправки: %v", err)
+	}
+	emails, _ := client.GetEmails(inbox.ID)
+	if len(emails) != 1 || emails[0].Body != email.HTML {
+		t.Errorf("Должна быть отправлена HTML версия: %+v", emails)
+	}
+
+	mock := mailslurptest.NewMockClient()
+	mockInbox, _ := mock.CreateInbox()
+	if err := SendSingleBody(mock, mockInbox.ID, "user@example.com", email); err != nil {
+		t.Fatal(err)
+	}
+	if sent := mock.Sent(); len(sent) != 1 || sent[0].Body != "Жирный текст" {
+		t.Errorf("Клиент без HTML должен получить текстовую версию: %+v", sent)
+	}
+
+	// Декораторы передают HTML обернутому клиенту или сообщают, что он не поддерживается
+	store, err := OpenMessageStore(filepath.Join(t.TempDir(), "store"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	owners := NewMemoryOwnershipStore()
+	owners.SetInboxOwner(inbox.ID, "ualice")
+	tenant, err := NewTenantClient(client, "ualice", owners)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := NewClientMetrics()
+	wrapped := NewInstrumentedMailSlurpClient(NewCachingMailSlurpClient(tenant, store, 0), metrics)
+	if err := SendSingleBody(wrapped, inbox.ID, inbox.EmailAddress, email); err != nil {
+		t.Fatalf("Ошибка отправки через декораторы: %v", err)
+	}
+	emails, _ = client.GetEmails(inbox.ID)
+	if len(emails) != 2 || emails[1].Body != email.HTML {
+		t.Errorf("Декораторы должны передать HTML версию: %+v", emails)
+	}
+
+	wrappedMock := NewInstrumentedMailSlurpClient(NewCachingMailSlurpClient(mock, store, 0), metrics)
+	if err := SendSingleBody(wrappedMock, mockInbox.ID, "user@example.com", email); err != nil {
+		t.Fatal(err)
+	}
+	if sent := mock.Sent(); len(sent) != 2 || sent[1].Body != "Жирный текст" {
+		t.Errorf("Обернутый клиент без HTML должен получить текстовую версию: %+v", sent)
+	}
+}
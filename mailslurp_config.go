@@ -0,0 +1,226 @@
+package mailslurp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Имена переменных окружения, переопределяющих настройки профиля
+const (
+	envConfigPath  = "NEUROMAIL_CONFIG"
+	envProfile     = "NEUROMAIL_PROFILE"
+	envAPIKey      = "NEUROMAIL_API_KEY"
+	envBaseURL     = "NEUROMAIL_BASE_URL"
+	envTimeout     = "NEUROMAIL_TIMEOUT"
+	envWaitTimeout = "NEUROMAIL_WAIT_TIMEOUT"
+	envDefaultTTL  = "NEUROMAIL_DEFAULT_TTL"
+	envPlan        = "NEUROMAIL_PLAN"
+)
+
+// defaultProfileName - имя профиля, который используется, если профиль не выбран явно
+const defaultProfileName = "default"
+
+// ErrProfileNotFound возвращается, если запрошенного профиля нет в файле конфигурации
+var ErrProfileNotFound = errors.New("профиль не найден")
+
+// ErrNoAPIKey возвращается, если API ключ не задан ни в профиле, ни в окружении
+var ErrNoAPIKey = errors.New("API ключ не задан: укажите его в профиле или в " + envAPIKey)
+
+// Duration - time.Duration, который читается из JSON строкой вида "30s" или "1h"
+type Duration time.Duration
+
+// UnmarshalJSON разбирает длительность из строки или числа миллисекунд
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("неверная длительность %q: %v", s, err)
+		}
+		*d = Duration(parsed)
+		return nil
+	}
+	var ms int64
+	if err := json.Unmarshal(data, &ms); err != nil {
+		return fmt.Errorf("неверная длительность %s", string(data))
+	}
+	*d = Duration(time.Duration(ms) * time.Millisecond)
+	return nil
+}
+
+// MarshalJSON записывает длительность строкой
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Profile описывает настройки подключения к MailSlurp
+type Profile struct {
+	Name    string `json:"-"`
+	APIKey  string `json:"apiKey,omitempty"`
+	BaseURL string `json:"baseUrl,omitempty"`
+	// Timeout ограничивает любой HTTP запрос, включая ожидание письма,
+	// поэтому он должен быть больше WaitTimeout (0 - без ограничения)
+	Timeout Duration `json:"timeout,omitempty"`
+	// WaitTimeout - время ожидания письма по умолчанию
+	WaitTimeout Duration `json:"waitTimeout,omitempty"`
+	// DefaultTTL - время жизни создаваемых ящиков по умолчанию
+	DefaultTTL Duration `json:"defaultTtl,omitempty"`
+	// Plan - тарифный план ключа: free, basic, professional, enterprise
+	Plan string `json:"plan,omitempty"`
+}
+
+// Config представляет собой файл конфигурации с именованными профилями
+type Config struct {
+	DefaultProfile string             `json:"defaultProfile,omitempty"`
+	Profiles       map[string]Profile `json:"profiles"`
+}
+
+// DefaultConfigPath возвращает путь к файлу конфигурации:
+// $NEUROMAIL_CONFIG или ~/.config/neuromail/config.json
+func DefaultConfigPath() string {
+	if path := os.Getenv(envConfigPath); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "neuromail", "config.json")
+}
+
+// LoadConfig читает файл конфигурации. Отсутствующий файл не считается ошибкой.
+func LoadConfig(path string) (*Config, error) {
+	cfg := &Config{Profiles: map[string]Profile{}}
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("не удалось разобрать файл конфигурации %s: %v", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]Profile{}
+	}
+	return cfg, nil
+}
+
+// Profile возвращает профиль по имени. Пустое имя означает профиль
+// из $NEUROMAIL_PROFILE, затем defaultProfile из файла, затем "default".
+// Профиль по умолчанию может отсутствовать в файле, явно запрошенный - нет.
+func (c *Config) Profile(name string) (*Profile, error) {
+	explicit := name != ""
+	if name == "" {
+		name = os.Getenv(envProfile)
+		explicit = name != ""
+	}
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		name = defaultProfileName
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok && explicit {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	profile.Name = name
+	return &profile, nil
+}
+
+// ApplyEnv переопределяет поля профиля значениями переменных NEUROMAIL_*
+func (p *Profile) ApplyEnv(lookup func(string) (string, bool)) error {
+	if v, ok := lookup(envAPIKey); ok && v != "" {
+		p.APIKey = v
+	}
+	if v, ok := lookup(envBaseURL); ok && v != "" {
+		p.BaseURL = v
+	}
+	if v, ok := lookup(envPlan); ok && v != "" {
+		p.Plan = v
+	}
+
+	durations := []struct {
+		env   string
+		field *Duration
+	}{
+		{envTimeout, &p.Timeout},
+		{envWaitTimeout, &p.WaitTimeout},
+		{envDefaultTTL, &p.DefaultTTL},
+	}
+	for _, d := range durations {
+		v, ok := lookup(d.env)
+		if !ok || v == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("неверное значение %s=%q: %v", d.env, v, err)
+		}
+		*d.field = Duration(parsed)
+	}
+	return nil
+}
+
+// withDefaults заполняет незаданные поля значениями по умолчанию
+func (p *Profile) withDefaults() {
+	if p.BaseURL == "" {
+		p.BaseURL = defaultBaseURL
+	}
+	p.BaseURL = strings.TrimRight(p.BaseURL, "/")
+	if p.WaitTimeout == 0 {
+		p.WaitTimeout = Duration(60 * time.Second)
+	}
+}
+
+// LoadProfile загружает файл конфигурации, выбирает профиль и
+// применяет переопределения из окружения
+func LoadProfile(name string) (*Profile, error) {
+	cfg, err := LoadConfig(DefaultConfigPath())
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := cfg.Profile(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := profile.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	profile.withDefaults()
+	return profile, nil
+}
+
+// Validate проверяет, что в профиле есть все необходимое для работы клиента
+func (p *Profile) Validate() error {
+	if p.APIKey == "" {
+		return ErrNoAPIKey
+	}
+	return nil
+}
+
+// ResolveProfile работает как LoadProfile, но дополнительно требует API ключ
+func ResolveProfile(name string) (*Profile, error) {
+	profile, err := LoadProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
@@ -0,0 +1,62 @@
+package mailslurp
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLoadProfile проверяет выбор профиля и переопределение из окружения
+func TestLoadProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	config := `{
+		"defaultProfile": "staging",
+		"profiles": {
+			"staging": {"apiKey": "staging-key", "baseUrl": "http://localhost:8080/", "timeout": "90s", "plan": "basic"},
+			"prod": {"apiKey": "prod-key", "waitTimeout": 120000, "defaultTtl": "1h"}
+		}
+	}`
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(envConfigPath, path)
+
+	profile, err := LoadProfile("")
+	if err != nil {
+		t.Fatalf("Ошибка при загрузке профиля: %v", err)
+	}
+	if profile.Name != "staging" || profile.APIKey != "staging-key" || profile.BaseURL != "http://localhost:8080" {
+		t.Errorf("Неверный профиль по умолчанию: %+v", profile)
+	}
+	if time.Duration(profile.Timeout) != 90*time.Second {
+		t.Errorf("Неверный таймаут: %v", time.Duration(profile.Timeout))
+	}
+
+	t.Setenv(envAPIKey, "env-key")
+	profile, err = LoadProfile("prod")
+	if err != nil {
+		t.Fatalf("Ошибка при загрузке профиля: %v", err)
+	}
+	if profile.APIKey != "env-key" {
+		t.Errorf("Ключ из окружения не применен: %s", profile.APIKey)
+	}
+	if time.Duration(profile.WaitTimeout) != 2*time.Minute || time.Duration(profile.DefaultTTL) != time.Hour {
+		t.Errorf("Неверные длительности: %+v", profile)
+	}
+
+	if _, err := LoadProfile("missing"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("Ожидалась ошибка ErrProfileNotFound, получено: %v", err)
+	}
+}
+
+// TestResolveProfileRequiresKey проверяет, что без ключа профиль не проходит проверку
+func TestResolveProfileRequiresKey(t *testing.T) {
+	t.Setenv(envConfigPath, filepath.Join(t.TempDir(), "missing.json"))
+	t.Setenv(envAPIKey, "")
+
+	if _, err := ResolveProfile(""); !errors.Is(err, ErrNoAPIKey) {
+		t.Errorf("Ожидалась ошибка ErrNoAPIKey, получено: %v", err)
+	}
+}
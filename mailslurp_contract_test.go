@@ -0,0 +1,53 @@
+package mailslurp_test
+
+import (
+	"testing"
+
+	. "mailslurp-example"
+	"mailslurp-example/mailslurptest"
+)
+
+// TestClientContract прогоняет контракт MailSlurpClient для всех реализаций
+func TestClientContract(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		mailslurptest.RunClientContract(t, func(t *testing.T) MailSlurpClient {
+			return newFakeMailSlurpServer(t).client()
+		})
+	})
+
+	t.Run("Mock", func(t *testing.T) {
+		mailslurptest.RunClientContract(t, func(t *testing.T) MailSlurpClient {
+			return mailslurptest.NewMockClient()
+		})
+	})
+
+	t.Run("Caching", func(t *testing.T) {
+		mailslurptest.RunClientContract(t, func(t *testing.T) MailSlurpClient {
+			store, err := OpenMessageStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			return NewCachingMailSlurpClient(newFakeMailSlurpServer(t).client(), store, 0)
+		})
+	})
+
+	t.Run("Instrumented", func(t *testing.T) {
+		mailslurptest.RunClientContract(t, func(t *testing.T) MailSlurpClient {
+			return NewInstrumentedMailSlurpClient(mailslurptest.NewMockClient(), NewClientMetrics())
+		})
+	})
+
+	t.Run("Tenant", func(t *testing.T) {
+		mailslurptest.RunClientContract(t, func(t *testing.T) MailSlurpClient {
+			client, err := NewTenantClient(mailslurptest.NewMockClient(), "ucontract", NewMemoryOwnershipStore())
+			if err != nil {
+				t.Fatal(err)
+			}
+			return client
+		})
+	})
+
+	t.Run("Live", func(t *testing.T) {
+		mailslurptest.RunClientContract(t, newLiveTestClient)
+	})
+}
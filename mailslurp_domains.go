@@ -0,0 +1,109 @@
+package mailslurp
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ErrDomainNotVerified возвращается при попытке использовать неподтвержденный домен
+var ErrDomainNotVerified = errors.New("домен не подтвержден")
+
+// DNSRecord представляет собой DNS запись, которую нужно добавить для подтверждения домена
+type DNSRecord struct {
+	Name       string `json:"name"`
+	RecordType string `json:"recordType"` // TXT, MX, CNAME
+	Value      string `json:"value"`
+	TTL        int    `json:"ttl,omitempty"`
+	Required   bool   `json:"required"`
+}
+
+// Domain представляет собой собственный домен, подключенный к MailSlurp
+type Domain struct {
+	ID                string      `json:"id"`
+	Domain            string      `json:"domain"`
+	IsVerified        bool        `json:"isVerified"`
+	VerificationToken string      `json:"verificationToken,omitempty"`
+	Records           []DNSRecord `json:"domainNameRecords,omitempty"`
+	CreatedAt         time.Time   `json:"createdAt"`
+}
+
+// DomainManager определяет операции с собственными доменами.
+// Доступно на планах professional и enterprise.
+type DomainManager interface {
+	CreateDomain(domain string) (*Domain, error)
+	GetDomains() ([]Domain, error)
+	GetDomain(domainID string) (*Domain, error)
+	GetDomainRecords(domainID string) ([]DNSRecord, error)
+	IsDomainVerified(domainID string) (bool, error)
+	DeleteDomain(domainID string) error
+}
+
+// CreateDomain подключает собственный домен и возвращает DNS записи для его подтверждения
+func (c *DefaultMailSlurpClient) CreateDomain(domain string) (*Domain, error) {
+	payload := map[string]string{"domain": domain, "domainType": string(InboxTypeHTTP)}
+
+	var created Domain
+	err := c.execute(apiCall{operation: "CreateDomain", method: "POST", path: "/domains", payload: payload}, &created)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// GetDomains получает список подключенных доменов
+func (c *DefaultMailSlurpClient) GetDomains() ([]Domain, error) {
+	var domains []Domain
+	if err := c.execute(apiCall{operation: "GetDomains", method: "GET", path: "/domains"}, &domains); err != nil {
+		return nil, err
+	}
+	return domains, nil
+}
+
+// GetDomain получает домен вместе с его DNS записями и статусом подтверждения
+func (c *DefaultMailSlurpClient) GetDomain(domainID string) (*Domain, error) {
+	var domain Domain
+	err := c.execute(apiCall{operation: "GetDomain", method: "GET", path: "/domains/" + url.PathEscape(domainID)}, &domain)
+	if err != nil {
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// GetDomainRecords возвращает DNS записи (TXT/MX/CNAME), необходимые для подтверждения домена
+func (c *DefaultMailSlurpClient) GetDomainRecords(domainID string) ([]DNSRecord, error) {
+	domain, err := c.GetDomain(domainID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.Records, nil
+}
+
+// IsDomainVerified проверяет, подтвержден ли домен
+func (c *DefaultMailSlurpClient) IsDomainVerified(domainID string) (bool, error) {
+	domain, err := c.GetDomain(domainID)
+	if err != nil {
+		return false, err
+	}
+	return domain.IsVerified, nil
+}
+
+// DeleteDomain удаляет домен
+func (c *DefaultMailSlurpClient) DeleteDomain(domainID string) error {
+	return c.execute(apiCall{operation: "DeleteDomain", method: "DELETE", path: "/domains/" + url.PathEscape(domainID)}, nil)
+}
+
+// CreateInboxOnDomain создает ящик на подтвержденном собственном домене
+func CreateInboxOnDomain(client MailSlurpClient, domains DomainManager, domainID string, opts CreateInboxOptions) (*Inbox, error) {
+	domain, err := domains.GetDomain(domainID)
+	if err != nil {
+		return nil, err
+	}
+	if !domain.IsVerified {
+		return nil, fmt.Errorf("%w: %s", ErrDomainNotVerified, domain.Domain)
+	}
+	opts.DomainID = domain.ID
+	opts.Domain = domain.Domain
+	return client.CreateInboxWithOptions(opts)
+}
@@ -0,0 +1,66 @@
+package mailslurp_test
+
+import (
+	"errors"
+	"testing"
+
+	. "mailslurp-example"
+)
+
+// TestDomainLifecycle проверяет подключение, подтверждение и удаление домена на фейковом сервере
+func TestDomainLifecycle(t *testing.T) {
+	server := newFakeMailSlurpServer(t)
+	client := server.client()
+	domains := client.(DomainManager)
+
+	domain, err := domains.CreateDomain("mail.example.com")
+	if err != nil {
+		t.Fatalf("Ошибка при создании домена: %v", err)
+	}
+	if domain.ID == "" || domain.Domain != "mail.example.com" || domain.IsVerified {
+		t.Fatalf("Неверный домен: %+v", domain)
+	}
+
+	records, err := domains.GetDomainRecords(domain.ID)
+	if err != nil {
+		t.Fatalf("Ошибка при получении DNS записей: %v", err)
+	}
+	types := map[string]bool{}
+	for _, record := range records {
+		types[record.RecordType] = true
+	}
+	if !types["TXT"] || !types["MX"] || !types["CNAME"] {
+		t.Errorf("Ожидались записи TXT, MX и CNAME, получено: %+v", records)
+	}
+
+	// Пока домен не подтвержден, ящик на нем создать нельзя
+	if _, err := CreateInboxOnDomain(client, domains, domain.ID, CreateInboxOptions{}); !errors.Is(err, ErrDomainNotVerified) {
+		t.Errorf("Ожидалась ошибка ErrDomainNotVerified, получено: %v", err)
+	}
+
+	server.verifyDomain(domain.ID)
+	verified, err := domains.IsDomainVerified(domain.ID)
+	if err != nil || !verified {
+		t.Fatalf("Домен должен быть подтвержден: %v, %v", verified, err)
+	}
+
+	inbox, err := CreateInboxOnDomain(client, domains, domain.ID, CreateInboxOptions{LocalPart: "support"})
+	if err != nil {
+		t.Fatalf("Ошибка при создании ящика на домене: %v", err)
+	}
+	if inbox.EmailAddress != "support@mail.example.com" || inbox.DomainID != domain.ID {
+		t.Errorf("Неверный ящик на домене: %+v", inbox)
+	}
+
+	list, err := domains.GetDomains()
+	if err != nil || len(list) != 1 {
+		t.Fatalf("Неверный список доменов: %+v, %v", list, err)
+	}
+
+	if err := domains.DeleteDomain(domain.ID); err != nil {
+		t.Fatalf("Ошибка при удалении домена: %v", err)
+	}
+	if _, err := domains.GetDomain(domain.ID); err == nil {
+		t.Error("Ожидалась ошибка для удаленного домена")
+	}
+}
@@ -0,0 +1,68 @@
+package mailslurp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Ошибки, с которыми можно сравнивать через errors.Is
+var (
+	// ErrPlanLimitExceeded - достигнут лимит тарифного плана
+	ErrPlanLimitExceeded = errors.New("ошибка API: PLAN_LIMIT_EXCEEDED")
+	// ErrRateLimited - слишком много запросов
+	ErrRateLimited = errors.New("ошибка API: слишком много запросов")
+	// ErrNotFound - ящик, письмо или домен не найдены
+	ErrNotFound = errors.New("ошибка API: не найдено")
+	// ErrUnauthorized - неверный или неактивный API ключ
+	ErrUnauthorized = errors.New("ошибка API: неверный API ключ")
+)
+
+// APIError представляет собой ошибку, которую вернул API MailSlurp
+type APIError struct {
+	StatusCode int
+	ErrorCode  string
+	Message    string
+	Body       string
+}
+
+// newAPIError разбирает тело ответа с ошибкой. API ключи, которые сервер
+// может вернуть в теле ошибки, маскируются вместе с переданными секретами.
+func newAPIError(body []byte, statusCode int, secrets ...string) *APIError {
+	apiErr := &APIError{StatusCode: statusCode, Body: RedactSecrets(string(body), secrets...)}
+	var errorResp ErrorResponse
+	if err := json.Unmarshal(body, &errorResp); err == nil {
+		apiErr.ErrorCode = errorResp.ErrorCode
+		apiErr.Message = RedactSecrets(errorResp.Message, secrets...)
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("ошибка API: %s - %s, код: %d", e.ErrorCode, e.Message, e.StatusCode)
+	}
+	return fmt.Sprintf("ошибка API: %s, код: %d", e.Body, e.StatusCode)
+}
+
+// Is позволяет сравнивать APIError с ErrPlanLimitExceeded, ErrRateLimited,
+// ErrNotFound и ErrUnauthorized через errors.Is
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrPlanLimitExceeded:
+		return e.ErrorCode == "PLAN_LIMIT_EXCEEDED" || e.StatusCode == http.StatusPaymentRequired
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	}
+	return false
+}
+
+// IsQuotaError сообщает, что ошибка вызвана исчерпанием квоты или лимита запросов
+func IsQuotaError(err error) bool {
+	return errors.Is(err, ErrPlanLimitExceeded) || errors.Is(err, ErrRateLimited)
+}
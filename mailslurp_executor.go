@@ -0,0 +1,303 @@
+package mailslurp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RequestInfo описывает вызов API, который выполняет клиент.
+// Доступен middleware и хукам через RequestInfoFromContext.
+type RequestInfo struct {
+	// Operation - имя метода клиента, например "CreateInbox"
+	Operation string
+	InboxID   string
+	EmailID   string
+	// Attempts - количество отправленных попыток запроса
+	Attempts int
+	Start    time.Time
+}
+
+type requestInfoKey struct{}
+
+// RequestInfoFromContext возвращает описание вызова из контекста запроса
+func RequestInfoFromContext(ctx context.Context) *RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(*RequestInfo)
+	return info
+}
+
+// BeforeRequestHook вызывается перед отправкой запроса к API.
+// Может изменить запрос (например, заголовки) или прервать его, вернув ошибку.
+type BeforeRequestHook func(req *http.Request) error
+
+// AfterResponseHook вызывается после получения ответа API и чтения его тела.
+// При сетевой ошибке resp и body равны nil.
+type AfterResponseHook func(req *http.Request, resp *http.Response, body []byte, err error)
+
+// Middleware оборачивает транспорт клиента. Middleware, переданные первыми,
+// оказываются снаружи цепочки и видят запрос раньше остальных.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc позволяет использовать функцию как http.RoundTripper
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip вызывает f(req)
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// ClientOption настраивает DefaultMailSlurpClient
+type ClientOption func(c *DefaultMailSlurpClient)
+
+// WithTransport задает базовый http.RoundTripper, через который уходят запросы
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *DefaultMailSlurpClient) {
+		c.transport = transport
+	}
+}
+
+// WithMiddleware добавляет middleware в цепочку клиента
+func WithMiddleware(middleware ...Middleware) ClientOption {
+	return func(c *DefaultMailSlurpClient) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+// WithBeforeRequest добавляет хуки, вызываемые перед каждым запросом
+func WithBeforeRequest(hooks ...BeforeRequestHook) ClientOption {
+	return func(c *DefaultMailSlurpClient) {
+		c.before = append(c.before, hooks...)
+	}
+}
+
+// WithAfterResponse добавляет хуки, вызываемые после каждого ответа
+func WithAfterResponse(hooks ...AfterResponseHook) ClientOption {
+	return func(c *DefaultMailSlurpClient) {
+		c.after = append(c.after, hooks...)
+	}
+}
+
+// apply применяет опции к клиенту и собирает цепочку middleware
+func (c *DefaultMailSlurpClient) apply(opts []ClientOption) *DefaultMailSlurpClient {
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	transport := c.transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	transport = countAttempts(transport)
+	if c.limiter != nil {
+		transport = rateLimit(c.limiter)(transport)
+	}
+	if c.logAttempts != nil {
+		transport = c.logAttempts(transport)
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		transport = c.middleware[i](transport)
+	}
+	c.client.Transport = transport
+	return c
+}
+
+// apiCall описывает один вызов API
+type apiCall struct {
+	operation string
+	method    string
+	path      string
+	query     url.Values
+	payload   interface{}
+	inboxID   string
+	emailID   string
+	// onResult вызывается после разбора ответа и может дополнить RequestInfo
+	// данными из него, например ID созданного ящика
+	onResult func(info *RequestInfo)
+}
+
+// execute выполняет вызов API: формирует запрос, прогоняет его через хуки
+// и цепочку middleware, читает ответ и разбирает JSON в out (если out != nil).
+// Если out имеет тип *[]byte, в него записывается тело ответа без разбора.
+func (c *DefaultMailSlurpClient) execute(call apiCall, out interface{}) (err error) {
+	info := &RequestInfo{
+		Operation: call.operation,
+		InboxID:   call.inboxID,
+		EmailID:   call.emailID,
+		Start:     time.Now(),
+	}
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := c.startSpan(ctx, call)
+	var resp *http.Response
+	defer func() { endSpan(span, info, resp, err) }()
+	ctx = context.WithValue(ctx, requestInfoKey{}, info)
+
+	var body io.Reader
+	if call.payload != nil {
+		payload, err := json.Marshal(call.payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, call.method, c.baseURL+call.path, body)
+	if err != nil {
+		return err
+	}
+	if len(call.query) > 0 {
+		req.URL.RawQuery = call.query.Encode()
+	}
+
+	req.Header.Add("x-api-key", c.apiKey)
+	raw, isRaw := out.(*[]byte)
+	if isRaw {
+		req.Header.Add("Accept", "application/octet-stream")
+	} else {
+		req.Header.Add("Accept", "application/json")
+	}
+	if call.payload != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	if span != nil {
+		req.Header.Set("traceparent", span.SpanContext().Traceparent())
+	}
+
+	for _, hook := range c.before {
+		if err := hook(req); err != nil {
+			return err
+		}
+	}
+
+	resp, respBody, err := c.roundTrip(req)
+	for _, hook := range c.after {
+		hook(req, resp, respBody, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(respBody, resp.StatusCode, c.apiKey)
+	}
+
+	if isRaw {
+		*raw = respBody
+		return nil
+	}
+	if out == nil || len(bytes.TrimSpace(respBody)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("не удалось распарсить ответ API: %v", err)
+	}
+	if call.onResult != nil {
+		call.onResult(info)
+	}
+	return nil
+}
+
+// roundTrip отправляет запрос и читает тело ответа целиком
+func (c *DefaultMailSlurpClient) roundTrip(req *http.Request) (*http.Response, []byte, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, nil, err
+	}
+	return resp, body, nil
+}
+
+// countAttempts - внутренний middleware, который считает попытки запроса в RequestInfo.
+// Всегда стоит ближе всего к сети, поэтому учитывает и повторы.
+func countAttempts(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if info := RequestInfoFromContext(req.Context()); info != nil {
+			info.Attempts++
+		}
+		return next.RoundTrip(req)
+	})
+}
+
+// RetryPolicy задает правила повтора запросов
+type RetryPolicy struct {
+	// MaxAttempts - максимальное количество попыток, включая первую
+	MaxAttempts int
+	// BaseDelay - задержка перед первым повтором, далее удваивается
+	BaseDelay time.Duration
+	// OnRetry вызывается перед каждым повтором с именем операции.
+	// Для метрик повторов достаточно WithMetrics.
+	OnRetry func(operation string)
+}
+
+// RetryMiddleware повторяет запросы при сетевых ошибках, ответах 429 и 5xx
+// с экспоненциальной задержкой, как withRetry во frontend.
+// Запросы POST повторяются только при 429, чтобы не создать дубликаты.
+func RetryMiddleware(policy RetryPolicy) Middleware {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			delay := policy.BaseDelay
+			for attempt := 1; ; attempt++ {
+				resp, err := next.RoundTrip(req)
+				if attempt >= policy.MaxAttempts || !shouldRetry(req, resp, err) {
+					return resp, err
+				}
+				if req.Body != nil && req.GetBody == nil {
+					return resp, err
+				}
+
+				if resp != nil {
+					io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+				}
+				if policy.OnRetry != nil {
+					operation := req.Method + " " + req.URL.Path
+					if info := RequestInfoFromContext(req.Context()); info != nil {
+						operation = info.Operation
+					}
+					policy.OnRetry(operation)
+				}
+
+				select {
+				case <-req.Context().Done():
+					return nil, req.Context().Err()
+				case <-time.After(delay):
+				}
+				delay *= 2
+
+				if req.GetBody != nil {
+					body, err := req.GetBody()
+					if err != nil {
+						return nil, err
+					}
+					req = req.Clone(req.Context())
+					req.Body = body
+				}
+			}
+		})
+	}
+}
+
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return req.Context().Err() == nil && req.Method != http.MethodPost
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return resp.StatusCode >= 500 && req.Method != http.MethodPost
+}
@@ -0,0 +1,136 @@
+package mailslurp_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	. "mailslurp-example"
+)
+
+// TestClientHooksAndMiddleware проверяет порядок хуков и цепочки middleware
+func TestClientHooksAndMiddleware(t *testing.T) {
+	server := newFakeMailSlurpServer(t)
+
+	var calls []string
+	tracing := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" "+RequestInfoFromContext(req.Context()).Operation)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+
+	var after []string
+	client := NewMailSlurpClientFromProfile(&Profile{APIKey: server.apiKey, BaseURL: server.URL},
+		WithBeforeRequest(func(req *http.Request) error {
+			req.Header.Set("X-Trace-Id", "trace-1")
+			return nil
+		}),
+		WithMiddleware(tracing("outer"), tracing("inner")),
+		WithAfterResponse(func(req *http.Request, resp *http.Response, body []byte, err error) {
+			info := RequestInfoFromContext(req.Context())
+			after = append(after, info.Operation+" "+resp.Status)
+		}),
+	)
+
+	inbox, err := client.CreateInbox()
+	if err != nil {
+		t.Fatalf("Ошибка при создании почтового ящика: %v", err)
+	}
+	if err := client.DeleteInbox(inbox.ID); err != nil {
+		t.Fatalf("Ошибка при удалении почтового ящика: %v", err)
+	}
+
+	if got := strings.Join(calls, ", "); got != "outer CreateInbox, inner CreateInbox, outer DeleteInbox, inner DeleteInbox" {
+		t.Errorf("Неверный порядок middleware: %s", got)
+	}
+	if got := strings.Join(after, ", "); got != "CreateInbox 201 Created, DeleteInbox 204 No Content" {
+		t.Errorf("Неверные вызовы хуков: %s", got)
+	}
+	if got := server.lastRequest().Header.Get("X-Trace-Id"); got != "trace-1" {
+		t.Errorf("Заголовок из хука не передан: %q", got)
+	}
+}
+
+// TestBeforeRequestHookAbort проверяет, что хук может прервать запрос
+func TestBeforeRequestHookAbort(t *testing.T) {
+	server := newFakeMailSlurpServer(t)
+	errBlocked := errors.New("запрос заблокирован")
+	client := NewMailSlurpClientFromProfile(&Profile{APIKey: server.apiKey, BaseURL: server.URL},
+		WithBeforeRequest(func(req *http.Request) error { return errBlocked }))
+
+	if _, err := client.GetInboxes(); !errors.Is(err, errBlocked) {
+		t.Errorf("Ожидалась ошибка хука, получено: %v", err)
+	}
+	if server.requestCount() != 0 {
+		t.Errorf("Запрос не должен был уйти на сервер")
+	}
+}
+
+// TestRetryMiddleware проверяет повторы при ошибках сервера и подсчет попыток
+func TestRetryMiddleware(t *testing.T) {
+	server := newFakeMailSlurpServer(t)
+	metrics := NewClientMetrics()
+
+	var attempts int
+	var retried []string
+	client := NewMailSlurpClientFromProfile(&Profile{APIKey: server.apiKey, BaseURL: server.URL},
+		WithMiddleware(RetryMiddleware(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond,
+			OnRetry: func(operation string) { retried = append(retried, operation) }})),
+		WithMetrics(metrics),
+		WithAfterResponse(func(req *http.Request, resp *http.Response, body []byte, err error) {
+			attempts = RequestInfoFromContext(req.Context()).Attempts
+		}),
+	)
+
+	server.failNext(http.StatusServiceUnavailable, "UNAVAILABLE")
+	server.failNext(http.StatusTooManyRequests, "TOO_MANY_REQUESTS")
+	if _, err := client.GetInboxes(); err != nil {
+		t.Fatalf("Запрос должен был пройти после повторов: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Ожидалось 3 попытки, получено %d", attempts)
+	}
+
+	// POST не повторяется при ошибке сервера, чтобы не создать дубликат ящика
+	server.failNext(http.StatusInternalServerError, "INTERNAL")
+	if _, err := client.CreateInbox(); err == nil {
+		t.Error("Ожидалась ошибка для CreateInbox")
+	}
+	if attempts != 1 {
+		t.Errorf("POST не должен повторяться, попыток: %d", attempts)
+	}
+
+	// POST с телом повторяется при 429
+	inbox, err := client.CreateInbox()
+	if err != nil {
+		t.Fatalf("Ошибка при создании почтового ящика: %v", err)
+	}
+	server.failNext(http.StatusTooManyRequests, "TOO_MANY_REQUESTS")
+	if err := client.SendEmail(inbox.ID, inbox.EmailAddress, "Повтор", "Тело"); err != nil {
+		t.Fatalf("Ошибка при отправке письма: %v", err)
+	}
+	email, err := client.WaitForLatestEmail(inbox.ID, time.Second)
+	if err != nil || email.Subject != "Повтор" {
+		t.Fatalf("Письмо после повтора не получено: %+v, %v", email, err)
+	}
+
+	if strings.Join(retried, ",") != "GetInboxes,GetInboxes,SendEmail" {
+		t.Errorf("Неверные вызовы OnRetry: %v", retried)
+	}
+
+	var out strings.Builder
+	metrics.WriteTo(&out)
+	for _, want := range []string{
+		`mailslurp_client_retries_total{method="GetInboxes"} 2`,
+		`mailslurp_client_retries_total{method="SendEmail"} 1`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("В метриках нет строки %q:\n%s", want, out.String())
+		}
+	}
+}
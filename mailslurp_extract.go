@@ -0,0 +1,88 @@
+package mailslurp
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ErrNothingExtracted возвращается, если в письме не найдено искомое значение
+var ErrNothingExtracted = errors.New("значение не найдено в письме")
+
+// linkPattern находит http(s)-ссылки в тексте
+var linkPattern = regexp.MustCompile(`https?://[^\s"'<>]+`)
+
+// defaultCodePattern находит код подтверждения длиной от 4 до 8 цифр
+var defaultCodePattern = codePattern(`\d{4,8}`)
+
+// codePattern находит отдельно стоящие цифры digits. Цифры из HTML-сущностей
+// (&#123;) и цветов (#123456) не подходят.
+func codePattern(digits string) *regexp.Regexp {
+	return regexp.MustCompile(`(?:^|[^0-9A-Za-z#&;])(` + digits + `)(?:$|[^0-9A-Za-z])`)
+}
+
+// ExtractCode ищет числовой код подтверждения заданной длины в тексте письма
+// (HTML преобразуется через TextBody, поэтому адреса картинок и стилей не
+// учитываются), а затем в теме. Если length <= 0, ищется код длиной от 4 до 8 цифр.
+func ExtractCode(email *Email, length int) (string, error) {
+	pattern := defaultCodePattern
+	if length > 0 {
+		pattern = codePattern(fmt.Sprintf(`\d{%d}`, length))
+	}
+
+	for _, text := range []string{email.TextBody(), email.Subject} {
+		if m := pattern.FindStringSubmatch(text); m != nil {
+			return m[1], nil
+		}
+	}
+	return "", fmt.Errorf("%w: код подтверждения", ErrNothingExtracted)
+}
+
+// ExtractLink ищет в письме первую ссылку: в HTML письме - среди ссылок
+// <a href> (стили, картинки и пиксели отслеживания пропускаются), в текстовом
+// письме и в теме - среди найденных в тексте адресов. Если host не пустой,
+// подходят только ссылки на этот домен или его поддомены.
+func ExtractLink(email *Email, host string) (string, error) {
+	for _, link := range emailLinks(email) {
+		u, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		if host == "" || matchesHost(u.Hostname(), host) {
+			return link, nil
+		}
+	}
+	if host != "" {
+		return "", fmt.Errorf("%w: ссылка на %s", ErrNothingExtracted, host)
+	}
+	return "", fmt.Errorf("%w: ссылка", ErrNothingExtracted)
+}
+
+// emailLinks возвращает ссылки тела письма, а затем темы
+func emailLinks(email *Email) []string {
+	var links []string
+	if htmlBodyPattern.MatchString(email.Body) {
+		links = htmlLinks(email.Body)
+	} else {
+		links = textLinks(email.Body)
+	}
+	return append(links, textLinks(email.Subject)...)
+}
+
+// textLinks находит http(s)-ссылки в обычном тексте
+func textLinks(text string) []string {
+	var links []string
+	for _, raw := range linkPattern.FindAllString(text, -1) {
+		links = append(links, html.UnescapeString(strings.TrimRight(raw, ".,;:!?)]}")))
+	}
+	return links
+}
+
+func matchesHost(hostname, host string) bool {
+	hostname = strings.ToLower(hostname)
+	host = strings.ToLower(host)
+	return hostname == host || strings.HasSuffix(hostname, "."+host)
+}
@@ -0,0 +1,425 @@
+package mailslurp_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "mailslurp-example"
+)
+
+// fakeMailSlurpServer представляет собой локальный HTTP сервер, повторяющий
+// поведение API MailSlurp, который нужен для тестов DefaultMailSlurpClient
+type fakeMailSlurpServer struct {
+	*httptest.Server
+	apiKey string
+
+	mu      sync.Mutex
+	inboxes []map[string]interface{}
+	domains []map[string]interface{}
+	emails  map[string][]map[string]interface{}
+	// attachments - содержимое вложений по ID
+	attachments map[string][]byte
+	requests    []*http.Request
+	failures    []fakeFailure
+	nextID      int
+}
+
+// fakeFailure описывает ошибку, которую сервер вернет на один из следующих запросов
+type fakeFailure struct {
+	status    int
+	errorCode string
+}
+
+// newFakeMailSlurpServer запускает фейковый сервер и останавливает его по завершении теста
+func newFakeMailSlurpServer(t *testing.T) *fakeMailSlurpServer {
+	t.Helper()
+	f := &fakeMailSlurpServer{
+		apiKey:      "fake-api-key",
+		emails:      map[string][]map[string]interface{}{},
+		attachments: map[string][]byte{},
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// client создает DefaultMailSlurpClient, направленный на фейковый сервер
+func (f *fakeMailSlurpServer) client() MailSlurpClient {
+	return NewMailSlurpClientFromProfile(&Profile{APIKey: f.apiKey, BaseURL: f.URL})
+}
+
+// lastRequest возвращает последний запрос, принятый сервером
+func (f *fakeMailSlurpServer) lastRequest() *http.Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.requests) == 0 {
+		return nil
+	}
+	return f.requests[len(f.requests)-1]
+}
+
+// deliver помещает входящее письмо в ящик
+func (f *fakeMailSlurpServer) deliver(inboxID, from, subject, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deliverLocked(inboxID, from, []string{f.addressLocked(inboxID)}, subject, body)
+}
+
+// attach добавляет вложение к последнему письму ящика
+func (f *fakeMailSlurpServer) attach(inboxID, attachmentID string, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	emails := f.emails[inboxID]
+	email := emails[len(emails)-1]
+	attachments, _ := email["attachments"].([]string)
+	email["attachments"] = append(attachments, attachmentID)
+	f.attachments[attachmentID] = data
+}
+
+// failNext заставляет сервер ответить ошибкой на следующий запрос.
+// Несколько вызовов подряд ставят ошибки в очередь.
+func (f *fakeMailSlurpServer) failNext(status int, errorCode string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = append(f.failures, fakeFailure{status: status, errorCode: errorCode})
+}
+
+// requestCount возвращает количество запросов, принятых сервером
+func (f *fakeMailSlurpServer) requestCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.requests)
+}
+
+func (f *fakeMailSlurpServer) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests = append(f.requests, r)
+	var failure *fakeFailure
+	if len(f.failures) > 0 {
+		failure = &f.failures[0]
+		f.failures = f.failures[1:]
+	}
+	f.mu.Unlock()
+
+	if failure != nil {
+		writeFakeError(w, failure.status, failure.errorCode, "ошибка, заданная в тесте")
+		return
+	}
+
+	if r.Header.Get("x-api-key") != f.apiKey {
+		writeFakeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "неверный API ключ")
+		return
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+	switch {
+	case path == "inboxes" && r.Method == http.MethodPost:
+		f.createInbox(w, r.URL.Query())
+	case path == "inboxes" && r.Method == http.MethodGet:
+		f.mu.Lock()
+		writeFakeJSON(w, http.StatusOK, f.inboxes)
+		f.mu.Unlock()
+	case len(parts) == 2 && parts[0] == "inboxes" && r.Method == http.MethodDelete:
+		f.deleteInbox(w, parts[1])
+	case len(parts) == 2 && parts[0] == "inboxes" && r.Method == http.MethodPost:
+		f.sendEmail(w, r, parts[1])
+	case len(parts) == 3 && parts[0] == "inboxes" && parts[2] == "deleteAllInboxEmails" && r.Method == http.MethodDelete:
+		f.mu.Lock()
+		if f.addressLocked(parts[1]) == "" {
+			writeFakeError(w, http.StatusNotFound, "NOT_FOUND", "ящик "+parts[1]+" не найден")
+		} else {
+			delete(f.emails, parts[1])
+			w.WriteHeader(http.StatusNoContent)
+		}
+		f.mu.Unlock()
+	case path == "emails" && r.Method == http.MethodGet:
+		f.mu.Lock()
+		if inboxID := r.URL.Query().Get("inboxId"); f.addressLocked(inboxID) == "" {
+			writeFakeError(w, http.StatusNotFound, "NOT_FOUND", "ящик "+inboxID+" не найден")
+		} else {
+			writeFakeJSON(w, http.StatusOK, map[string]interface{}{"content": f.emails[inboxID]})
+		}
+		f.mu.Unlock()
+	case len(parts) == 4 && parts[0] == "emails" && parts[2] == "attachments" && r.Method == http.MethodGet:
+		f.mu.Lock()
+		if data, ok := f.attachments[parts[3]]; ok {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write(data)
+		} else {
+			writeFakeError(w, http.StatusNotFound, "NOT_FOUND", "вложение "+parts[3]+" не найдено")
+		}
+		f.mu.Unlock()
+	case path == "waitForLatestEmail" && r.Method == http.MethodGet:
+		f.waitForLatestEmail(w, r.URL.Query())
+	case path == "user/info" && r.Method == http.MethodGet:
+		writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+			"id":               "user-1",
+			"emailAddress":     "owner@example.com",
+			"accountState":     "ACTIVE",
+			"subscriptionType": "PRO_MONTHLY",
+			"createdAt":        "2024-03-29T00:00:00Z",
+		})
+	case path == "inboxes/count" && r.Method == http.MethodGet:
+		f.mu.Lock()
+		writeFakeJSON(w, http.StatusOK, map[string]int{"totalElements": len(f.inboxes)})
+		f.mu.Unlock()
+	case path == "emails/count" && r.Method == http.MethodGet:
+		f.mu.Lock()
+		total := 0
+		for _, emails := range f.emails {
+			total += len(emails)
+		}
+		writeFakeJSON(w, http.StatusOK, map[string]int{"totalElements": total})
+		f.mu.Unlock()
+	case path == "domains" && r.Method == http.MethodPost:
+		f.createDomain(w, r)
+	case path == "domains" && r.Method == http.MethodGet:
+		f.mu.Lock()
+		writeFakeJSON(w, http.StatusOK, f.domains)
+		f.mu.Unlock()
+	case len(parts) == 2 && parts[0] == "domains" && r.Method == http.MethodGet:
+		f.mu.Lock()
+		if domain := f.domainLocked(parts[1]); domain != nil {
+			writeFakeJSON(w, http.StatusOK, domain)
+		} else {
+			writeFakeError(w, http.StatusNotFound, "NOT_FOUND", "домен "+parts[1]+" не найден")
+		}
+		f.mu.Unlock()
+	case len(parts) == 2 && parts[0] == "domains" && r.Method == http.MethodDelete:
+		f.deleteDomain(w, parts[1])
+	default:
+		writeFakeError(w, http.StatusNotFound, "NOT_FOUND", "неизвестный метод "+r.Method+" "+r.URL.Path)
+	}
+}
+
+func (f *fakeMailSlurpServer) createInbox(w http.ResponseWriter, q url.Values) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	domainName := q.Get("domainName")
+	if domainID := q.Get("domainId"); domainID != "" {
+		domain := f.domainLocked(domainID)
+		if domain == nil || domain["isVerified"] != true {
+			writeFakeError(w, http.StatusBadRequest, "DOMAIN_NOT_VERIFIED", "домен "+domainID+" не подтвержден")
+			return
+		}
+		domainName = domain["domain"].(string)
+	}
+	if domainName == "" {
+		domainName = "mailslurp.test"
+	}
+
+	f.nextID++
+	id := fmt.Sprintf("inbox-%d", f.nextID)
+	address := q.Get("emailAddress")
+	if address == "" {
+		address = fmt.Sprintf("%s@%s", id, domainName)
+	}
+
+	inbox := map[string]interface{}{
+		"id":           id,
+		"emailAddress": address,
+		"createdAt":    time.Now().UTC().Format(time.RFC3339),
+		"name":         q.Get("name"),
+		"description":  q.Get("description"),
+		"tags":         q["tags"],
+		"favourite":    q.Get("favourite") == "true",
+		"inboxType":    q.Get("inboxType"),
+		"domainId":     q.Get("domainId"),
+	}
+	if inbox["inboxType"] == "" {
+		inbox["inboxType"] = string(InboxTypeHTTP)
+	}
+	if expiresAt := q.Get("expiresAt"); expiresAt != "" {
+		inbox["expiresAt"] = expiresAt
+	} else if expiresIn, err := strconv.ParseInt(q.Get("expiresIn"), 10, 64); err == nil {
+		inbox["expiresAt"] = time.Now().Add(time.Duration(expiresIn) * time.Millisecond).UTC().Format(time.RFC3339)
+	}
+
+	f.inboxes = append(f.inboxes, inbox)
+	writeFakeJSON(w, http.StatusCreated, inbox)
+}
+
+func (f *fakeMailSlurpServer) deleteInbox(w http.ResponseWriter, id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, inbox := range f.inboxes {
+		if inbox["id"] == id {
+			f.inboxes = append(f.inboxes[:i], f.inboxes[i+1:]...)
+			delete(f.emails, id)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeFakeError(w, http.StatusNotFound, "NOT_FOUND", "ящик "+id+" не найден")
+}
+
+func (f *fakeMailSlurpServer) sendEmail(w http.ResponseWriter, r *http.Request, fromInboxID string) {
+	var payload struct {
+		To      []string `json:"to"`
+		Subject string   `json:"subject"`
+		Body    string   `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeFakeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	from := f.addressLocked(fromInboxID)
+	if from == "" {
+		writeFakeError(w, http.StatusNotFound, "NOT_FOUND", "ящик "+fromInboxID+" не найден")
+		return
+	}
+	for _, to := range payload.To {
+		for _, inbox := range f.inboxes {
+			if strings.EqualFold(inbox["emailAddress"].(string), to) {
+				f.deliverLocked(inbox["id"].(string), from, payload.To, payload.Subject, payload.Body)
+			}
+		}
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (f *fakeMailSlurpServer) waitForLatestEmail(w http.ResponseWriter, q url.Values) {
+	inboxID := q.Get("inboxId")
+	timeoutMs, _ := strconv.Atoi(q.Get("timeout"))
+	deadline := time.Now().Add(time.Duration(timeoutMs) * time.Millisecond)
+	unreadOnly := q.Get("unreadOnly") == "true"
+
+	for {
+		f.mu.Lock()
+		if f.addressLocked(inboxID) == "" {
+			writeFakeError(w, http.StatusNotFound, "NOT_FOUND", "ящик "+inboxID+" не найден")
+			f.mu.Unlock()
+			return
+		}
+		emails := f.emails[inboxID]
+		for i := len(emails) - 1; i >= 0; i-- {
+			if !unreadOnly || emails[i]["read"] != true {
+				emails[i]["read"] = true
+				writeFakeJSON(w, http.StatusOK, emails[i])
+				f.mu.Unlock()
+				return
+			}
+		}
+		f.mu.Unlock()
+
+		if time.Now().After(deadline) {
+			writeFakeError(w, http.StatusRequestTimeout, "TIMEOUT", "письмо не получено")
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (f *fakeMailSlurpServer) createDomain(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Domain string `json:"domain"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Domain == "" {
+		writeFakeError(w, http.StatusBadRequest, "BAD_REQUEST", "не указан домен")
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nextID++
+	token := fmt.Sprintf("mailslurp-verification-%d", f.nextID)
+	domain := map[string]interface{}{
+		"id":                fmt.Sprintf("domain-%d", f.nextID),
+		"domain":            payload.Domain,
+		"isVerified":        false,
+		"verificationToken": token,
+		"createdAt":         time.Now().UTC().Format(time.RFC3339),
+		"domainNameRecords": []DNSRecord{
+			{Name: payload.Domain, RecordType: "TXT", Value: token, TTL: 300, Required: true},
+			{Name: payload.Domain, RecordType: "MX", Value: "10 mx.mailslurp.test", TTL: 300, Required: true},
+			{Name: "mailslurp._domainkey." + payload.Domain, RecordType: "CNAME", Value: "dkim.mailslurp.test", TTL: 300, Required: false},
+		},
+	}
+	f.domains = append(f.domains, domain)
+	writeFakeJSON(w, http.StatusCreated, domain)
+}
+
+func (f *fakeMailSlurpServer) deleteDomain(w http.ResponseWriter, id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, domain := range f.domains {
+		if domain["id"] == id {
+			f.domains = append(f.domains[:i], f.domains[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeFakeError(w, http.StatusNotFound, "NOT_FOUND", "домен "+id+" не найден")
+}
+
+// verifyDomain отмечает домен подтвержденным, как будто DNS записи добавлены
+func (f *fakeMailSlurpServer) verifyDomain(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if domain := f.domainLocked(id); domain != nil {
+		domain["isVerified"] = true
+	}
+}
+
+func (f *fakeMailSlurpServer) domainLocked(id string) map[string]interface{} {
+	for _, domain := range f.domains {
+		if domain["id"] == id {
+			return domain
+		}
+	}
+	return nil
+}
+
+func (f *fakeMailSlurpServer) addressLocked(inboxID string) string {
+	for _, inbox := range f.inboxes {
+		if inbox["id"] == inboxID {
+			return inbox["emailAddress"].(string)
+		}
+	}
+	return ""
+}
+
+func (f *fakeMailSlurpServer) deliverLocked(inboxID, from string, to []string, subject, body string) {
+	f.nextID++
+	f.emails[inboxID] = append(f.emails[inboxID], map[string]interface{}{
+		"id":        fmt.Sprintf("email-%d", f.nextID),
+		"inboxId":   inboxID,
+		"subject":   subject,
+		"body":      body,
+		"from":      from,
+		"to":        to,
+		"createdAt": time.Now().UTC().Format(time.RFC3339),
+		"read":      false,
+	})
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeFakeError(w http.ResponseWriter, status int, code, message string) {
+	writeFakeJSON(w, status, ErrorResponse{
+		Status:    http.StatusText(status),
+		Message:   message,
+		ErrorCode: code,
+	})
+}
@@ -0,0 +1,535 @@
+package mailslurp
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// htmlBodyPattern определяет, что тело письма является HTML
+var htmlBodyPattern = regexp.MustCompile(`(?i)<(?:html|body|div|p|br|table|td|a|span|b|strong|img|h[1-6])[\s/>]`)
+
+// Элементы, содержимое которых не выводится
+var htmlSkippedElements = map[string]bool{
+	"script": true, "style": true, "head": true, "title": true, "noscript": true, "template": true,
+}
+
+// Блочные элементы: перед и после них начинается новая строка.
+// Значение - количество переводов строки (2 - отдельный абзац).
+var htmlBlockElements = map[string]int{
+	"p": 2, "h1": 2, "h2": 2, "h3": 2, "h4": 2, "h5": 2, "h6": 2,
+	"ul": 2, "ol": 2, "blockquote": 2, "pre": 2, "table": 2, "hr": 2,
+	"div": 1, "section": 1, "article": 1, "header": 1, "footer": 1, "main": 1,
+	"nav": 1, "aside": 1, "center": 1, "form": 1, "dl": 1, "dt": 1, "dd": 1,
+	"address": 1, "figure": 1, "figcaption": 1, "li": 1, "tr": 1,
+}
+
+// TextBody возвращает тело письма в виде читаемого текста: HTML
+// преобразуется через HTMLToText, обычный текст возвращается как есть
+func (e *Email) TextBody() string {
+	if !htmlBodyPattern.MatchString(e.Body) {
+		return e.Body
+	}
+	return HTMLToText(e.Body)
+}
+
+// HTMLToText преобразует HTML в читаемый текст: убирает style и script,
+// схлопывает пробелы, выводит таблицы по строкам, а адреса ссылок -
+// сносками [1] в конце текста
+func HTMLToText(s string) string {
+	r := renderHTML(s)
+	text := r.stack[0].block.String()
+	if len(r.footnotes) > 0 {
+		var b strings.Builder
+		b.WriteString(text)
+		b.WriteString("\n\n")
+		for i, link := range r.footnotes {
+			fmt.Fprintf(&b, "[%d] %s\n", i+1, link)
+		}
+		text = strings.TrimRight(b.String(), "\n")
+	}
+	return text
+}
+
+// htmlLinks возвращает адреса http(s) ссылок <a href> в порядке документа.
+// Адреса стилей, картинок и других ресурсов в результат не попадают.
+func htmlLinks(s string) []string {
+	return renderHTML(s).anchors
+}
+
+// renderHTML обходит HTML и закрывает все незакрытые таблицы и ссылки
+func renderHTML(s string) *htmlRenderer {
+	r := &htmlRenderer{links: map[string]int{}}
+	r.push(false, "")
+	r.render(s)
+	for len(r.tables) > 0 {
+		r.closeTable()
+	}
+	for len(r.stack) > 1 {
+		if r.stack[len(r.stack)-1].link {
+			r.closeLink()
+		} else {
+			r.cur().writeText(r.pop().block.String())
+		}
+	}
+	return r
+}
+
+// textBlock накапливает текст, схлопывая пробелы и пустые строки
+type textBlock struct {
+	b        strings.Builder
+	space    bool // нужен пробел перед следующим словом
+	newlines int  // сколько переводов строки уже стоит в конце
+	started  bool
+}
+
+// writeText добавляет текст, заменяя последовательности пробелов одним
+func (t *textBlock) writeText(s string) {
+	for _, c := range s {
+		if unicode.IsSpace(c) {
+			t.space = true
+			continue
+		}
+		if t.space && t.started && t.newlines == 0 {
+			t.b.WriteByte(' ')
+		}
+		t.space = false
+		t.b.WriteRune(c)
+		t.started = true
+		t.newlines = 0
+	}
+}
+
+// writeRaw добавляет текст без изменений (для pre и таблиц)
+func (t *textBlock) writeRaw(s string) {
+	if s == "" {
+		return
+	}
+	if t.space && t.started && t.newlines == 0 {
+		t.b.WriteByte(' ')
+	}
+	t.b.WriteString(s)
+	t.space = false
+	t.started = true
+	t.newlines = len(s) - len(strings.TrimRight(s, "\n"))
+}
+
+// breakLine завершает строку так, чтобы в конце было не меньше n переводов строки
+func (t *textBlock) breakLine(n int) {
+	t.space = false
+	if !t.started {
+		return
+	}
+	for t.newlines < n {
+		t.b.WriteByte('\n')
+		t.newlines++
+	}
+}
+
+// String возвращает текст без пробелов в конце строк
+func (t *textBlock) String() string {
+	lines := strings.Split(strings.TrimSpace(t.b.String()), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// htmlTable - таблица, строки которой накапливаются до закрывающего тега
+type htmlTable struct {
+	rows   [][]string
+	inRow  bool
+	inCell bool
+}
+
+// htmlFrame - блок текста на стеке: документ, ячейка таблицы или ссылка
+type htmlFrame struct {
+	block *textBlock
+	link  bool
+	href  string
+}
+
+// htmlRenderer обходит HTML и строит текст. Содержимое ячеек таблиц и
+// ссылок собирается в отдельные блоки на стеке.
+type htmlRenderer struct {
+	stack     []htmlFrame
+	tables    []*htmlTable
+	links     map[string]int
+	footnotes []string
+	anchors   []string // адреса http(s) ссылок в порядке документа
+	pre       int
+}
+
+func (r *htmlRenderer) cur() *textBlock {
+	return r.stack[len(r.stack)-1].block
+}
+
+func (r *htmlRenderer) push(link bool, href string) {
+	r.stack = append(r.stack, htmlFrame{block: &textBlock{}, link: link, href: href})
+}
+
+func (r *htmlRenderer) pop() htmlFrame {
+	frame := r.stack[len(r.stack)-1]
+	r.stack = r.stack[:len(r.stack)-1]
+	return frame
+}
+
+func (r *htmlRenderer) render(s string) {
+	for len(s) > 0 {
+		lt := strings.IndexByte(s, '<')
+		if lt < 0 {
+			r.text(s)
+			return
+		}
+		if lt > 0 {
+			r.text(s[:lt])
+			s = s[lt:]
+		}
+
+		switch {
+		case strings.HasPrefix(s, "<!--"):
+			end := strings.Index(s, "-->")
+			if end < 0 {
+				return
+			}
+			s = s[end+3:]
+			continue
+		case strings.HasPrefix(s, "<!") || strings.HasPrefix(s, "<?"):
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return
+			}
+			s = s[end+1:]
+			continue
+		}
+
+		name, attrs, closing, rest, ok := parseHTMLTag(s)
+		if !ok {
+			// Незакрытый тег тянется до конца строки: выводим остаток как
+			// текст, а не ищем '>' заново с каждого следующего символа
+			if rest == "" {
+				r.text(s)
+				return
+			}
+			r.text("<")
+			s = s[1:]
+			continue
+		}
+		s = rest
+
+		if !closing && htmlSkippedElements[name] {
+			s = skipHTMLElement(s, name)
+			continue
+		}
+		if closing {
+			r.endTag(name)
+		} else {
+			r.startTag(name, attrs)
+		}
+	}
+}
+
+func (r *htmlRenderer) text(s string) {
+	s = html.UnescapeString(s)
+	if r.pre > 0 {
+		r.cur().writeRaw(s)
+		return
+	}
+	r.cur().writeText(s)
+}
+
+func (r *htmlRenderer) startTag(name string, attrs map[string]string) {
+	switch name {
+	case "br":
+		r.cur().breakLine(1)
+		return
+	case "img":
+		if alt := strings.TrimSpace(attrs["alt"]); alt != "" {
+			r.cur().writeText(" " + alt + " ")
+		}
+		return
+	case "a":
+		// Ссылки не вкладываются: новая ссылка закрывает незакрытую
+		if r.stack[len(r.stack)-1].link {
+			r.closeLink()
+		}
+		r.push(true, attrs["href"])
+		return
+	case "table":
+		r.cur().breakLine(htmlBlockElements[name])
+		r.tables = append(r.tables, &htmlTable{})
+		return
+	case "tr":
+		if t := r.table(); t != nil {
+			r.closeCell(t)
+			t.rows = append(t.rows, nil)
+			t.inRow = true
+			return
+		}
+	case "td", "th":
+		if t := r.table(); t != nil {
+			r.closeCell(t)
+			if !t.inRow {
+				t.rows = append(t.rows, nil)
+				t.inRow = true
+			}
+			t.inCell = true
+			r.push(false, "")
+			return
+		}
+	case "pre":
+		r.pre++
+	case "hr":
+		r.cur().breakLine(1)
+		r.cur().writeRaw("----")
+	}
+
+	if n, ok := htmlBlockElements[name]; ok {
+		r.cur().breakLine(n)
+	}
+	if name == "li" {
+		r.cur().writeRaw("- ")
+	}
+}
+
+func (r *htmlRenderer) endTag(name string) {
+	switch name {
+	case "a":
+		r.closeLink()
+		return
+	case "table":
+		r.closeTable()
+		return
+	case "tr":
+		if t := r.table(); t != nil {
+			r.closeCell(t)
+			t.inRow = false
+			return
+		}
+	case "td", "th":
+		if t := r.table(); t != nil {
+			r.closeCell(t)
+			return
+		}
+	case "pre":
+		if r.pre > 0 {
+			r.pre--
+		}
+	}
+
+	if n, ok := htmlBlockElements[name]; ok {
+		r.cur().breakLine(n)
+	}
+}
+
+// table возвращает последнюю открытую таблицу
+func (r *htmlRenderer) table() *htmlTable {
+	if len(r.tables) == 0 {
+		return nil
+	}
+	return r.tables[len(r.tables)-1]
+}
+
+// closeLink выводит текст ссылки и добавляет сноску с ее адресом.
+// Закрывающий тег без открытой ссылки в текущем блоке игнорируется.
+func (r *htmlRenderer) closeLink() {
+	if !r.stack[len(r.stack)-1].link {
+		return
+	}
+	frame := r.pop()
+	href := strings.TrimSpace(frame.href)
+	text := frame.block.String()
+
+	r.cur().writeText(text)
+	if lower := strings.ToLower(href); strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		r.anchors = append(r.anchors, href)
+	}
+	if isFootnoteLink(href) && href != text && strings.TrimPrefix(href, "mailto:") != text {
+		n, ok := r.links[href]
+		if !ok {
+			r.footnotes = append(r.footnotes, href)
+			n = len(r.footnotes)
+			r.links[href] = n
+		}
+		r.cur().writeText(fmt.Sprintf(" [%d]", n))
+	}
+	// Пробел в конце текста ссылки отделяет ее от следующего слова
+	if frame.block.space {
+		r.cur().space = true
+	}
+}
+
+func isFootnoteLink(href string) bool {
+	lower := strings.ToLower(href)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "mailto:")
+}
+
+// closeCell завершает открытую ячейку таблицы t вместе с незакрытыми
+// ссылками внутри нее
+func (r *htmlRenderer) closeCell(t *htmlTable) {
+	if !t.inCell {
+		return
+	}
+	for r.stack[len(r.stack)-1].link {
+		r.closeLink()
+	}
+	cell := r.pop().block.String()
+	t.rows[len(t.rows)-1] = append(t.rows[len(t.rows)-1], cell)
+	t.inCell = false
+}
+
+// closeTable выводит таблицу: таблицы с однострочными ячейками - колонками
+// через " | ", таблицы верстки (с абзацами в ячейках) - ячейками по порядку
+func (r *htmlRenderer) closeTable() {
+	t := r.table()
+	if t == nil {
+		return
+	}
+	r.closeCell(t)
+	r.tables = r.tables[:len(r.tables)-1]
+
+	var rows [][]string
+	layout := false
+	cols := 0
+	for _, row := range t.rows {
+		var cells []string
+		for _, cell := range row {
+			if cell != "" {
+				cells = append(cells, cell)
+			}
+			layout = layout || strings.Contains(cell, "\n")
+		}
+		if len(cells) > 0 {
+			rows = append(rows, row)
+			cols = max(cols, len(row))
+		}
+	}
+
+	out := r.cur()
+	out.breakLine(2)
+	if layout || cols < 2 {
+		for _, row := range rows {
+			for _, cell := range row {
+				if cell != "" {
+					out.breakLine(1)
+					out.writeRaw(cell)
+				}
+			}
+		}
+		out.breakLine(2)
+		return
+	}
+
+	widths := make([]int, cols)
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	for _, row := range rows {
+		var line strings.Builder
+		for i := 0; i < cols; i++ {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			if i > 0 {
+				line.WriteString(" | ")
+			}
+			line.WriteString(cell)
+			if i < cols-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+			}
+		}
+		out.breakLine(1)
+		out.writeRaw(strings.TrimRight(line.String(), " "))
+	}
+	out.breakLine(2)
+}
+
+// parseHTMLTag разбирает тег в начале s. Возвращает имя в нижнем регистре,
+// атрибуты, признак закрывающего тега и остаток строки после тега. Если
+// после имени тега нет '>', возвращается ok == false и пустой остаток.
+func parseHTMLTag(s string) (name string, attrs map[string]string, closing bool, rest string, ok bool) {
+	i := 1
+	if i < len(s) && s[i] == '/' {
+		closing = true
+		i++
+	}
+	start := i
+	for i < len(s) && (isASCIILetter(s[i]) || (i > start && s[i] >= '0' && s[i] <= '9')) {
+		i++
+	}
+	if i == start {
+		return "", nil, false, s, false
+	}
+	name = strings.ToLower(s[start:i])
+
+	// Ищем конец тега, пропуская '>' внутри кавычек
+	end := -1
+	var quote byte
+	for j := i; j < len(s); j++ {
+		switch c := s[j]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			end = j
+		}
+		if end >= 0 {
+			break
+		}
+	}
+	if end < 0 {
+		return "", nil, false, "", false
+	}
+	return name, parseHTMLAttrs(s[i:end]), closing, s[end+1:], true
+}
+
+// htmlAttrPattern находит атрибуты вида name="value", name='value' и name=value
+var htmlAttrPattern = regexp.MustCompile(`([a-zA-Z_:][-a-zA-Z0-9_:.]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+func parseHTMLAttrs(s string) map[string]string {
+	attrs := map[string]string{}
+	for _, m := range htmlAttrPattern.FindAllStringSubmatch(s, -1) {
+		attrs[strings.ToLower(m[1])] = html.UnescapeString(m[2] + m[3] + m[4])
+	}
+	return attrs
+}
+
+// skipHTMLElement пропускает содержимое элемента name до закрывающего тега
+func skipHTMLElement(s, name string) string {
+	end := indexClosingTag(s, name)
+	if end < 0 {
+		return ""
+	}
+	s = s[end:]
+	if gt := strings.IndexByte(s, '>'); gt >= 0 {
+		return s[gt+1:]
+	}
+	return ""
+}
+
+// indexClosingTag ищет "</name" без учета регистра, не копируя s целиком
+func indexClosingTag(s, name string) int {
+	for i := 0; ; i += 2 {
+		j := strings.Index(s[i:], "</")
+		if j < 0 {
+			return -1
+		}
+		i += j
+		if end := i + 2 + len(name); end <= len(s) && strings.EqualFold(s[i+2:end], name) {
+			return i
+		}
+	}
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
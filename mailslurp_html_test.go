@@ -0,0 +1,105 @@
+package mailslurp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const marketingHTML = `<!DOCTYPE html>
+<html><head><title>Рассылка</title>
+<style>body { color: #123456; } .btn { padding: 10px }</style>
+</head>
+<body>
+<!-- прехедер -->
+<table width="100%"><tr><td>
+  <h1>Добро   пожаловать,
+     Иван!</h1>
+  <p>Подтвердите адрес,
+  нажав&nbsp;на <a href="https://app.example.com/confirm?token=abc&amp;u=1" class="btn">кнопку</a>.</p>
+  <p>Или откройте <a href="https://app.example.com/confirm?token=abc&amp;u=1">эту ссылку</a>.</p>
+  <script>track("open")</script>
+  <table>
+    <tr><th>Тариф</th><th>Цена</th></tr>
+    <tr><td>Базовый</td><td>0 ₽</td></tr>
+    <tr><td>Профессиональный</td><td>990 ₽</td></tr>
+  </table>
+  <ul><li>Первый пункт</li><li>Второй <b>пункт</b></li></ul>
+  <p>Почта: <a href="mailto:help@example.com">help@example.com</a><br>
+  <a href="https://example.com/unsubscribe">Отписаться</a></p>
+</td></tr></table>
+</body></html>`
+
+// TestHTMLToText проверяет преобразование HTML письма в текст
+func TestHTMLToText(t *testing.T) {
+	want := `Добро пожаловать, Иван!
+
+Подтвердите адрес, нажав на кнопку [1].
+
+Или откройте эту ссылку [1].
+
+Тариф            | Цена
+Базовый          | 0 ₽
+Профессиональный | 990 ₽
+
+- Первый пункт
+- Второй пункт
+
+Почта: help@example.com
+Отписаться [2]
+
+[1] https://app.example.com/confirm?token=abc&u=1
+[2] https://example.com/unsubscribe`
+
+	got := HTMLToText(marketingHTML)
+	if got != want {
+		t.Errorf("Неверный текст:\n%s\nожидалось:\n%s", got, want)
+	}
+	for _, junk := range []string{"color", "track", "Рассылка", "прехедер"} {
+		if strings.Contains(got, junk) {
+			t.Errorf("Текст не должен содержать %q", junk)
+		}
+	}
+}
+
+// TestEmailTextBody проверяет, что обычный текст возвращается без изменений
+func TestEmailTextBody(t *testing.T) {
+	plain := &Email{Body: "Код: 1 < 2 и  два пробела\n\nабзац"}
+	if got := plain.TextBody(); got != plain.Body {
+		t.Errorf("Обычный текст изменен: %q", got)
+	}
+
+	html := &Email{Body: `<p>Код&nbsp;<b>482913</b></p><pre>  строка 1
+  строка 2</pre>`}
+	if got := html.TextBody(); got != "Код 482913\n\n  строка 1\n  строка 2" {
+		t.Errorf("Неверный текст: %q", got)
+	}
+	if !BodyContains("Код 482913")(html) || !BodyContains("<b>482913</b>")(html) {
+		t.Error("BodyContains должен искать и в тексте, и в исходном HTML")
+	}
+}
+
+// TestHTMLToTextMalformed проверяет незакрытые теги и вложенные ссылки, в
+// том числе на больших входных данных из чужих писем
+func TestHTMLToTextMalformed(t *testing.T) {
+	tests := []struct {
+		name, html, want string
+	}{
+		{"unterminated tag", `<p>Код 1234</p><a href="x`, "Код 1234\n\n<a href=\"x"},
+		{"nested links", `<a href="https://a.example">один <a href="https://b.example">два</a>`, "один [1] два [2]\n\n[1] https://a.example\n[2] https://b.example"},
+		{"uppercase script", `до<SCRIPT>x()</Script>после`, "допосле"},
+	}
+	for _, tt := range tests {
+		if got := HTMLToText(tt.html); got != tt.want {
+			t.Errorf("%s: получено %q, ожидалось %q", tt.name, got, tt.want)
+		}
+	}
+
+	start := time.Now()
+	HTMLToText(strings.Repeat("<a ", 50000))
+	HTMLToText(strings.Repeat(`<a href="https://example.com/x">x`, 10000))
+	HTMLToText(strings.Repeat("<script></script>", 20000))
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Преобразование враждебного HTML заняло %s", elapsed)
+	}
+}
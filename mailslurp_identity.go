@@ -0,0 +1,501 @@
+package mailslurp
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Наборы символов паролей и кодов, как в frontend/js/generator.js
+const (
+	passwordLowerChars   = "abcdefghijklmnopqrstuvwxyz"
+	passwordUpperChars   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	passwordDigitChars   = "0123456789"
+	passwordSpecialChars = "!@#$%^&*()_-+=<>?/[]{}|~"
+)
+
+// Параметры генератора по умолчанию
+const (
+	defaultPasswordLength   = 12
+	defaultCodeLength       = 6
+	uniqueLoginAttempts     = 100
+	defaultIdentityLifetime = 5 * time.Minute
+)
+
+var (
+	// ErrInvalidPasswordPolicy - политике паролей невозможно удовлетворить
+	ErrInvalidPasswordPolicy = errors.New("недопустимая политика паролей")
+	// ErrPasswordPolicy - пароль не соответствует политике
+	ErrPasswordPolicy = errors.New("пароль не соответствует политике")
+)
+
+// Locale - набор имен и фамилий для генерации данных пользователя
+type Locale struct {
+	Code        string
+	FemaleNames []string
+	MaleNames   []string
+	LastNames   []string
+	// FemaleLastName образует женскую форму фамилии; nil - фамилия не меняется
+	FemaleLastName func(lastName string) string
+}
+
+// LocaleEN - английские имена и фамилии из frontend/js/generator.js
+var LocaleEN = &Locale{
+	Code: "en",
+	FemaleNames: []string{
+		"Emma", "Olivia", "Ava", "Isabella", "Sophia", "Charlotte", "Mia", "Amelia",
+		"Harper", "Evelyn", "Abigail", "Emily", "Elizabeth", "Mila", "Ella", "Avery",
+		"Sofia", "Camila", "Aria", "Scarlett", "Victoria", "Madison", "Luna", "Grace",
+		"Chloe", "Penelope", "Layla", "Riley", "Zoey", "Nora", "Lily", "Eleanor",
+		"Hannah", "Lillian", "Addison", "Aubrey", "Ellie", "Stella", "Natalie", "Zoe",
+		"Leah", "Hazel", "Violet", "Aurora", "Savannah", "Audrey", "Brooklyn", "Bella",
+		"Claire", "Skylar", "Lucy", "Paisley", "Everly", "Anna", "Caroline", "Nova",
+		"Genesis", "Emilia", "Kennedy", "Samantha", "Maya", "Willow", "Kinsley", "Naomi",
+		"Aaliyah", "Elena", "Sarah", "Ariana", "Allison", "Gabriella", "Alice", "Madelyn",
+		"Cora", "Ruby", "Eva", "Serenity", "Autumn", "Adeline", "Hailey", "Gianna",
+		"Valentina", "Isla", "Eliana", "Quinn", "Nevaeh", "Ivy", "Sadie", "Piper",
+		"Lydia", "Alexa", "Josephine", "Emery", "Julia", "Delilah", "Arianna", "Vivian",
+		"Kaylee", "Sophie", "Brielle", "Madeline", "Donna", "Maria", "Jessica",
+	},
+	MaleNames: []string{
+		"Liam", "Noah", "William", "James", "Oliver", "Benjamin", "Elijah", "Lucas",
+		"Mason", "Logan", "Alexander", "Ethan", "Jacob", "Michael", "Daniel", "Henry",
+		"Jackson", "Sebastian", "Aiden", "Matthew", "Samuel", "David", "Joseph", "Carter",
+		"Owen", "Wyatt", "John", "Jack", "Luke", "Jayden", "Dylan", "Grayson", "Levi",
+		"Isaac", "Gabriel", "Julian", "Mateo", "Anthony", "Jaxon", "Lincoln", "Joshua",
+		"Christopher", "Andrew", "Theodore", "Caleb", "Ryan", "Asher", "Nathan", "Thomas",
+		"Leo", "Isaiah", "Charles", "Josiah", "Hudson", "Christian", "Hunter", "Connor",
+		"Eli", "Ezra", "Aaron", "Landon", "Adrian", "Jonathan", "Nolan", "Jeremiah",
+		"Easton", "Elias", "Colton", "Cameron", "Carson", "Robert", "Angel", "Maverick",
+		"Nicholas", "Dominic", "Jaxson", "Greyson", "Adam", "Ian", "Austin", "Santiago",
+		"Jordan", "Cooper", "Brayden", "Roman", "Evan", "Ezekiel", "Xavier", "Jose",
+		"Jace", "Jameson", "Leonardo", "Bryson", "Axel", "Everett", "Parker", "Kayden",
+		"Miles", "Sawyer", "Jason", "Antonio",
+	},
+	LastNames: []string{
+		"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis",
+		"Rodriguez", "Martinez", "Hernandez", "Lopez", "Gonzalez", "Wilson", "Anderson",
+		"Thomas", "Taylor", "Moore", "Jackson", "Martin", "Lee", "Perez", "Thompson",
+		"White", "Harris", "Sanchez", "Clark", "Ramirez", "Lewis", "Robinson", "Walker",
+		"Young", "Allen", "King", "Wright", "Scott", "Torres", "Nguyen", "Hill", "Flores",
+		"Green", "Adams", "Nelson", "Baker", "Hall", "Rivera", "Campbell", "Mitchell",
+		"Carter", "Roberts", "Gomez", "Phillips", "Evans", "Turner", "Diaz", "Parker",
+		"Cruz", "Edwards", "Collins", "Reyes", "Stewart", "Morris", "Morales", "Murphy",
+		"Cook", "Rogers", "Gutierrez", "Ortiz", "Morgan", "Cooper", "Peterson", "Bailey",
+		"Reed", "Kelly", "Howard", "Ramos", "Kim", "Cox", "Ward", "Richardson", "Watson",
+		"Brooks", "Chavez", "Wood", "James", "Bennett", "Gray", "Mendoza", "Ruiz", "Hughes",
+		"Price", "Alvarez", "Castillo", "Sanders", "Patel", "Myers", "Long", "Ross", "Foster",
+		"Jimenez", "Powell", "Jenkins", "Perry", "Russell", "Sullivan", "Bell", "Coleman",
+		"Butler", "Henderson", "Barnes", "Gonzales", "Fisher", "Vasquez", "Simmons", "Romero",
+	},
+}
+
+// LocaleRU - русские имена и фамилии; логины транслитерируются латиницей
+var LocaleRU = &Locale{
+	Code: "ru",
+	FemaleNames: []string{
+		"Анна", "Мария", "Елена", "Ольга", "Наталья", "Татьяна", "Ирина", "Екатерина",
+		"Светлана", "Юлия", "Анастасия", "Дарья", "Полина", "Алиса", "Ксения", "Виктория",
+		"Софья", "Александра", "Вероника", "Валерия", "Марина", "Людмила", "Галина", "Нина",
+		"Алёна", "Кристина", "Евгения", "Василиса", "Варвара", "Ульяна", "Милана", "Ева",
+	},
+	MaleNames: []string{
+		"Александр", "Дмитрий", "Максим", "Сергей", "Андрей", "Алексей", "Артём", "Илья",
+		"Кирилл", "Михаил", "Никита", "Матвей", "Роман", "Егор", "Иван", "Владимир",
+		"Николай", "Павел", "Денис", "Евгений", "Константин", "Тимофей", "Фёдор", "Григорий",
+		"Олег", "Юрий", "Виктор", "Степан", "Лев", "Марк", "Глеб", "Ярослав",
+	},
+	LastNames: []string{
+		"Иванов", "Смирнов", "Кузнецов", "Попов", "Васильев", "Петров", "Соколов", "Михайлов",
+		"Новиков", "Фёдоров", "Морозов", "Волков", "Алексеев", "Лебедев", "Семёнов", "Егоров",
+		"Павлов", "Козлов", "Степанов", "Николаев", "Орлов", "Андреев", "Макаров", "Никитин",
+		"Захаров", "Зайцев", "Соловьёв", "Борисов", "Яковлев", "Григорьев", "Романов", "Воробьёв",
+		"Сергеев", "Кузьмин", "Фролов", "Александров", "Дмитриев", "Королёв", "Гусев", "Киселёв",
+		"Ильин", "Максимов", "Поляков", "Сорокин", "Виноградов", "Ковалёв", "Белов", "Медведев",
+		"Антонов", "Тарасов", "Жуков", "Баранов", "Филиппов", "Комаров", "Давыдов", "Беляев",
+		"Герасимов", "Богданов", "Осипов", "Сидоров", "Матвеев", "Титов", "Марков", "Миронов",
+		"Крылов", "Куликов", "Карпов", "Власов", "Мельников", "Денисов", "Гаврилов", "Тихонов",
+		"Казаков", "Афанасьев", "Данилов", "Савельев", "Тимофеев", "Фомин", "Чернов", "Абрамов",
+		"Вишневский", "Островский", "Толстой", "Шевченко", "Коваленко", "Бондаренко", "Черных", "Белых",
+	},
+	FemaleLastName: russianFemaleLastName,
+}
+
+// russianFemaleLastName образует женскую форму русской фамилии:
+// Иванов - Иванова, Вишневский - Вишневская, Толстой - Толстая.
+// Фамилии на -ко, -их, -ых не изменяются.
+func russianFemaleLastName(lastName string) string {
+	for _, suffix := range []struct{ male, female string }{
+		{"ский", "ская"}, {"цкий", "цкая"}, {"ой", "ая"},
+		{"ов", "ова"}, {"ев", "ева"}, {"ёв", "ёва"}, {"ин", "ина"}, {"ын", "ына"},
+	} {
+		if strings.HasSuffix(lastName, suffix.male) {
+			return strings.TrimSuffix(lastName, suffix.male) + suffix.female
+		}
+	}
+	return lastName
+}
+
+// Locales - поддерживаемые локали по коду
+var Locales = map[string]*Locale{
+	LocaleEN.Code: LocaleEN,
+	LocaleRU.Code: LocaleRU,
+}
+
+// PasswordPolicy задает требования к паролю. Символы из Exclude не
+// используются (например, похожие 0/O и l/1 или запрещенные сайтом).
+type PasswordPolicy struct {
+	Length         int
+	RequireLower   bool
+	RequireUpper   bool
+	RequireDigits  bool
+	RequireSpecial bool
+	// Special - допустимые специальные символы, по умолчанию как во frontend
+	Special string
+	Exclude string
+}
+
+// DefaultPasswordPolicy - 12 символов, все классы символов, как во frontend
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		Length:         defaultPasswordLength,
+		RequireLower:   true,
+		RequireUpper:   true,
+		RequireDigits:  true,
+		RequireSpecial: true,
+	}
+}
+
+// classes возвращает наборы символов: обязательные классы и общий алфавит
+func (p PasswordPolicy) classes() (required []string, alphabet string, err error) {
+	special := p.Special
+	if special == "" {
+		special = passwordSpecialChars
+	}
+	exclude := func(chars string) string {
+		return strings.Map(func(r rune) rune {
+			if strings.ContainsRune(p.Exclude, r) {
+				return -1
+			}
+			return r
+		}, chars)
+	}
+
+	// Строчные буквы используются всегда, как во frontend
+	for _, class := range []struct {
+		chars    string
+		required bool
+		use      bool
+	}{
+		{passwordLowerChars, p.RequireLower, true},
+		{passwordUpperChars, p.RequireUpper, p.RequireUpper},
+		{passwordDigitChars, p.RequireDigits, p.RequireDigits},
+		{special, p.RequireSpecial, p.RequireSpecial},
+	} {
+		chars := exclude(class.chars)
+		if class.required && chars == "" {
+			return nil, "", fmt.Errorf("%w: все символы обязательного класса исключены", ErrInvalidPasswordPolicy)
+		}
+		if class.required {
+			required = append(required, chars)
+		}
+		if class.use {
+			alphabet += chars
+		}
+	}
+	if alphabet == "" {
+		return nil, "", fmt.Errorf("%w: нет допустимых символов", ErrInvalidPasswordPolicy)
+	}
+	if p.length() < len(required) {
+		return nil, "", fmt.Errorf("%w: длина %d меньше числа обязательных классов %d",
+			ErrInvalidPasswordPolicy, p.length(), len(required))
+	}
+	return required, alphabet, nil
+}
+
+func (p PasswordPolicy) length() int {
+	if p.Length <= 0 {
+		return defaultPasswordLength
+	}
+	return p.Length
+}
+
+// Check проверяет, что пароль соответствует политике
+func (p PasswordPolicy) Check(password string) error {
+	if n := len([]rune(password)); n < p.length() {
+		return fmt.Errorf("%w: длина %d меньше %d", ErrPasswordPolicy, n, p.length())
+	}
+	if i := strings.IndexAny(password, p.Exclude); p.Exclude != "" && i >= 0 {
+		return fmt.Errorf("%w: запрещенный символ %q", ErrPasswordPolicy, password[i])
+	}
+	required, _, err := p.classes()
+	if err != nil {
+		return err
+	}
+	for _, chars := range required {
+		if !strings.ContainsAny(password, chars) {
+			return fmt.Errorf("%w: нет символов из набора %q", ErrPasswordPolicy, chars)
+		}
+	}
+	return nil
+}
+
+// CodeKind - алфавит кода подтверждения
+type CodeKind int
+
+const (
+	// CodeAlphanumeric - заглавные буквы и цифры
+	CodeAlphanumeric CodeKind = iota
+	// CodeLetters - только заглавные буквы
+	CodeLetters
+	// CodeDigits - только цифры
+	CodeDigits
+)
+
+// Identity - данные пользователя для регистрации
+type Identity struct {
+	FirstName string    `json:"firstName"`
+	LastName  string    `json:"lastName"`
+	Female    bool      `json:"female"`
+	Login     string    `json:"login"`
+	Password  string    `json:"password"`
+	Locale    string    `json:"locale"`
+	Generated time.Time `json:"generated"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	// EmailAddress и InboxID заполняются, если данные привязаны к ящику
+	EmailAddress string `json:"emailAddress,omitempty"`
+	InboxID      string `json:"inboxId,omitempty"`
+}
+
+// FullName возвращает имя и фамилию
+func (i *Identity) FullName() string {
+	return i.FirstName + " " + i.LastName
+}
+
+// AttachInbox привязывает данные к почтовому ящику
+func (i *Identity) AttachInbox(inbox *Inbox) {
+	i.EmailAddress = inbox.EmailAddress
+	i.InboxID = inbox.ID
+}
+
+// IdentityGenerator генерирует имена, логины, пароли и коды. При одинаковом
+// seed и одинаковой последовательности вызовов результаты совпадают, что
+// позволяет воспроизвести упавший тест. Безопасен для параллельного
+// использования, но порядок вызовов из разных горутин не детерминирован.
+type IdentityGenerator struct {
+	Locale *Locale
+	Policy PasswordPolicy
+
+	mu     sync.Mutex
+	seed   int64
+	rnd    *rand.Rand
+	logins map[string]bool
+}
+
+// NewIdentityGenerator создает генератор для локали. seed 0 выбирается
+// по текущему времени; узнать его можно через Seed.
+func NewIdentityGenerator(locale *Locale, seed int64) *IdentityGenerator {
+	if locale == nil {
+		locale = LocaleEN
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &IdentityGenerator{
+		Locale: locale,
+		Policy: DefaultPasswordPolicy(),
+		seed:   seed,
+		rnd:    rand.New(rand.NewSource(seed)),
+		logins: map[string]bool{},
+	}
+}
+
+// Seed возвращает зерно генератора для воспроизведения результатов
+func (g *IdentityGenerator) Seed() int64 {
+	return g.seed
+}
+
+func (g *IdentityGenerator) intn(n int) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.rnd.Intn(n)
+}
+
+func (g *IdentityGenerator) pick(items []string) string {
+	return items[g.intn(len(items))]
+}
+
+// FirstName возвращает случайное имя
+func (g *IdentityGenerator) FirstName(female bool) string {
+	if female {
+		return g.pick(g.Locale.FemaleNames)
+	}
+	return g.pick(g.Locale.MaleNames)
+}
+
+// LastName возвращает случайную фамилию в мужской или женской форме
+func (g *IdentityGenerator) LastName(female bool) string {
+	name := g.pick(g.Locale.LastNames)
+	if female && g.Locale.FemaleLastName != nil {
+		name = g.Locale.FemaleLastName(name)
+	}
+	return name
+}
+
+// Login возвращает логин из имени и фамилии в одном из форматов frontend:
+// имя.фамилия123, имяф123, ифамилия123, имяфамилия12, имя.фамилияя123.
+// Кириллица транслитерируется.
+func (g *IdentityGenerator) Login(firstName, lastName string) string {
+	name := transliterate(strings.ToLower(firstName))
+	surname := transliterate(strings.ToLower(lastName))
+	first := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return s[:1]
+	}
+	last := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return s[len(s)-1:]
+	}
+
+	switch g.intn(5) {
+	case 0:
+		return fmt.Sprintf("%s.%s%d", name, surname, g.intn(1000))
+	case 1:
+		return fmt.Sprintf("%s%s%d", name, first(surname), g.intn(1000))
+	case 2:
+		return fmt.Sprintf("%s%s%d", first(name), surname, g.intn(1000))
+	case 3:
+		return fmt.Sprintf("%s%s%d", name, surname, g.intn(100))
+	default:
+		return fmt.Sprintf("%s.%s%s%d", name, surname, last(surname), g.intn(1000))
+	}
+}
+
+// uniqueLogin возвращает логин, который генератор еще не выдавал
+func (g *IdentityGenerator) uniqueLogin(firstName, lastName string) string {
+	var login string
+	for attempt := 0; attempt < uniqueLoginAttempts; attempt++ {
+		login = g.Login(firstName, lastName)
+		g.mu.Lock()
+		used := g.logins[login]
+		g.logins[login] = true
+		g.mu.Unlock()
+		if !used {
+			return login
+		}
+	}
+	return fmt.Sprintf("%s%d", login, g.intn(1000000))
+}
+
+// Password генерирует пароль по политике: по одному символу каждого
+// обязательного класса, остальные - из общего алфавита, затем перемешивание
+func (g *IdentityGenerator) Password(policy PasswordPolicy) (string, error) {
+	required, alphabet, err := policy.classes()
+	if err != nil {
+		return "", err
+	}
+
+	password := make([]rune, 0, policy.length())
+	for _, chars := range required {
+		runes := []rune(chars)
+		password = append(password, runes[g.intn(len(runes))])
+	}
+	all := []rune(alphabet)
+	for len(password) < policy.length() {
+		password = append(password, all[g.intn(len(all))])
+	}
+	g.mu.Lock()
+	g.rnd.Shuffle(len(password), func(i, j int) { password[i], password[j] = password[j], password[i] })
+	g.mu.Unlock()
+	return string(password), nil
+}
+
+// VerificationCode генерирует код подтверждения длины length (по умолчанию 6)
+func (g *IdentityGenerator) VerificationCode(length int, kind CodeKind) string {
+	if length <= 0 {
+		length = defaultCodeLength
+	}
+	chars := passwordUpperChars + passwordDigitChars
+	switch kind {
+	case CodeLetters:
+		chars = passwordUpperChars
+	case CodeDigits:
+		chars = passwordDigitChars
+	}
+	code := make([]byte, length)
+	for i := range code {
+		code[i] = chars[g.intn(len(chars))]
+	}
+	return string(code)
+}
+
+// Identity генерирует полный набор данных пользователя с паролем по g.Policy
+func (g *IdentityGenerator) Identity() (*Identity, error) {
+	female := g.intn(2) == 1
+	firstName := g.FirstName(female)
+	lastName := g.LastName(female)
+	password, err := g.Password(g.Policy)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &Identity{
+		FirstName: firstName,
+		LastName:  lastName,
+		Female:    female,
+		Login:     g.uniqueLogin(firstName, lastName),
+		Password:  password,
+		Locale:    g.Locale.Code,
+		Generated: now,
+		ExpiresAt: now.Add(defaultIdentityLifetime),
+	}, nil
+}
+
+// IdentityWithInbox генерирует данные пользователя и создает для него
+// почтовый ящик с именем по логину
+func (g *IdentityGenerator) IdentityWithInbox(client MailSlurpClient, opts CreateInboxOptions) (*Identity, error) {
+	identity, err := g.Identity()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Name == "" {
+		opts.Name = identity.Login
+	}
+	inbox, err := client.CreateInboxWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	identity.AttachInbox(inbox)
+	return identity, nil
+}
+
+// translitTable - транслитерация кириллицы для логинов
+var translitTable = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// transliterate переводит строку в нижнем регистре в латиницу, оставляя
+// только буквы и цифры ASCII
+func transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if latin, ok := translitTable[r]; ok {
+			b.WriteString(latin)
+		} else if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,137 @@
+package mailslurp_test
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	. "mailslurp-example"
+	"mailslurp-example/mailslurptest"
+)
+
+// TestIdentityGeneratorDeterministic проверяет воспроизводимость по seed
+func TestIdentityGeneratorDeterministic(t *testing.T) {
+	a := NewIdentityGenerator(LocaleRU, 42)
+	b := NewIdentityGenerator(LocaleRU, 42)
+	for i := 0; i < 5; i++ {
+		x, err := a.Identity()
+		if err != nil {
+			t.Fatal(err)
+		}
+		y, _ := b.Identity()
+		if x.FullName() != y.FullName() || x.Login != y.Login || x.Password != y.Password {
+			t.Errorf("Генераторы с одним seed разошлись: %+v и %+v", x, y)
+		}
+	}
+
+	c := NewIdentityGenerator(LocaleRU, 43)
+	x, _ := NewIdentityGenerator(LocaleRU, 42).Identity()
+	y, _ := c.Identity()
+	if x.Password == y.Password {
+		t.Error("Разные seed должны давать разные пароли")
+	}
+	if NewIdentityGenerator(nil, 0).Seed() == 0 {
+		t.Error("Нулевой seed должен заменяться случайным")
+	}
+}
+
+// TestIdentityLocales проверяет имена, женские фамилии и транслитерацию логинов
+func TestIdentityLocales(t *testing.T) {
+	login := regexp.MustCompile(`^[a-z0-9.]+$`)
+	for _, locale := range []*Locale{LocaleEN, LocaleRU} {
+		gen := NewIdentityGenerator(locale, 7)
+		seen := map[string]bool{}
+		for i := 0; i < 200; i++ {
+			identity, err := gen.Identity()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !login.MatchString(identity.Login) {
+				t.Errorf("%s: логин должен быть в латинице: %q", locale.Code, identity.Login)
+			}
+			if seen[identity.Login] {
+				t.Errorf("%s: логин повторился: %s", locale.Code, identity.Login)
+			}
+			seen[identity.Login] = true
+			if identity.Locale != locale.Code {
+				t.Errorf("Неверная локаль: %s", identity.Locale)
+			}
+		}
+	}
+
+	tests := map[string]string{"Иванов": "Иванова", "Соловьёв": "Соловьёва", "Вишневский": "Вишневская",
+		"Толстой": "Толстая", "Ильин": "Ильина", "Шевченко": "Шевченко", "Черных": "Черных"}
+	for male, female := range tests {
+		if got := RussianFemaleLastName(male); got != female {
+			t.Errorf("RussianFemaleLastName(%s) = %s, ожидалось %s", male, got, female)
+		}
+	}
+	if got := Transliterate("щукин-ёжик"); got != "shchukinezhik" {
+		t.Errorf("Неверная транслитерация: %s", got)
+	}
+}
+
+// TestPasswordPolicy проверяет генерацию паролей по политике
+func TestPasswordPolicy(t *testing.T) {
+	gen := NewIdentityGenerator(LocaleEN, 1)
+	policy := PasswordPolicy{Length: 16, RequireLower: true, RequireUpper: true, RequireDigits: true,
+		RequireSpecial: true, Special: "!#", Exclude: "0O1lI"}
+	for i := 0; i < 100; i++ {
+		password, err := gen.Password(policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(password) != 16 || strings.ContainsAny(password, "0O1lI@$") {
+			t.Fatalf("Пароль нарушает политику: %q", password)
+		}
+		if err := policy.Check(password); err != nil {
+			t.Fatalf("Check(%q): %v", password, err)
+		}
+	}
+
+	if err := DefaultPasswordPolicy().Check("short"); !errors.Is(err, ErrPasswordPolicy) {
+		t.Errorf("Короткий пароль должен нарушать политику, получено: %v", err)
+	}
+	if err := DefaultPasswordPolicy().Check("nouppercase1!x"); !errors.Is(err, ErrPasswordPolicy) {
+		t.Errorf("Пароль без заглавных должен нарушать политику, получено: %v", err)
+	}
+	if _, err := gen.Password(PasswordPolicy{Length: 2, RequireUpper: true, RequireDigits: true, RequireSpecial: true}); !errors.Is(err, ErrInvalidPasswordPolicy) {
+		t.Errorf("Ожидалась ErrInvalidPasswordPolicy, получено: %v", err)
+	}
+	if _, err := gen.Password(PasswordPolicy{RequireDigits: true, Exclude: "0123456789"}); !errors.Is(err, ErrInvalidPasswordPolicy) {
+		t.Errorf("Ожидалась ErrInvalidPasswordPolicy, получено: %v", err)
+	}
+}
+
+// TestVerificationCode проверяет алфавиты кодов подтверждения
+func TestVerificationCode(t *testing.T) {
+	gen := NewIdentityGenerator(LocaleEN, 3)
+	patterns := map[CodeKind]*regexp.Regexp{
+		CodeAlphanumeric: regexp.MustCompile(`^[A-Z0-9]{6}$`),
+		CodeLetters:      regexp.MustCompile(`^[A-Z]{8}$`),
+		CodeDigits:       regexp.MustCompile(`^[0-9]{4}$`),
+	}
+	lengths := map[CodeKind]int{CodeAlphanumeric: 0, CodeLetters: 8, CodeDigits: 4}
+	for kind, pattern := range patterns {
+		if code := gen.VerificationCode(lengths[kind], kind); !pattern.MatchString(code) {
+			t.Errorf("Неверный код %d: %q", kind, code)
+		}
+	}
+}
+
+// TestIdentityWithInbox проверяет привязку данных к новому ящику
+func TestIdentityWithInbox(t *testing.T) {
+	mock := mailslurptest.NewMockClient()
+	identity, err := NewIdentityGenerator(LocaleRU, 5).IdentityWithInbox(mock, CreateInboxOptions{Tags: []string{"signup"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inboxes, _ := mock.GetInboxes()
+	if len(inboxes) != 1 || inboxes[0].ID != identity.InboxID || inboxes[0].EmailAddress != identity.EmailAddress {
+		t.Fatalf("Данные не привязаны к ящику: %+v, %+v", identity, inboxes)
+	}
+	if inboxes[0].Name != identity.Login {
+		t.Errorf("Имя ящика должно совпадать с логином: %s", inboxes[0].Name)
+	}
+}
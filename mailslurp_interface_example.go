@@ -1,6 +1,7 @@
-package main
+package mailslurp
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -96,6 +97,39 @@ func ExampleCreateMultipleInboxes() {
 	}
 }
 
+// Пример использования пула заранее созданных почтовых ящиков
+func ExampleInboxPool() {
+	// Создаем клиент MailSlurp
+	client := NewMailSlurpClient("YOUR_API_KEY")
+	
+	// Пул держит три готовых ящика и не выходит за лимит плана
+	pool := NewInboxPool(client, InboxPoolOptions{
+		Size:       3,
+		MaxInboxes: GetPlanLimits("basic").MaxInboxes,
+		Reuse:      true,
+	})
+	defer pool.Close()
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	
+	// Получаем ящик без ожидания создания
+	inbox, err := pool.Acquire(ctx)
+	if err != nil {
+		log.Fatalf("Ошибка при получении почтового ящика из пула: %v", err)
+	}
+	
+	fmt.Printf("Получен почтовый ящик из пула: %s\n", inbox.EmailAddress)
+	
+	// После теста письма удаляются, а ящик возвращается в пул
+	if err := pool.Release(inbox); err != nil {
+		log.Printf("Предупреждение: не удалось вернуть почтовый ящик в пул: %v", err)
+	}
+	
+	stats := pool.Stats()
+	fmt.Printf("Готово: %d, выдано: %d, создано: %d\n", stats.Ready, stats.InUse, stats.Created)
+}
+
 // Пример использования интерфейса для тестирования регистрации
 func ExampleTestRegistration() {
 	// Создаем клиент MailSlurp
@@ -138,4 +172,25 @@ func ExampleTestRegistration() {
 	} else {
 		fmt.Println("Временный почтовый ящик успешно удален")
 	}
-} 
\ No newline at end of file
+} 
+// Пример автоматизации регистрации: все шаги ExampleTestRegistration
+// выполняет RegistrationFlow
+func ExampleRegistrationFlow() {
+	flow := &RegistrationFlow{
+		Client: NewMailSlurpClient("YOUR_API_KEY"),
+		Register: FormRegistration("https://example.com/signup", map[string]string{
+			"email":    "{email}",
+			"password": "{password}",
+			"name":     "{full_name}",
+		}),
+		Generator: NewIdentityGenerator(LocaleRU, 0),
+		Matchers:  []EmailMatcher{SubjectContains("подтвердите")},
+		LinkHost:  "example.com",
+	}
+
+	result, err := flow.Run(context.Background())
+	if err != nil {
+		log.Fatalf("Ошибка регистрации: %v", err)
+	}
+	fmt.Printf("Пользователь %s подтвержден за %s\n", result.Identity.EmailAddress, result.Timings.Total)
+}
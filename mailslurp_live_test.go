@@ -0,0 +1,39 @@
+package mailslurp_test
+
+import (
+	"testing"
+	"time"
+
+	. "mailslurp-example"
+	"mailslurp-example/mailtest"
+)
+
+// TestSendAndReceiveEmail тестирует отправку и получение письма
+func TestSendAndReceiveEmail(t *testing.T) {
+	client := newLiveTestClient(t)
+	inbox := mailtest.Inbox(t, client)
+
+	// Отправляем письмо
+	subject := "Тестовое письмо"
+	body := "Это тестовое письмо для проверки API"
+
+	err := client.SendEmail(inbox.ID, inbox.EmailAddress, subject, body)
+	if err != nil {
+		t.Fatalf("Ошибка при отправке письма: %v", err)
+	}
+
+	// Ждем и проверяем содержимое письма
+	email := mailtest.ExpectEmail(t, inbox, SubjectContains(subject), 30*time.Second)
+	mailtest.AssertSubject(t, email, subject)
+	mailtest.AssertBody(t, email, body)
+
+	// Получаем список писем
+	emails, err := client.GetEmails(inbox.ID)
+	if err != nil {
+		t.Fatalf("Ошибка при получении списка писем: %v", err)
+	}
+
+	if len(emails) == 0 {
+		t.Error("Список писем пуст")
+	}
+}
@@ -0,0 +1,227 @@
+package mailslurp
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// redactedValue подставляется вместо API ключей в логах и ошибках
+const redactedValue = "[REDACTED]"
+
+// defaultMaxLoggedBody - лимит тела запроса и ответа в логах по умолчанию
+const defaultMaxLoggedBody = 2048
+
+// secretPattern находит API ключи в заголовках (x-api-key: ...), query (apiKey=...)
+// и JSON ("apiKey": "...")
+var secretPattern = regexp.MustCompile(`(?i)((?:x-api-key|api[_-]?key)["']?\s*[:=]\s*["']?)([^"'&\s,;}]+)`)
+
+// LogOptions задает параметры логирования запросов к API
+type LogOptions struct {
+	// Level - уровень успешных запросов, по умолчанию slog.LevelDebug
+	Level slog.Leveler
+	// ErrorLevel - уровень запросов с ошибкой, по умолчанию slog.LevelWarn
+	ErrorLevel slog.Leveler
+	// LogBodies включает запись тел запроса и ответа
+	LogBodies bool
+	// MaxBodySize - максимальный размер тела в логе в байтах, по умолчанию 2048
+	MaxBodySize int
+}
+
+// WithLogger включает логирование запросов через slog: метод, путь, статус,
+// длительность и количество попыток. Каждая попытка дополнительно пишется
+// на уровне Debug. API ключи всегда маскируются.
+func WithLogger(logger *slog.Logger, opts LogOptions) ClientOption {
+	return func(c *DefaultMailSlurpClient) {
+		if logger == nil {
+			logger = slog.Default()
+		}
+		if opts.Level == nil {
+			opts.Level = slog.LevelDebug
+		}
+		if opts.ErrorLevel == nil {
+			opts.ErrorLevel = slog.LevelWarn
+		}
+		if opts.MaxBodySize <= 0 {
+			opts.MaxBodySize = defaultMaxLoggedBody
+		}
+		l := &requestLogger{logger: logger, opts: opts}
+		c.logAttempts = l.attempts
+		c.after = append(c.after, l.afterResponse)
+	}
+}
+
+// RedactSecrets маскирует API ключи в строке: значения x-api-key и apiKey,
+// а также явно переданные секреты, где бы они ни встретились
+func RedactSecrets(s string, secrets ...string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, redactedValue)
+		}
+	}
+	return secretPattern.ReplaceAllString(s, "${1}"+redactedValue)
+}
+
+// RedactURL возвращает URL без значений API ключей в query
+func RedactURL(u *url.URL) string {
+	redacted := *u
+	query := u.Query()
+	changed := false
+	for name := range query {
+		if isSecretParam(name) {
+			query.Set(name, redactedValue)
+			changed = true
+		}
+	}
+	if changed {
+		redacted.RawQuery = query.Encode()
+	}
+	redacted.User = nil
+	return redacted.String()
+}
+
+func isSecretParam(name string) bool {
+	name = strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(name))
+	return name == "apikey" || name == "xapikey"
+}
+
+// requestLogger пишет запросы клиента в slog
+type requestLogger struct {
+	logger *slog.Logger
+	opts   LogOptions
+}
+
+// afterResponse пишет итог вызова API после всех повторов
+func (l *requestLogger) afterResponse(req *http.Request, resp *http.Response, body []byte, err error) {
+	ctx := req.Context()
+	failed := err != nil || resp.StatusCode < 200 || resp.StatusCode >= 300
+	level := l.opts.Level.Level()
+	if failed {
+		level = l.opts.ErrorLevel.Level()
+	}
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	secret := req.Header.Get("x-api-key")
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("path", RedactSecrets(RedactURL(&url.URL{Path: req.URL.Path, RawQuery: req.URL.RawQuery}), secret)),
+	}
+	if info := RequestInfoFromContext(ctx); info != nil {
+		attrs = append(attrs,
+			slog.String("operation", info.Operation),
+			slog.Duration("duration", time.Since(info.Start)),
+			slog.Int("attempts", info.Attempts),
+		)
+		if info.InboxID != "" {
+			attrs = append(attrs, slog.String("inbox_id", info.InboxID))
+		}
+		if info.EmailID != "" {
+			attrs = append(attrs, slog.String("email_id", info.EmailID))
+		}
+	}
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", RedactSecrets(err.Error(), secret)))
+	}
+
+	// Тело ответа с ошибкой пишем всегда: по нему обычно понятна причина
+	if l.opts.LogBodies || (failed && len(body) > 0) {
+		if l.opts.LogBodies {
+			if reqBody := requestBody(req); reqBody != nil {
+				attrs = append(attrs, slog.String("request_body", l.body(reqBody, secret)))
+			}
+		}
+		if len(body) > 0 {
+			attrs = append(attrs, slog.String("response_body", l.body(body, secret)))
+		}
+	}
+
+	msg := "запрос к MailSlurp API"
+	if failed {
+		msg = "ошибка запроса к MailSlurp API"
+	}
+	l.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// attempts оборачивает транспорт и пишет каждую попытку на уровне Debug
+func (l *requestLogger) attempts(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		ctx := req.Context()
+		if !l.logger.Enabled(ctx, slog.LevelDebug) {
+			return resp, err
+		}
+
+		secret := req.Header.Get("x-api-key")
+		attrs := []slog.Attr{
+			slog.String("method", req.Method),
+			slog.String("url", RedactSecrets(RedactURL(req.URL), secret)),
+			slog.String("headers", RedactSecrets(dumpHeaders(req.Header), secret)),
+		}
+		if info := RequestInfoFromContext(ctx); info != nil {
+			attrs = append(attrs, slog.Int("attempt", info.Attempts))
+		}
+		if resp != nil {
+			attrs = append(attrs, slog.Int("status", resp.StatusCode))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", RedactSecrets(err.Error(), secret)))
+		}
+		l.logger.LogAttrs(ctx, slog.LevelDebug, "попытка запроса к MailSlurp API", attrs...)
+		return resp, err
+	})
+}
+
+// body маскирует ключи и обрезает тело до MaxBodySize
+func (l *requestLogger) body(body []byte, secret string) string {
+	s := RedactSecrets(string(body), secret)
+	if len(s) <= l.opts.MaxBodySize {
+		return s
+	}
+	cut := l.opts.MaxBodySize
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s... (обрезано, всего %d байт)", s[:cut], len(s))
+}
+
+// requestBody возвращает копию тела запроса, не трогая исходный поток
+func requestBody(req *http.Request) []byte {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	return data
+}
+
+// dumpHeaders формирует строку заголовков запроса для отладки
+func dumpHeaders(header http.Header) string {
+	var b strings.Builder
+	for _, name := range sortedKeys(header) {
+		for _, value := range header[name] {
+			if b.Len() > 0 {
+				b.WriteString("; ")
+			}
+			b.WriteString(name + ": " + value)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package mailslurp_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	. "mailslurp-example"
+)
+
+// TestRedactSecrets проверяет маскирование ключей в разных форматах
+func TestRedactSecrets(t *testing.T) {
+	cases := map[string]string{
+		"X-Api-Key: secret-123":                 "X-Api-Key: [REDACTED]",
+		"GET /inboxes?apiKey=secret-123&page=1": "GET /inboxes?apiKey=[REDACTED]&page=1",
+		`{"apiKey": "secret-123", "ok": true}`:  `{"apiKey": "[REDACTED]", "ok": true}`,
+		"ключ secret-123 не активен":            "ключ [REDACTED] не активен",
+		"apiKeyManager загружен":                "apiKeyManager загружен",
+	}
+	for input, want := range cases {
+		if got := RedactSecrets(input, "secret-123"); got != want {
+			t.Errorf("RedactSecrets(%q) = %q, ожидалось %q", input, got, want)
+		}
+	}
+
+	u, _ := url.Parse("https://api.mailslurp.com/inboxes?api_key=secret-123&size=10")
+	if got := RedactURL(u); strings.Contains(got, "secret-123") || !strings.Contains(got, "size=10") {
+		t.Errorf("Неверный результат RedactURL: %s", got)
+	}
+}
+
+// TestClientLogging проверяет, что запросы логируются без API ключа
+func TestClientLogging(t *testing.T) {
+	server := newFakeMailSlurpServer(t)
+
+	var out bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := NewMailSlurpClientFromProfile(&Profile{APIKey: server.apiKey, BaseURL: server.URL},
+		WithMiddleware(RetryMiddleware(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond})),
+		WithLogger(logger, LogOptions{Level: slog.LevelInfo, LogBodies: true, MaxBodySize: 64}))
+
+	inbox, err := client.CreateInbox()
+	if err != nil {
+		t.Fatalf("Ошибка при создании почтового ящика: %v", err)
+	}
+	server.failNext(http.StatusServiceUnavailable, "UNAVAILABLE")
+	if _, err := client.GetEmails(inbox.ID); err != nil {
+		t.Fatalf("Ошибка при получении писем: %v", err)
+	}
+	if err := client.SendEmail(inbox.ID, inbox.EmailAddress, "Тема", strings.Repeat("длинное тело ", 20)); err != nil {
+		t.Fatalf("Ошибка при отправке письма: %v", err)
+	}
+
+	logs := out.String()
+	if strings.Contains(logs, server.apiKey) {
+		t.Fatalf("API ключ попал в логи:\n%s", logs)
+	}
+	for _, want := range []string{
+		`level=INFO msg="запрос к MailSlurp API" method=POST path=/inboxes operation=CreateInbox`,
+		`operation=GetEmails`,
+		`attempts=2`,
+		`inbox_id=` + inbox.ID,
+		`status=503`,
+		`X-Api-Key: [REDACTED]`,
+		`обрезано, всего`,
+	} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("В логах нет %q:\n%s", want, logs)
+		}
+	}
+}
+
+// TestAPIErrorRedaction проверяет, что ключ не попадает в ошибку и лог ошибки
+func TestAPIErrorRedaction(t *testing.T) {
+	const apiKey = "secret-key-42"
+	transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		body := `{"errorCode":"UNAUTHORIZED","message":"ключ ` + apiKey + ` не найден","apiKey":"` + apiKey + `"}`
+		return &http.Response{
+			StatusCode: http.StatusUnauthorized,
+			Status:     "401 Unauthorized",
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     http.Header{},
+			Request:    req,
+		}, nil
+	})
+
+	var out bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&out, nil))
+	client := NewMailSlurpClient(apiKey, WithTransport(transport), WithLogger(logger, LogOptions{}))
+
+	_, err := client.GetInboxes()
+	if err == nil {
+		t.Fatal("Ожидалась ошибка")
+	}
+	if strings.Contains(err.Error(), apiKey) {
+		t.Errorf("API ключ попал в текст ошибки: %v", err)
+	}
+	if strings.Contains(out.String(), apiKey) {
+		t.Errorf("API ключ попал в лог:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), `"level":"WARN"`) || !strings.Contains(out.String(), `"status":401`) {
+		t.Errorf("Ошибка должна логироваться на уровне WARN со статусом:\n%s", out.String())
+	}
+}
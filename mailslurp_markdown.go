@@ -0,0 +1,221 @@
+package mailslurp
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Шаблоны блочной разметки Markdown
+var (
+	mdHeadingPattern   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdRulePattern      = regexp.MustCompile(`^\s{0,3}(?:(?:\*\s*){3,}|(?:-\s*){3,}|(?:_\s*){3,})$`)
+	mdBulletPattern    = regexp.MustCompile(`^\s{0,3}[-*+]\s+(.*)$`)
+	mdOrderedPattern   = regexp.MustCompile(`^\s{0,3}\d{1,9}[.)]\s+(.*)$`)
+	mdQuotePattern     = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	mdFencePattern     = regexp.MustCompile("^\\s{0,3}(```|~~~)")
+	mdContinuationLine = regexp.MustCompile(`^\s{2,}\S`)
+)
+
+// Шаблоны строчной разметки; применяются к тексту после экранирования HTML
+var (
+	mdImagePattern    = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+	mdLinkPattern     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	mdAutolinkPattern = regexp.MustCompile(`&lt;((?:https?://|mailto:)[^\s&]+)&gt;`)
+	mdStrongPattern   = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*|__(\S(?:.*?\S)?)__`)
+	mdEmPattern       = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*|\b_(\S(?:[^_]*?\S)?)_\b`)
+	mdStrikePattern   = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+)
+
+// MarkdownToHTML преобразует Markdown в HTML. Поддерживаются заголовки,
+// абзацы, списки, цитаты, блоки кода, горизонтальные линии, ссылки,
+// изображения, выделение и перенос строки двумя пробелами. HTML в тексте
+// экранируется.
+func MarkdownToHTML(markdown string) string {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	var out strings.Builder
+	renderMarkdownBlocks(&out, lines)
+	return strings.TrimRight(out.String(), "\n")
+}
+
+func renderMarkdownBlocks(out *strings.Builder, lines []string) {
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + renderMarkdownLines(paragraph) + "</p>\n")
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+
+		case mdFencePattern.MatchString(line):
+			flush()
+			fence := mdFencePattern.FindStringSubmatch(line)[1]
+			lang := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), fence[:1]))
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, lines[i])
+			}
+			if lang != "" {
+				fmt.Fprintf(out, "<pre><code class=\"language-%s\">", html.EscapeString(lang))
+			} else {
+				out.WriteString("<pre><code>")
+			}
+			out.WriteString(html.EscapeString(strings.Join(code, "\n")))
+			out.WriteString("</code></pre>\n")
+
+		case mdHeadingPattern.MatchString(line):
+			flush()
+			m := mdHeadingPattern.FindStringSubmatch(line)
+			fmt.Fprintf(out, "<h%d>%s</h%d>\n", len(m[1]), renderMarkdownInline(m[2]), len(m[1]))
+
+		case mdRulePattern.MatchString(line):
+			flush()
+			out.WriteString("<hr>\n")
+
+		case mdQuotePattern.MatchString(line):
+			flush()
+			var quoted []string
+			for ; i < len(lines) && mdQuotePattern.MatchString(lines[i]); i++ {
+				quoted = append(quoted, mdQuotePattern.FindStringSubmatch(lines[i])[1])
+			}
+			i--
+			out.WriteString("<blockquote>\n")
+			renderMarkdownBlocks(out, quoted)
+			out.WriteString("</blockquote>\n")
+
+		case mdBulletPattern.MatchString(line), mdOrderedPattern.MatchString(line):
+			flush()
+			i = renderMarkdownList(out, lines, i) - 1
+
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	flush()
+}
+
+// renderMarkdownList выводит список, начинающийся со строки start, и
+// возвращает индекс первой строки после него
+func renderMarkdownList(out *strings.Builder, lines []string, start int) int {
+	pattern, tag := mdBulletPattern, "ul"
+	if !mdBulletPattern.MatchString(lines[start]) {
+		pattern, tag = mdOrderedPattern, "ol"
+	}
+
+	out.WriteString("<" + tag + ">\n")
+	var item []string
+	flush := func() {
+		if item != nil {
+			out.WriteString("<li>" + renderMarkdownLines(item) + "</li>\n")
+		}
+		item = nil
+	}
+
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if m := pattern.FindStringSubmatch(line); m != nil {
+			flush()
+			item = []string{m[1]}
+			continue
+		}
+		// Строки с отступом продолжают текущий пункт
+		if item != nil && mdContinuationLine.MatchString(line) {
+			item = append(item, strings.TrimSpace(line))
+			continue
+		}
+		break
+	}
+	flush()
+	out.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// renderMarkdownLines объединяет строки абзаца; строка, оканчивающаяся
+// двумя пробелами, дает перенос <br>
+func renderMarkdownLines(lines []string) string {
+	parts := make([]string, len(lines))
+	for i, line := range lines {
+		text := renderMarkdownInline(strings.TrimSpace(line))
+		if i < len(lines)-1 && strings.HasSuffix(line, "  ") {
+			text += "<br>"
+		}
+		parts[i] = text
+	}
+	return strings.Join(parts, "\n")
+}
+
+// renderMarkdownInline выводит строчную разметку. Код в обратных кавычках
+// и адреса ссылок не обрабатываются как разметка.
+func renderMarkdownInline(s string) string {
+	var out strings.Builder
+	segments := strings.Split(s, "`")
+	for i, segment := range segments {
+		// Нечетные сегменты - код; непарная кавычка выводится как есть
+		if i%2 == 1 && i < len(segments)-1 {
+			out.WriteString("<code>" + html.EscapeString(segment) + "</code>")
+			continue
+		}
+		if i%2 == 1 {
+			out.WriteString("`")
+		}
+		out.WriteString(renderMarkdownEmphasis(html.EscapeString(segment)))
+	}
+	return out.String()
+}
+
+// renderMarkdownEmphasis заменяет ссылки, изображения и выделение в
+// экранированном тексте. Готовые теги ссылок временно заменяются
+// метками, чтобы символы * и _ в адресах не считались выделением.
+func renderMarkdownEmphasis(s string) string {
+	var saved []string
+	save := func(tag string) string {
+		saved = append(saved, tag)
+		return fmt.Sprintf("\x00%d\x00", len(saved)-1)
+	}
+
+	s = mdImagePattern.ReplaceAllStringFunc(s, func(m string) string {
+		sub := mdImagePattern.FindStringSubmatch(m)
+		if !isSafeMarkdownURL(sub[2]) {
+			return sub[1]
+		}
+		return save(fmt.Sprintf(`<img src="%s" alt="%s">`, sub[2], sub[1]))
+	})
+	s = mdLinkPattern.ReplaceAllStringFunc(s, func(m string) string {
+		sub := mdLinkPattern.FindStringSubmatch(m)
+		if !isSafeMarkdownURL(sub[2]) {
+			return sub[1]
+		}
+		return save(fmt.Sprintf(`<a href="%s">`, sub[2])) + sub[1] + save("</a>")
+	})
+	s = mdAutolinkPattern.ReplaceAllStringFunc(s, func(m string) string {
+		url := mdAutolinkPattern.FindStringSubmatch(m)[1]
+		return save(fmt.Sprintf(`<a href="%s">%s</a>`, url, url))
+	})
+
+	s = mdStrongPattern.ReplaceAllString(s, "<strong>$1$2</strong>")
+	s = mdEmPattern.ReplaceAllString(s, "<em>$1$2</em>")
+	s = mdStrikePattern.ReplaceAllString(s, "<del>$1</del>")
+
+	for i, tag := range saved {
+		s = strings.Replace(s, fmt.Sprintf("\x00%d\x00", i), tag, 1)
+	}
+	return s
+}
+
+// isSafeMarkdownURL запрещает javascript: и подобные схемы в ссылках
+func isSafeMarkdownURL(url string) bool {
+	lower := strings.ToLower(url)
+	if i := strings.IndexAny(lower, ":/?#"); i >= 0 && lower[i] == ':' {
+		scheme := lower[:i]
+		return scheme == "http" || scheme == "https" || scheme == "mailto" || scheme == "cid"
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package mailslurp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrEmailTimeout возвращается, когда подходящее письмо не пришло за отведенное время
+var ErrEmailTimeout = errors.New("время ожидания письма истекло")
+
+// EmailMatcher проверяет, подходит ли письмо под заданное условие
+type EmailMatcher func(email *Email) bool
+
+// SubjectContains проверяет, что тема письма содержит подстроку (без учета регистра)
+func SubjectContains(substr string) EmailMatcher {
+	return func(email *Email) bool {
+		return containsFold(email.Subject, substr)
+	}
+}
+
+// FromContains проверяет, что адрес отправителя содержит подстроку (без учета регистра)
+func FromContains(substr string) EmailMatcher {
+	return func(email *Email) bool {
+		return containsFold(email.From, substr)
+	}
+}
+
+// BodyContains проверяет, что тело письма содержит подстроку (без учета регистра).
+// HTML письма проверяются и в исходном виде, и после преобразования в текст.
+func BodyContains(substr string) EmailMatcher {
+	return func(email *Email) bool {
+		return containsFold(email.Body, substr) || containsFold(email.TextBody(), substr)
+	}
+}
+
+// MatchAll объединяет несколько условий: письмо должно подходить под все
+func MatchAll(matchers ...EmailMatcher) EmailMatcher {
+	return func(email *Email) bool {
+		for _, m := range matchers {
+			if m != nil && !m(email) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// WaitForMatchingEmail ожидает письмо, подходящее под все условия.
+// Сначала проверяются уже полученные письма, затем используется WaitForLatestEmail
+// до истечения таймаута. Если подходящее письмо уже есть в ящике, например
+// от предыдущего запуска теста, возвращается оно; чтобы ждать только новое
+// письмо, используйте WaitForNewMatchingEmail.
+func WaitForMatchingEmail(client MailSlurpClient, inboxID string, timeout time.Duration, matchers ...EmailMatcher) (*Email, error) {
+	return waitForMatchingEmail(client, inboxID, timeout, false, matchers)
+}
+
+// WaitForNewMatchingEmail ожидает письмо, подходящее под все условия и
+// пришедшее после вызова: письма, которые уже были в ящике, не учитываются
+func WaitForNewMatchingEmail(client MailSlurpClient, inboxID string, timeout time.Duration, matchers ...EmailMatcher) (*Email, error) {
+	return waitForMatchingEmail(client, inboxID, timeout, true, matchers)
+}
+
+func waitForMatchingEmail(client MailSlurpClient, inboxID string, timeout time.Duration, onlyNew bool, matchers []EmailMatcher) (*Email, error) {
+	match := MatchAll(matchers...)
+	deadline := time.Now().Add(timeout)
+
+	// Проверяем письма, которые уже есть в ящике
+	emails, err := client.GetEmails(inboxID)
+	if err != nil {
+		return nil, err
+	}
+	var latest *Email
+	for i := range emails {
+		if !onlyNew && match(&emails[i]) && (latest == nil || emails[i].Created.After(latest.Created)) {
+			latest = &emails[i]
+		}
+	}
+	if latest != nil {
+		return latest, nil
+	}
+
+	// Ждем новые письма, пока не истечет таймаут
+	seen := make(map[string]bool, len(emails))
+	for _, email := range emails {
+		seen[email.ID] = true
+	}
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, fmt.Errorf("%w: ящик %s", ErrEmailTimeout, inboxID)
+		}
+
+		email, err := client.WaitForLatestEmail(inboxID, remaining)
+		if err != nil {
+			if time.Until(deadline) <= 0 {
+				return nil, fmt.Errorf("%w: ящик %s: %v", ErrEmailTimeout, inboxID, err)
+			}
+			return nil, err
+		}
+		if seen[email.ID] {
+			// Новых писем нет — делаем паузу, чтобы не опрашивать API впустую
+			time.Sleep(minDuration(matchPollInterval, time.Until(deadline)))
+			continue
+		}
+		seen[email.ID] = true
+		if match(email) {
+			return email, nil
+		}
+	}
+}
+
+// matchPollInterval - пауза между повторными запросами, если новых писем нет
+const matchPollInterval = time.Second
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
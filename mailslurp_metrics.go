@@ -0,0 +1,418 @@
+package mailslurp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Границы гистограмм в секундах
+var (
+	requestDurationBuckets  = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+	deliveryDurationBuckets = []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120, 300}
+)
+
+// pendingDeliveryTTL - сколько хранится время отправки письма, которое еще не
+// получено: не дольше верхней границы гистограммы доставки. Письма, которые
+// так и не пришли (например, отправленные на внешние адреса), иначе
+// копились бы в памяти.
+var pendingDeliveryTTL = time.Duration(deliveryDurationBuckets[len(deliveryDurationBuckets)-1] * float64(time.Second))
+
+// ClientMetrics собирает метрики вызовов MailSlurpClient в формате Prometheus
+type ClientMetrics struct {
+	requests    *counterVec
+	duration    *histogramVec
+	retries     *counterVec
+	quotaErrors *counterVec
+	delivery    *histogramVec
+}
+
+// NewClientMetrics создает пустой набор метрик
+func NewClientMetrics() *ClientMetrics {
+	return &ClientMetrics{
+		requests: newCounterVec("mailslurp_client_requests_total",
+			"Количество вызовов клиента MailSlurp.", "method", "status"),
+		duration: newHistogramVec("mailslurp_client_request_duration_seconds",
+			"Длительность вызовов клиента MailSlurp.", requestDurationBuckets, "method", "status"),
+		retries: newCounterVec("mailslurp_client_retries_total",
+			"Количество повторных попыток запросов.", "method"),
+		quotaErrors: newCounterVec("mailslurp_client_quota_errors_total",
+			"Количество ошибок из-за лимитов плана и частоты запросов.", "method"),
+		delivery: newHistogramVec("mailslurp_email_delivery_seconds",
+			"Время от отправки письма до его получения клиентом.", deliveryDurationBuckets),
+	}
+}
+
+// ObserveRequest учитывает один вызов метода клиента
+func (m *ClientMetrics) ObserveRequest(method string, duration time.Duration, err error) {
+	status := metricStatus(err)
+	m.requests.inc(method, status)
+	m.duration.observe(duration.Seconds(), method, status)
+	if IsQuotaError(err) {
+		m.quotaErrors.inc(method)
+	}
+}
+
+// ObserveRetry учитывает повторную попытку запроса
+func (m *ClientMetrics) ObserveRetry(method string) {
+	m.retries.inc(method)
+}
+
+// WithMetrics подключает к DefaultMailSlurpClient учет повторных попыток:
+// после каждого вызова API в metrics добавляются все попытки, кроме первой,
+// в том числе повторы RetryMiddleware. Метрики вызовов и доставки собирает
+// InstrumentedMailSlurpClient.
+func WithMetrics(metrics *ClientMetrics) ClientOption {
+	return WithAfterResponse(func(req *http.Request, resp *http.Response, body []byte, err error) {
+		info := RequestInfoFromContext(req.Context())
+		if info == nil {
+			return
+		}
+		for i := 1; i < info.Attempts; i++ {
+			metrics.ObserveRetry(info.Operation)
+		}
+	})
+}
+
+// ObserveDelivery учитывает время доставки письма
+func (m *ClientMetrics) ObserveDelivery(latency time.Duration) {
+	m.delivery.observe(latency.Seconds())
+}
+
+// WriteTo записывает метрики в текстовом формате Prometheus
+func (m *ClientMetrics) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	m.requests.write(&b)
+	m.duration.write(&b)
+	m.retries.write(&b)
+	m.quotaErrors.write(&b)
+	m.delivery.write(&b)
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Handler возвращает HTTP обработчик для эндпоинта /metrics
+func (m *ClientMetrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WriteTo(w)
+	})
+}
+
+// metricStatus возвращает значение метки status для результата вызова
+func metricStatus(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return strconv.Itoa(apiErr.StatusCode)
+	}
+	if errors.Is(err, ErrEmailTimeout) {
+		return "timeout"
+	}
+	return "error"
+}
+
+// InstrumentedMailSlurpClient представляет собой декоратор MailSlurpClient,
+// который записывает метрики каждого вызова. Если письмо было отправлено
+// через этот же клиент, при его получении учитывается время доставки.
+// Письма, полученные позже pendingDeliveryTTL после отправки, в метрику
+// доставки не попадают.
+type InstrumentedMailSlurpClient struct {
+	MailSlurpClient
+	metrics *ClientMetrics
+	now     func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]time.Time // получатель и тема -> время отправки
+}
+
+// NewInstrumentedMailSlurpClient создает клиент, записывающий метрики в metrics
+func NewInstrumentedMailSlurpClient(client MailSlurpClient, metrics *ClientMetrics) *InstrumentedMailSlurpClient {
+	return &InstrumentedMailSlurpClient{
+		MailSlurpClient: client,
+		metrics:         metrics,
+		now:             time.Now,
+		pending:         map[string]time.Time{},
+	}
+}
+
+// Metrics возвращает набор метрик клиента
+func (c *InstrumentedMailSlurpClient) Metrics() *ClientMetrics {
+	return c.metrics
+}
+
+// GetInboxes получает список почтовых ящиков
+func (c *InstrumentedMailSlurpClient) GetInboxes() ([]Inbox, error) {
+	start := c.now()
+	inboxes, err := c.MailSlurpClient.GetInboxes()
+	c.metrics.ObserveRequest("GetInboxes", c.now().Sub(start), err)
+	return inboxes, err
+}
+
+// CreateInbox создает новый временный почтовый ящик
+func (c *InstrumentedMailSlurpClient) CreateInbox() (*Inbox, error) {
+	start := c.now()
+	inbox, err := c.MailSlurpClient.CreateInbox()
+	c.metrics.ObserveRequest("CreateInbox", c.now().Sub(start), err)
+	return inbox, err
+}
+
+// CreateInboxWithOptions создает почтовый ящик с заданными параметрами
+func (c *InstrumentedMailSlurpClient) CreateInboxWithOptions(opts CreateInboxOptions) (*Inbox, error) {
+	start := c.now()
+	inbox, err := c.MailSlurpClient.CreateInboxWithOptions(opts)
+	c.metrics.ObserveRequest("CreateInboxWithOptions", c.now().Sub(start), err)
+	return inbox, err
+}
+
+// DeleteInbox удаляет почтовый ящик
+func (c *InstrumentedMailSlurpClient) DeleteInbox(inboxID string) error {
+	start := c.now()
+	err := c.MailSlurpClient.DeleteInbox(inboxID)
+	c.metrics.ObserveRequest("DeleteInbox", c.now().Sub(start), err)
+	return err
+}
+
+// SendEmail отправляет письмо и запоминает время отправки для метрики доставки
+func (c *InstrumentedMailSlurpClient) SendEmail(inboxID, to, subject, body string) error {
+	start := c.now()
+	err := c.MailSlurpClient.SendEmail(inboxID, to, subject, body)
+	c.metrics.ObserveRequest("SendEmail", c.now().Sub(start), err)
+	if err == nil {
+		c.trackSent(to, subject, start)
+	}
+	return err
+}
+
+// SendHTMLEmail отправляет HTML письмо и запоминает время отправки
+func (c *InstrumentedMailSlurpClient) SendHTMLEmail(inboxID, to, subject, html string) error {
+	start := c.now()
+	err := sendHTMLEmail(c.MailSlurpClient, inboxID, to, subject, html)
+	if errors.Is(err, ErrHTMLNotSupported) {
+		return err
+	}
+	c.metrics.ObserveRequest("SendHTMLEmail", c.now().Sub(start), err)
+	if err == nil {
+		c.trackSent(to, subject, start)
+	}
+	return err
+}
+
+// DeleteAllInboxEmails очищает ящик через обернутый клиент
+func (c *InstrumentedMailSlurpClient) DeleteAllInboxEmails(inboxID string) error {
+	start := c.now()
+	err := deleteAllInboxEmails(c.MailSlurpClient, inboxID)
+	if errors.Is(err, ErrCleanupNotSupported) {
+		return err
+	}
+	c.metrics.ObserveRequest("DeleteAllInboxEmails", c.now().Sub(start), err)
+	return err
+}
+
+// DownloadAttachment скачивает вложение через обернутый клиент
+func (c *InstrumentedMailSlurpClient) DownloadAttachment(emailID, attachmentID string) ([]byte, error) {
+	start := c.now()
+	data, err := downloadAttachment(c.MailSlurpClient, emailID, attachmentID)
+	if errors.Is(err, ErrAttachmentsNotSupported) {
+		return nil, err
+	}
+	c.metrics.ObserveRequest("DownloadAttachment", c.now().Sub(start), err)
+	return data, err
+}
+
+// GetAccountInfo получает информацию об аккаунте через обернутый клиент
+func (c *InstrumentedMailSlurpClient) GetAccountInfo() (*AccountInfo, error) {
+	start := c.now()
+	info, err := getAccountInfo(c.MailSlurpClient)
+	if errors.Is(err, ErrAccountInfoNotSupported) {
+		return nil, err
+	}
+	c.metrics.ObserveRequest("GetAccountInfo", c.now().Sub(start), err)
+	return info, err
+}
+
+// WaitForLatestEmail ожидает письмо и учитывает время его доставки
+func (c *InstrumentedMailSlurpClient) WaitForLatestEmail(inboxID string, timeout time.Duration) (*Email, error) {
+	start := c.now()
+	email, err := c.MailSlurpClient.WaitForLatestEmail(inboxID, timeout)
+	c.metrics.ObserveRequest("WaitForLatestEmail", c.now().Sub(start), err)
+	if err == nil {
+		c.observeReceived(*email)
+	}
+	return email, err
+}
+
+// GetEmails получает список писем и учитывает время доставки новых писем
+func (c *InstrumentedMailSlurpClient) GetEmails(inboxID string) ([]Email, error) {
+	start := c.now()
+	emails, err := c.MailSlurpClient.GetEmails(inboxID)
+	c.metrics.ObserveRequest("GetEmails", c.now().Sub(start), err)
+	for _, email := range emails {
+		c.observeReceived(email)
+	}
+	return emails, err
+}
+
+// trackSent запоминает время отправки письма и забывает письма, которые
+// не пришли за pendingDeliveryTTL
+func (c *InstrumentedMailSlurpClient) trackSent(to, subject string, sentAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictPendingLocked()
+	c.pending[deliveryKey(to, subject)] = sentAt
+}
+
+func (c *InstrumentedMailSlurpClient) evictPendingLocked() {
+	cutoff := c.now().Add(-pendingDeliveryTTL)
+	for key, sentAt := range c.pending {
+		if sentAt.Before(cutoff) {
+			delete(c.pending, key)
+		}
+	}
+}
+
+// observeReceived учитывает время доставки, если письмо было отправлено этим клиентом
+func (c *InstrumentedMailSlurpClient) observeReceived(email Email) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictPendingLocked()
+	if len(c.pending) == 0 {
+		return
+	}
+	for _, to := range email.To {
+		key := deliveryKey(to, email.Subject)
+		if sentAt, ok := c.pending[key]; ok {
+			delete(c.pending, key)
+			c.metrics.ObserveDelivery(c.now().Sub(sentAt))
+			return
+		}
+	}
+}
+
+func deliveryKey(to, subject string) string {
+	return strings.ToLower(strings.TrimSpace(to)) + "\x00" + subject
+}
+
+// counterVec - счетчик Prometheus с метками
+type counterVec struct {
+	name, help string
+	labels     []string
+	mu         sync.Mutex
+	values     map[string]float64
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{name: name, help: help, labels: labels, values: map[string]float64{}}
+}
+
+func (c *counterVec) inc(labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[formatLabels(c.labels, labelValues)]++
+}
+
+func (c *counterVec) write(b *strings.Builder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	for _, labels := range sortedKeys(c.values) {
+		fmt.Fprintf(b, "%s%s %s\n", c.name, labels, formatFloat(c.values[labels]))
+	}
+}
+
+// histogramVec - гистограмма Prometheus с метками
+type histogramVec struct {
+	name, help string
+	labels     []string
+	buckets    []float64
+	mu         sync.Mutex
+	series     map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64
+	count       uint64
+	sum         float64
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{name: name, help: help, labels: labels, buckets: buckets, series: map[string]*histogramSeries{}}
+}
+
+func (h *histogramVec) observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := formatLabels(h.labels, labelValues)
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{labelValues: labelValues, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+func (h *histogramVec) write(b *strings.Builder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	bucketLabels := append(append([]string{}, h.labels...), "le")
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		for i, bound := range h.buckets {
+			le := append(append([]string{}, s.labelValues...), formatFloat(bound))
+			fmt.Fprintf(b, "%s_bucket%s %d\n", h.name, formatLabels(bucketLabels, le), s.counts[i])
+		}
+		inf := append(append([]string{}, s.labelValues...), "+Inf")
+		fmt.Fprintf(b, "%s_bucket%s %d\n", h.name, formatLabels(bucketLabels, inf), s.count)
+		fmt.Fprintf(b, "%s_sum%s %s\n", h.name, key, formatFloat(s.sum))
+		fmt.Fprintf(b, "%s_count%s %d\n", h.name, key, s.count)
+	}
+}
+
+// formatLabels формирует набор меток вида {method="GetInboxes",status="ok"}
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	parts := make([]string, len(names))
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		parts[i] = fmt.Sprintf("%s=%q", name, value)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
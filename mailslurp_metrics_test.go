@@ -0,0 +1,92 @@
+package mailslurp_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"time"
+
+	. "mailslurp-example"
+	"mailslurp-example/mailslurptest"
+)
+
+// TestInstrumentedClientMetrics проверяет метрики вызовов, ошибок квоты и времени доставки
+func TestInstrumentedClientMetrics(t *testing.T) {
+	server := newFakeMailSlurpServer(t)
+	metrics := NewClientMetrics()
+	client := NewInstrumentedMailSlurpClient(server.client(), metrics)
+
+	inbox, err := client.CreateInbox()
+	if err != nil {
+		t.Fatalf("Ошибка при создании почтового ящика: %v", err)
+	}
+
+	server.failNext(http.StatusPaymentRequired, "PLAN_LIMIT_EXCEEDED")
+	if _, err := client.CreateInbox(); !errors.Is(err, ErrPlanLimitExceeded) {
+		t.Fatalf("Ожидалась ошибка ErrPlanLimitExceeded, получено: %v", err)
+	}
+
+	if err := client.SendEmail(inbox.ID, inbox.EmailAddress, "Метрики", "Тело"); err != nil {
+		t.Fatalf("Ошибка при отправке письма: %v", err)
+	}
+	if _, err := client.WaitForLatestEmail(inbox.ID, 0); err != nil {
+		t.Fatalf("Ошибка при ожидании письма: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	out := rec.Body.String()
+
+	for _, want := range []string{
+		`mailslurp_client_requests_total{method="CreateInbox",status="ok"} 1`,
+		`mailslurp_client_requests_total{method="CreateInbox",status="402"} 1`,
+		`mailslurp_client_quota_errors_total{method="CreateInbox"} 1`,
+		`mailslurp_client_request_duration_seconds_count{method="SendEmail",status="ok"} 1`,
+		`mailslurp_email_delivery_seconds_count 1`,
+		`mailslurp_email_delivery_seconds_bucket{le="+Inf"} 1`,
+		"# TYPE mailslurp_client_retries_total counter",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("В метриках нет строки %q:\n%s", want, out)
+		}
+	}
+}
+
+// TestInstrumentedClientForgetsUndelivered проверяет, что письма, которые не
+// пришли дольше верхней границы гистограммы доставки, не копятся в памяти
+func TestInstrumentedClientForgetsUndelivered(t *testing.T) {
+	mock := mailslurptest.NewMockClient(Inbox{ID: "inbox-1", EmailAddress: "user@example.com"})
+	client := NewInstrumentedMailSlurpClient(mock, NewClientMetrics())
+	now := time.Now()
+	client.SetClock(func() time.Time { return now })
+
+	for _, to := range []string{"a@external.com", "b@external.com", "user@example.com"} {
+		if err := client.SendEmail("inbox-1", to, "Код", "1234"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := client.PendingDeliveries(); got != 3 {
+		t.Fatalf("Ожидалось 3 неполученных письма, получено %d", got)
+	}
+
+	now = now.Add(10 * time.Minute)
+	if err := client.SendEmail("inbox-1", "c@external.com", "Код", "5678"); err != nil {
+		t.Fatal(err)
+	}
+	if got := client.PendingDeliveries(); got != 1 {
+		t.Errorf("Старые неполученные письма должны быть забыты, осталось %d", got)
+	}
+
+	// Письмо, пришедшее позже верхней границы, в метрику доставки не попадает
+	if emails, err := client.GetEmails("inbox-1"); err != nil || len(emails) != 1 {
+		t.Fatalf("Ожидалось одно полученное письмо: %+v, %v", emails, err)
+	}
+	var out strings.Builder
+	client.Metrics().WriteTo(&out)
+	if strings.Contains(out.String(), "mailslurp_email_delivery_seconds_count") {
+		t.Errorf("Просроченное письмо не должно учитываться:\n%s", out.String())
+	}
+}
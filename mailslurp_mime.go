@@ -0,0 +1,246 @@
+package mailslurp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// maxMIMEDepth ограничивает вложенность multipart частей
+const maxMIMEDepth = 10
+
+// mimeWordDecoder декодирует RFC 2047 заголовки с поддержкой кириллических кодировок
+var mimeWordDecoder = &mime.WordDecoder{CharsetReader: CharsetReader}
+
+// MessagePart - декодированная листовая часть письма
+type MessagePart struct {
+	ContentType string
+	// Charset - исходная кодировка текстовой части
+	Charset     string
+	Filename    string
+	ContentID   string
+	Disposition string
+	// Data - содержимое после снятия transfer-encoding; текстовые части
+	// перекодированы в UTF-8
+	Data []byte
+}
+
+// IsAttachment сообщает, что часть является вложением, а не текстом письма
+func (p MessagePart) IsAttachment() bool {
+	if p.Disposition == "attachment" {
+		return true
+	}
+	return p.Filename != "" || !strings.HasPrefix(p.ContentType, "text/")
+}
+
+// Message - письмо, разобранное из исходного текста в формате RFC 5322
+type Message struct {
+	Header  mail.Header
+	ID      string
+	Subject string
+	From    *mail.Address
+	To      []*mail.Address
+	Cc      []*mail.Address
+	Date    time.Time
+	// Text и HTML - первые текстовая и HTML части письма в UTF-8
+	Text string
+	HTML string
+	// Parts - все листовые части в порядке следования
+	Parts []MessagePart
+}
+
+// ParseMessage разбирает исходный текст письма: декодирует RFC 2047
+// заголовки, quoted-printable и base64 части и перекодирует текст из
+// KOI8-R, windows-1251 и ISO-8859-5 в UTF-8.
+func ParseMessage(r io.Reader) (*Message, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось разобрать письмо: %v", err)
+	}
+
+	m := &Message{
+		Header:  msg.Header,
+		ID:      strings.Trim(msg.Header.Get("Message-Id"), "<> "),
+		Subject: DecodeHeader(msg.Header.Get("Subject")),
+	}
+	parser := &mail.AddressParser{WordDecoder: mimeWordDecoder}
+	if from := msg.Header.Get("From"); from != "" {
+		if m.From, err = parser.Parse(from); err != nil {
+			m.From = &mail.Address{Address: DecodeHeader(from)}
+		}
+	}
+	m.To = parseAddressList(parser, msg.Header.Get("To"))
+	m.Cc = parseAddressList(parser, msg.Header.Get("Cc"))
+	if date, err := msg.Header.Date(); err == nil {
+		m.Date = date
+	}
+
+	if err := m.readPart(textproto.MIMEHeader(msg.Header), msg.Body, 0); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// readPart разбирает часть с заголовками header, рекурсивно обходя multipart
+func (m *Message) readPart(header textproto.MIMEHeader, body io.Reader, depth int) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxMIMEDepth {
+			return fmt.Errorf("слишком глубокая вложенность частей письма")
+		}
+		boundary := params["boundary"]
+		if boundary == "" {
+			return fmt.Errorf("не указан boundary для %s", mediaType)
+		}
+		reader := multipart.NewReader(body, boundary)
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("не удалось прочитать часть письма: %v", err)
+			}
+			if err := m.readPart(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(transferDecoder(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return fmt.Errorf("не удалось декодировать часть %s: %v", mediaType, err)
+	}
+
+	part := MessagePart{
+		ContentType: mediaType,
+		Charset:     params["charset"],
+		Filename:    DecodeHeader(params["name"]),
+		ContentID:   strings.Trim(header.Get("Content-Id"), "<> "),
+		Data:        data,
+	}
+	if disposition, dparams, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+		part.Disposition = disposition
+		if name := dparams["filename"]; name != "" {
+			part.Filename = DecodeHeader(name)
+		}
+	}
+
+	if strings.HasPrefix(mediaType, "text/") && part.Disposition != "attachment" {
+		text, err := DecodeCharset(data, part.Charset)
+		if err != nil {
+			// Неизвестная кодировка: оставляем текст как есть, заменяя
+			// некорректные последовательности
+			text, _ = DecodeCharset(data, "utf-8")
+		}
+		part.Data = []byte(text)
+		switch {
+		case mediaType == "text/plain" && m.Text == "":
+			m.Text = text
+		case mediaType == "text/html" && m.HTML == "":
+			m.HTML = text
+		}
+	}
+	m.Parts = append(m.Parts, part)
+	return nil
+}
+
+// Attachments возвращает вложения письма
+func (m *Message) Attachments() []MessagePart {
+	var attachments []MessagePart
+	for _, part := range m.Parts {
+		if part.IsAttachment() {
+			attachments = append(attachments, part)
+		}
+	}
+	return attachments
+}
+
+// Email преобразует разобранное письмо в Email. Тело берется из текстовой
+// части, а при ее отсутствии - из HTML.
+func (m *Message) Email() Email {
+	email := Email{
+		ID:      m.ID,
+		Subject: m.Subject,
+		Body:    m.Text,
+		Created: m.Date,
+	}
+	if email.Body == "" {
+		email.Body = m.HTML
+	}
+	if m.From != nil {
+		email.From = m.From.Address
+	}
+	for _, to := range m.To {
+		email.To = append(email.To, to.Address)
+	}
+	for _, attachment := range m.Attachments() {
+		email.Attachments = append(email.Attachments, attachment.Filename)
+	}
+	return email
+}
+
+// DecodeHeader декодирует RFC 2047 слова (=?koi8-r?B?...?=) в значении
+// заголовка. Если значение декодировать не удалось, оно возвращается как есть.
+func DecodeHeader(value string) string {
+	if !strings.Contains(value, "=?") {
+		return value
+	}
+	decoded, err := mimeWordDecoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// transferDecoder снимает Content-Transfer-Encoding с тела части
+func transferDecoder(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &base64Cleaner{r: r})
+	default:
+		return r
+	}
+}
+
+// base64Cleaner убирает из base64 пробелы и табуляции, которые встречаются
+// в письмах; переводы строк base64.NewDecoder пропускает сам
+type base64Cleaner struct {
+	r io.Reader
+}
+
+func (c *base64Cleaner) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	n = copy(p, bytes.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, p[:n]))
+	return n, err
+}
+
+func parseAddressList(parser *mail.AddressParser, value string) []*mail.Address {
+	if value == "" {
+		return nil
+	}
+	addresses, err := parser.ParseList(value)
+	if err != nil {
+		return []*mail.Address{{Address: DecodeHeader(value)}}
+	}
+	return addresses
+}
@@ -0,0 +1,134 @@
+package mailslurp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const mimeFixtureText = "Здравствуйте, Ёлка!\r\nВаш код подтверждения: 482913\r\nСсылка: https://app.example.com/confirm?token=abc\r\n"
+
+func parseMIMEFixture(t *testing.T, name string) *Message {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", "mime", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	msg, err := ParseMessage(f)
+	if err != nil {
+		t.Fatalf("Ошибка разбора %s: %v", name, err)
+	}
+	return msg
+}
+
+// TestDecodeCharset проверяет перекодирование кириллических кодировок
+func TestDecodeCharset(t *testing.T) {
+	tests := []struct {
+		charset string
+		data    []byte
+	}{
+		{"KOI8-R", []byte{0xF0, 0xD2, 0xC9, 0xD7, 0xC5, 0xD4, 0x20, 0xA3, 0xB3}},
+		{"windows-1251", []byte{0xCF, 0xF0, 0xE8, 0xE2, 0xE5, 0xF2, 0x20, 0xB8, 0xA8}},
+		{"ISO-8859-5", []byte{0xBF, 0xE0, 0xD8, 0xD2, 0xD5, 0xE2, 0x20, 0xF1, 0xA1}},
+		{"utf-8", []byte("Привет ёЁ")},
+	}
+	for _, tt := range tests {
+		got, err := DecodeCharset(tt.data, tt.charset)
+		if err != nil {
+			t.Errorf("%s: %v", tt.charset, err)
+			continue
+		}
+		if got != "Привет ёЁ" {
+			t.Errorf("%s: получено %q", tt.charset, got)
+		}
+	}
+
+	if _, err := DecodeCharset([]byte("x"), "ebcdic"); err == nil {
+		t.Error("Ожидалась ошибка для неизвестной кодировки")
+	}
+}
+
+// TestDecodeHeader проверяет декодирование RFC 2047 заголовков
+func TestDecodeHeader(t *testing.T) {
+	tests := map[string]string{
+		"=?koi8-r?B?8NLJ18XU?=":                           "Привет",
+		"=?windows-1251?Q?=CF=F0=E8=E2=E5=F2_=EC=E8=F0?=": "Привет мир",
+		"Re: =?utf-8?B?0J/RgNC40LLQtdGC?= !":              "Re: Привет !",
+		"обычная тема":                                    "обычная тема",
+		"=?unknown?B?AAAA?=":                              "=?unknown?B?AAAA?=",
+	}
+	for in, want := range tests {
+		if got := DecodeHeader(in); got != want {
+			t.Errorf("DecodeHeader(%q) = %q, ожидалось %q", in, got, want)
+		}
+	}
+}
+
+// TestParseMessageKOI8R проверяет письмо в KOI8-R с quoted-printable
+func TestParseMessageKOI8R(t *testing.T) {
+	msg := parseMIMEFixture(t, "koi8r_quoted_printable.eml")
+	if msg.Subject != "Подтверждение регистрации" {
+		t.Errorf("Неверная тема: %q", msg.Subject)
+	}
+	if msg.From.Name != "Служба поддержки" || msg.From.Address != "support@example.ru" {
+		t.Errorf("Неверный отправитель: %+v", msg.From)
+	}
+	if len(msg.To) != 1 || msg.To[0].Name != "Иван Петров" {
+		t.Errorf("Неверные получатели: %+v", msg.To)
+	}
+	if msg.Text != mimeFixtureText {
+		t.Errorf("Неверный текст:\n%s\nожидалось:\n%s", msg.Text, mimeFixtureText)
+	}
+	if msg.Parts[0].Charset != "koi8-r" {
+		t.Errorf("Неверная кодировка части: %q", msg.Parts[0].Charset)
+	}
+}
+
+// TestParseMessageWindows1251 проверяет письмо в windows-1251 с base64
+func TestParseMessageWindows1251(t *testing.T) {
+	msg := parseMIMEFixture(t, "windows1251_base64.eml")
+	if msg.Subject != "Заказ №1234 оформлен" || msg.From.Name != "Магазин «Ромашка»" {
+		t.Errorf("Неверные заголовки: %q, %+v", msg.Subject, msg.From)
+	}
+	if msg.Text != mimeFixtureText {
+		t.Errorf("Неверный текст:\n%s\nожидалось:\n%s", msg.Text, mimeFixtureText)
+	}
+
+	email := msg.Email()
+	if email.ID != "cp1251-1@example.ru" || email.From != "shop@example.ru" || email.Created.IsZero() {
+		t.Errorf("Неверное преобразование в Email: %+v", email)
+	}
+	if code, err := ExtractCode(&email, 6); err != nil || code != "482913" {
+		t.Errorf("Код не извлечен из декодированного письма: %q, %v", code, err)
+	}
+}
+
+// TestParseMessageMultipart проверяет multipart письмо в ISO-8859-5 с вложением
+func TestParseMessageMultipart(t *testing.T) {
+	msg := parseMIMEFixture(t, "iso88595_multipart.eml")
+	if msg.Subject != "Выписка по счёту" || msg.From.Name != "Банк" {
+		t.Errorf("Неверные заголовки: %q, %+v", msg.Subject, msg.From)
+	}
+	if len(msg.To) != 2 || msg.To[1].Name != "Мария" {
+		t.Errorf("Неверные получатели: %+v", msg.To)
+	}
+	if msg.Text != mimeFixtureText {
+		t.Errorf("Неверный текст:\n%s\nожидалось:\n%s", msg.Text, mimeFixtureText)
+	}
+	if !strings.Contains(msg.HTML, "<b>482913</b>") || !strings.Contains(msg.HTML, "Ёлка") {
+		t.Errorf("Неверный HTML: %q", msg.HTML)
+	}
+
+	attachments := msg.Attachments()
+	if len(attachments) != 1 || attachments[0].Filename != "выписка.csv" {
+		t.Fatalf("Неверные вложения: %+v", attachments)
+	}
+	if string(attachments[0].Data) != "номер;сумма\r\n1234;500\r\n" {
+		t.Errorf("Неверное содержимое вложения: %q", attachments[0].Data)
+	}
+	if email := msg.Email(); len(email.Attachments) != 1 || email.Body != mimeFixtureText {
+		t.Errorf("Неверное преобразование в Email: %+v", email)
+	}
+}
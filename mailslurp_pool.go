@@ -0,0 +1,311 @@
+package mailslurp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrPoolClosed возвращается при работе с закрытым пулом
+	ErrPoolClosed = errors.New("пул почтовых ящиков закрыт")
+	// ErrCleanupNotSupported - декорируемый клиент не умеет очищать ящики
+	ErrCleanupNotSupported = errors.New("клиент не поддерживает очистку ящика")
+)
+
+// Значения InboxPoolOptions по умолчанию
+const (
+	defaultPoolSize         = 3
+	defaultPoolRetryBackoff = 30 * time.Second
+)
+
+// InboxCleaner - клиент, который умеет удалять все письма ящика.
+// Пул использует его, чтобы переиспользовать ящики вместо удаления.
+// Декораторы реализуют его всегда и возвращают ErrCleanupNotSupported, если
+// обернутый клиент очистку не поддерживает.
+type InboxCleaner interface {
+	DeleteAllInboxEmails(inboxID string) error
+}
+
+// deleteAllInboxEmails очищает ящик, если клиент это поддерживает
+func deleteAllInboxEmails(client MailSlurpClient, inboxID string) error {
+	cleaner, ok := client.(InboxCleaner)
+	if !ok {
+		return ErrCleanupNotSupported
+	}
+	return cleaner.DeleteAllInboxEmails(inboxID)
+}
+
+// InboxPoolOptions задает параметры пула почтовых ящиков
+type InboxPoolOptions struct {
+	// Size - сколько готовых ящиков держать в пуле, по умолчанию 3
+	Size int
+	// MaxInboxes - максимум ящиков пула (готовые и выданные), например
+	// GetPlanLimits(plan).MaxInboxes. 0 - без ограничения.
+	MaxInboxes int
+	// Create - параметры создаваемых ящиков
+	Create CreateInboxOptions
+	// Reuse - очищать возвращенные ящики и выдавать их снова. Требует, чтобы
+	// клиент реализовывал InboxCleaner, иначе ящики удаляются.
+	Reuse bool
+	// RetryBackoff - пауза перед повторным созданием после ошибки, по умолчанию 30s
+	RetryBackoff time.Duration
+}
+
+// InboxPoolStats - состояние пула
+type InboxPoolStats struct {
+	Ready    int `json:"ready"`
+	InUse    int `json:"inUse"`
+	Waiting  int `json:"waiting"`
+	Created  int `json:"created"`
+	Reused   int `json:"reused"`
+	Deleted  int `json:"deleted"`
+	Acquired int `json:"acquired"`
+	// CreateErrors - количество неудачных попыток создать ящик
+	CreateErrors int `json:"createErrors"`
+	// QuotaExhausted - последняя попытка создания упала на лимите плана
+	QuotaExhausted bool   `json:"quotaExhausted"`
+	LastError      string `json:"lastError,omitempty"`
+}
+
+// InboxPool держит заранее созданные почтовые ящики и выдает их по запросу,
+// чтобы тесты не тратили время на создание ящика. Пул пополняется в фоне,
+// не превышая MaxInboxes, и делает паузу при ошибках квоты.
+type InboxPool struct {
+	client MailSlurpClient
+	opts   InboxPoolOptions
+
+	mu        sync.Mutex
+	ready     []*Inbox
+	inUse     map[string]*Inbox
+	creating  int
+	cleaning  int // возвращенные ящики, которые очищаются для повторной выдачи
+	waiting   int
+	stats     InboxPoolStats
+	available chan struct{} // закрывается, когда в пуле появляется ящик
+	closed    bool
+
+	refill chan struct{}
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewInboxPool создает пул и начинает заполнять его в фоне
+func NewInboxPool(client MailSlurpClient, opts InboxPoolOptions) *InboxPool {
+	if opts.Size <= 0 {
+		opts.Size = defaultPoolSize
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = defaultPoolRetryBackoff
+	}
+	p := &InboxPool{
+		client:    client,
+		opts:      opts,
+		inUse:     map[string]*Inbox{},
+		available: make(chan struct{}),
+		refill:    make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+	p.wg.Add(1)
+	go p.refillLoop()
+	p.triggerRefill()
+	return p
+}
+
+// Acquire выдает готовый ящик, ожидая его создания, если пул пуст
+func (p *InboxPool) Acquire(ctx context.Context) (*Inbox, error) {
+	p.mu.Lock()
+	p.waiting++
+	defer func() {
+		p.waiting--
+		p.mu.Unlock()
+	}()
+
+	for {
+		if p.closed {
+			return nil, ErrPoolClosed
+		}
+		if n := len(p.ready); n > 0 {
+			inbox := p.ready[n-1]
+			p.ready = p.ready[:n-1]
+			p.inUse[inbox.ID] = inbox
+			p.stats.Acquired++
+			p.triggerRefill()
+			return inbox, nil
+		}
+
+		p.triggerRefill()
+		available := p.available
+		p.mu.Unlock()
+		select {
+		case <-available:
+			p.mu.Lock()
+		case <-ctx.Done():
+			p.mu.Lock()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Release возвращает ящик в пул. При Reuse письма удаляются и ящик выдается
+// снова; иначе, а также если очистить ящик не удалось, он удаляется.
+func (p *InboxPool) Release(inbox *Inbox) error {
+	p.mu.Lock()
+	if _, ok := p.inUse[inbox.ID]; !ok {
+		p.mu.Unlock()
+		return nil
+	}
+	delete(p.inUse, inbox.ID)
+	reuse := p.opts.Reuse && !p.closed && len(p.ready) < p.opts.Size
+	if reuse {
+		p.cleaning++
+	}
+	p.mu.Unlock()
+
+	if reuse {
+		err := deleteAllInboxEmails(p.client, inbox.ID)
+		p.mu.Lock()
+		p.cleaning--
+		// Пока ящик очищался, Close мог уже забрать готовые ящики:
+		// тогда этот ящик удаляем, иначе он останется в API
+		if err == nil && !p.closed {
+			p.stats.Reused++
+			p.pushLocked(inbox)
+			p.mu.Unlock()
+			return nil
+		}
+		p.mu.Unlock()
+	}
+
+	err := p.client.DeleteInbox(inbox.ID)
+	p.mu.Lock()
+	if err == nil || errors.Is(err, ErrNotFound) {
+		p.stats.Deleted++
+		err = nil
+	}
+	p.mu.Unlock()
+	p.triggerRefill()
+	return err
+}
+
+// Stats возвращает текущее состояние пула
+func (p *InboxPool) Stats() InboxPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := p.stats
+	stats.Ready = len(p.ready)
+	stats.InUse = len(p.inUse)
+	stats.Waiting = p.waiting
+	return stats
+}
+
+// Close останавливает пополнение и удаляет готовые ящики. Выданные ящики
+// удаляются при возврате через Release.
+func (p *InboxPool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+	close(p.available)
+	p.mu.Unlock()
+	p.wg.Wait()
+
+	p.mu.Lock()
+	ready := p.ready
+	p.ready = nil
+	p.mu.Unlock()
+
+	var errs []error
+	for _, inbox := range ready {
+		if err := p.client.DeleteInbox(inbox.ID); err != nil && !errors.Is(err, ErrNotFound) {
+			errs = append(errs, err)
+			continue
+		}
+		p.mu.Lock()
+		p.stats.Deleted++
+		p.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// pushLocked добавляет ящик в готовые и будит ожидающих Acquire
+func (p *InboxPool) pushLocked(inbox *Inbox) {
+	p.ready = append(p.ready, inbox)
+	if !p.closed {
+		close(p.available)
+		p.available = make(chan struct{})
+	}
+}
+
+func (p *InboxPool) triggerRefill() {
+	select {
+	case p.refill <- struct{}{}:
+	default:
+	}
+}
+
+// needLocked сообщает, нужно ли создать еще один ящик
+func (p *InboxPool) needLocked() bool {
+	if p.closed {
+		return false
+	}
+	want := max(p.opts.Size, p.waiting)
+	// Очищаемый ящик скоро вернется в готовые, поэтому замену ему не создаем
+	if len(p.ready)+p.creating+p.cleaning >= want {
+		return false
+	}
+	total := len(p.ready) + len(p.inUse) + p.creating + p.cleaning
+	return p.opts.MaxInboxes <= 0 || total < p.opts.MaxInboxes
+}
+
+func (p *InboxPool) refillLoop() {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-p.refill:
+		}
+
+		for {
+			p.mu.Lock()
+			if !p.needLocked() {
+				p.mu.Unlock()
+				break
+			}
+			p.creating++
+			p.mu.Unlock()
+
+			inbox, err := p.client.CreateInboxWithOptions(p.opts.Create)
+
+			p.mu.Lock()
+			p.creating--
+			if err != nil {
+				p.stats.CreateErrors++
+				p.stats.QuotaExhausted = IsQuotaError(err)
+				p.stats.LastError = err.Error()
+				p.mu.Unlock()
+				// Не долбим API после ошибки: ждем паузу или закрытия пула
+				select {
+				case <-p.done:
+					return
+				case <-time.After(p.opts.RetryBackoff):
+				}
+				continue
+			}
+			p.stats.Created++
+			p.stats.QuotaExhausted = false
+			if p.closed {
+				p.mu.Unlock()
+				p.client.DeleteInbox(inbox.ID)
+				return
+			}
+			p.pushLocked(inbox)
+			p.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package mailslurp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "mailslurp-example"
+	"mailslurp-example/mailslurptest"
+)
+
+// waitForPool ждет, пока состояние пула не станет подходящим
+func waitForPool(t *testing.T, pool *InboxPool, cond func(InboxPoolStats) bool) InboxPoolStats {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stats := pool.Stats()
+		if cond(stats) {
+			return stats
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Пул не пришел в ожидаемое состояние: %+v", stats)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// blockingCleaner задерживает очистку ящика, пока тест не разрешит продолжить
+type blockingCleaner struct {
+	*mailslurptest.MockClient
+	cleaning chan struct{}
+	proceed  chan struct{}
+}
+
+func (c *blockingCleaner) DeleteAllInboxEmails(inboxID string) error {
+	close(c.cleaning)
+	<-c.proceed
+	return c.MockClient.DeleteAllInboxEmails(inboxID)
+}
+
+// TestInboxPoolReleaseDuringClose проверяет, что ящик, возвращенный во время
+// Close, удаляется, а не остается в закрытом пуле
+func TestInboxPoolReleaseDuringClose(t *testing.T) {
+	client := &blockingCleaner{
+		MockClient: mailslurptest.NewMockClient(),
+		cleaning:   make(chan struct{}),
+		proceed:    make(chan struct{}),
+	}
+	pool := NewInboxPool(client, InboxPoolOptions{Size: 1, MaxInboxes: 1, Reuse: true})
+	inbox, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Ошибка Acquire: %v", err)
+	}
+
+	released := make(chan error, 1)
+	go func() { released <- pool.Release(inbox) }()
+	<-client.cleaning
+
+	closed := make(chan error, 1)
+	go func() { closed <- pool.Close() }()
+	waitForPool(t, pool, func(InboxPoolStats) bool { return pool.Closed() })
+	close(client.proceed)
+
+	if err := <-released; err != nil {
+		t.Fatalf("Ошибка Release: %v", err)
+	}
+	if err := <-closed; err != nil {
+		t.Fatalf("Ошибка Close: %v", err)
+	}
+	if inboxes, _ := client.GetInboxes(); len(inboxes) != 0 {
+		t.Errorf("Ящик, возвращенный во время Close, не удален: %+v", inboxes)
+	}
+	if stats := pool.Stats(); stats.Ready != 0 || stats.Reused != 0 || stats.Deleted != stats.Created {
+		t.Errorf("Неверная статистика: %+v", stats)
+	}
+}
+
+// TestInboxPoolReuse проверяет выдачу и очистку ящиков для повторного использования
+func TestInboxPoolReuse(t *testing.T) {
+	mock := mailslurptest.NewMockClient()
+	pool := NewInboxPool(mock, InboxPoolOptions{Size: 1, MaxInboxes: 1, Reuse: true, Create: CreateInboxOptions{Tags: []string{"pool"}}})
+	defer pool.Close()
+
+	inbox, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Ошибка Acquire: %v", err)
+	}
+	if len(inbox.Tags) != 1 || inbox.Tags[0] != "pool" {
+		t.Errorf("Параметры создания не применены: %+v", inbox)
+	}
+	mock.DeliverMessage(inbox.ID, "app@example.com", "Код", "1234")
+
+	if err := pool.Release(inbox); err != nil {
+		t.Fatalf("Ошибка Release: %v", err)
+	}
+	if emails, _ := mock.GetEmails(inbox.ID); len(emails) != 0 {
+		t.Errorf("Письма не удалены при возврате: %d", len(emails))
+	}
+
+	stats := pool.Stats()
+	if stats.Ready != 1 || stats.Reused != 1 || stats.Deleted != 0 || stats.Acquired != 1 || stats.InUse != 0 {
+		t.Errorf("Неверная статистика: %+v", stats)
+	}
+	if got := mock.CallCount("CreateInboxWithOptions"); got != 1 {
+		t.Errorf("Ожидался один созданный ящик, получено %d", got)
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Ошибка Close: %v", err)
+	}
+	if inboxes, _ := mock.GetInboxes(); len(inboxes) != 0 {
+		t.Errorf("Close должен удалить готовые ящики, осталось %d", len(inboxes))
+	}
+	if _, err := pool.Acquire(context.Background()); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Ожидалась ErrPoolClosed, получено: %v", err)
+	}
+}
+
+// TestInboxPoolReuseThroughDecorators проверяет, что декораторы не мешают
+// пулу очищать ящики вместо удаления и создания новых
+func TestInboxPoolReuseThroughDecorators(t *testing.T) {
+	decorators := map[string]func(t *testing.T, client MailSlurpClient) MailSlurpClient{
+		"Caching": func(t *testing.T, client MailSlurpClient) MailSlurpClient {
+			store, err := OpenMessageStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			return NewCachingMailSlurpClient(client, store, time.Minute)
+		},
+		"Instrumented": func(t *testing.T, client MailSlurpClient) MailSlurpClient {
+			return NewInstrumentedMailSlurpClient(client, NewClientMetrics())
+		},
+		"Tenant": func(t *testing.T, client MailSlurpClient) MailSlurpClient {
+			tenant, err := NewTenantClient(client, "upool", NewMemoryOwnershipStore())
+			if err != nil {
+				t.Fatal(err)
+			}
+			return tenant
+		},
+	}
+	for name, decorate := range decorators {
+		t.Run(name, func(t *testing.T) {
+			mock := mailslurptest.NewMockClient()
+			client := decorate(t, mock)
+			pool := NewInboxPool(client, InboxPoolOptions{Size: 1, MaxInboxes: 1, Reuse: true})
+			defer pool.Close()
+
+			inbox, err := pool.Acquire(context.Background())
+			if err != nil {
+				t.Fatalf("Ошибка Acquire: %v", err)
+			}
+			mock.DeliverMessage(inbox.ID, "app@example.com", "Код", "1234")
+			if emails, _ := client.GetEmails(inbox.ID); len(emails) != 1 {
+				t.Fatalf("Письмо не получено через декоратор: %d", len(emails))
+			}
+			if err := pool.Release(inbox); err != nil {
+				t.Fatalf("Ошибка Release: %v", err)
+			}
+
+			if stats := pool.Stats(); stats.Reused != 1 || stats.Deleted != 0 {
+				t.Errorf("Ящик должен быть очищен, а не удален: %+v", stats)
+			}
+			if got := mock.CallCount("CreateInboxWithOptions"); got != 1 {
+				t.Errorf("Ожидался один созданный ящик, получено %d", got)
+			}
+			// Кэш не должен возвращать письма очищенного ящика
+			if emails, _ := client.GetEmails(inbox.ID); len(emails) != 0 {
+				t.Errorf("После очистки ящика остались письма: %d", len(emails))
+			}
+		})
+	}
+
+	// Если обернутый клиент не умеет очищать ящики, декоратор сообщает об
+	// этом, и пул удаляет ящик
+	client := NewInstrumentedMailSlurpClient(struct{ MailSlurpClient }{mailslurptest.NewMockClient()}, NewClientMetrics())
+	if err := client.DeleteAllInboxEmails("inbox-1"); !errors.Is(err, ErrCleanupNotSupported) {
+		t.Errorf("Ожидалась ErrCleanupNotSupported, получено: %v", err)
+	}
+	pool := NewInboxPool(client, InboxPoolOptions{Size: 1, MaxInboxes: 1, Reuse: true})
+	defer pool.Close()
+	inbox, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Release(inbox); err != nil {
+		t.Fatal(err)
+	}
+	if stats := pool.Stats(); stats.Reused != 0 || stats.Deleted != 1 {
+		t.Errorf("Ящик без поддержки очистки должен быть удален: %+v", stats)
+	}
+}
+
+// TestInboxPoolMaxInboxes проверяет ожидание при достижении лимита ящиков
+func TestInboxPoolMaxInboxes(t *testing.T) {
+	mock := mailslurptest.NewMockClient()
+	pool := NewInboxPool(mock, InboxPoolOptions{Size: 1, MaxInboxes: 2})
+	defer pool.Close()
+
+	first, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("При исчерпании лимита Acquire должен ждать, получено: %v", err)
+	}
+
+	// Без Reuse возвращенный ящик удаляется, и освобождается место для нового
+	done := make(chan *Inbox)
+	go func() {
+		inbox, _ := pool.Acquire(context.Background())
+		done <- inbox
+	}()
+	if err := pool.Release(first); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case inbox := <-done:
+		if inbox == nil || inbox.ID == first.ID {
+			t.Errorf("Ожидался новый ящик, получено: %+v", inbox)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Acquire не дождался нового ящика")
+	}
+	if stats := pool.Stats(); stats.Deleted != 1 || stats.InUse != 2 {
+		t.Errorf("Неверная статистика: %+v", stats)
+	}
+}
+
+// TestInboxPoolQuota проверяет паузу пополнения при ошибке квоты
+func TestInboxPoolQuota(t *testing.T) {
+	mock := mailslurptest.NewMockClient()
+	mock.FailOn("CreateInboxWithOptions", 2, ErrPlanLimitExceeded)
+	pool := NewInboxPool(mock, InboxPoolOptions{Size: 2, RetryBackoff: 20 * time.Millisecond})
+	defer pool.Close()
+
+	stats := waitForPool(t, pool, func(s InboxPoolStats) bool { return s.CreateErrors == 1 })
+	if !stats.QuotaExhausted || stats.LastError == "" {
+		t.Errorf("Ошибка квоты не отражена в статистике: %+v", stats)
+	}
+
+	stats = waitForPool(t, pool, func(s InboxPoolStats) bool { return s.Ready == 2 })
+	if stats.QuotaExhausted || stats.Created != 2 {
+		t.Errorf("После паузы пул должен заполниться: %+v", stats)
+	}
+}
+
+// TestInboxPoolDefaultClient проверяет очистку ящиков через API на фейковом сервере
+func TestInboxPoolDefaultClient(t *testing.T) {
+	server := newFakeMailSlurpServer(t)
+	client := server.client()
+	pool := NewInboxPool(client, InboxPoolOptions{Size: 1, MaxInboxes: 1, Reuse: true})
+	defer pool.Close()
+
+	inbox, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.deliver(inbox.ID, "app@example.com", "Тема", "Тело")
+	if err := pool.Release(inbox); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != inbox.ID {
+		t.Errorf("Ожидался тот же ящик после очистки")
+	}
+	if emails, _ := client.GetEmails(again.ID); len(emails) != 0 {
+		t.Errorf("Ящик не очищен: %d писем", len(emails))
+	}
+}
@@ -0,0 +1,89 @@
+package mailslurp
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter ограничивает частоту запросов по алгоритму token bucket
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // токенов в секунду
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewRateLimiter создает ограничитель на perSecond запросов в секунду
+// с возможностью кратковременно выполнить до burst запросов подряд
+func NewRateLimiter(perSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// Wait ждет, пока можно будет выполнить запрос, или отмены ctx
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		delay := l.reserve()
+		if delay <= 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// reserve забирает токен, если он есть, иначе возвращает время до появления токена
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate <= 0 {
+		return 0
+	}
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// WithRateLimit ограничивает частоту запросов клиента. Ограничение действует
+// на каждую попытку, включая повторы RetryMiddleware, и общее для всех
+// горутин, использующих клиент.
+func WithRateLimit(limiter *RateLimiter) ClientOption {
+	return func(c *DefaultMailSlurpClient) {
+		c.limiter = limiter
+	}
+}
+
+// rateLimit - middleware, который ждет разрешения ограничителя перед запросом
+func rateLimit(limiter *RateLimiter) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := limiter.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package mailslurp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Шаги сценария регистрации, см. RegistrationError.Step
+const (
+	RegistrationStepCreateInbox  = "create_inbox"
+	RegistrationStepRegister     = "register"
+	RegistrationStepWaitForEmail = "wait_for_email"
+	RegistrationStepExtractLink  = "extract_link"
+	RegistrationStepConfirm      = "confirm"
+	RegistrationStepDeleteInbox  = "delete_inbox"
+)
+
+// defaultRegistrationTimeout - ожидание письма с подтверждением по умолчанию
+const defaultRegistrationTimeout = 60 * time.Second
+
+// ErrUnexpectedStatus возвращается, если приложение ответило ошибкой HTTP
+var ErrUnexpectedStatus = errors.New("неожиданный статус ответа")
+
+// RegisterFunc регистрирует пользователя identity в тестируемом приложении.
+// client хранит cookie и используется затем для перехода по ссылке
+// подтверждения, поэтому сессия регистрации сохраняется.
+type RegisterFunc func(ctx context.Context, client *http.Client, identity *Identity) error
+
+// FormRegistration возвращает RegisterFunc, которая отправляет POST форму
+// на formURL. В значениях полей подставляются данные пользователя:
+// {email}, {password}, {login}, {first_name}, {last_name}, {full_name}.
+// Ответ со статусом 400 и выше считается ошибкой.
+func FormRegistration(formURL string, fields map[string]string) RegisterFunc {
+	return func(ctx context.Context, client *http.Client, identity *Identity) error {
+		replacer := strings.NewReplacer(
+			"{email}", identity.EmailAddress,
+			"{password}", identity.Password,
+			"{login}", identity.Login,
+			"{first_name}", identity.FirstName,
+			"{last_name}", identity.LastName,
+			"{full_name}", identity.FullName(),
+		)
+		form := url.Values{}
+		for name, value := range fields {
+			form.Set(name, replacer.Replace(value))
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, formURL, strings.NewReader(form.Encode()))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, resp.Body)
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("%w: POST %s: %s", ErrUnexpectedStatus, formURL, resp.Status)
+		}
+		return nil
+	}
+}
+
+// RegistrationFlow описывает сценарий регистрации: создать ящик и данные
+// пользователя, зарегистрироваться, дождаться письма, перейти по ссылке
+// подтверждения и удалить ящик.
+type RegistrationFlow struct {
+	// Client - клиент MailSlurp
+	Client MailSlurpClient
+	// Register - шаг регистрации, например FormRegistration
+	Register RegisterFunc
+	// Generator создает данные пользователя, по умолчанию LocaleEN со случайным seed
+	Generator *IdentityGenerator
+	// Inbox - параметры создаваемого ящика
+	Inbox CreateInboxOptions
+	// Matchers - условия для письма с подтверждением; без условий подходит любое
+	Matchers []EmailMatcher
+	// Timeout - ожидание письма, по умолчанию 60s
+	Timeout time.Duration
+	// LinkHost - домен ссылки подтверждения; пустой - первая ссылка в письме
+	LinkHost string
+	// HTTPClient - клиент для регистрации и подтверждения. Если у него нет
+	// cookie jar, используется копия с новым jar.
+	HTTPClient *http.Client
+	// KeepInbox - не удалять ящик после сценария
+	KeepInbox bool
+}
+
+// RegistrationTimings - длительность шагов сценария
+type RegistrationTimings struct {
+	CreateInbox  time.Duration `json:"createInbox"`
+	Register     time.Duration `json:"register"`
+	WaitForEmail time.Duration `json:"waitForEmail"`
+	Confirm      time.Duration `json:"confirm"`
+	Total        time.Duration `json:"total"`
+}
+
+// RegistrationResult - результат сценария. При ошибке заполнены поля
+// шагов, которые успели выполниться.
+type RegistrationResult struct {
+	Identity         *Identity `json:"identity,omitempty"`
+	Email            *Email    `json:"email,omitempty"`
+	ConfirmationLink string    `json:"confirmationLink,omitempty"`
+	// ConfirmedURL - адрес после всех редиректов ссылки подтверждения
+	ConfirmedURL  string              `json:"confirmedUrl,omitempty"`
+	ConfirmStatus int                 `json:"confirmStatus,omitempty"`
+	InboxDeleted  bool                `json:"inboxDeleted"`
+	Timings       RegistrationTimings `json:"timings"`
+}
+
+// RegistrationError - ошибка шага сценария регистрации
+type RegistrationError struct {
+	Step string
+	Err  error
+}
+
+func (e *RegistrationError) Error() string {
+	return fmt.Sprintf("регистрация: шаг %s: %v", e.Step, e.Err)
+}
+
+func (e *RegistrationError) Unwrap() error {
+	return e.Err
+}
+
+// Run выполняет сценарий регистрации. Ошибка шага возвращается как
+// *RegistrationError вместе с частично заполненным результатом. Ящик
+// удаляется и при ошибке, если не задан KeepInbox.
+func (f *RegistrationFlow) Run(ctx context.Context) (result *RegistrationResult, err error) {
+	if f.Register == nil {
+		return nil, fmt.Errorf("регистрация: не задан шаг Register")
+	}
+	client := ClientWithContext(ctx, f.Client)
+	httpClient := withCookieJar(f.HTTPClient)
+	generator := f.Generator
+	if generator == nil {
+		generator = NewIdentityGenerator(LocaleEN, 0)
+	}
+	timeout := f.Timeout
+	if timeout <= 0 {
+		timeout = defaultRegistrationTimeout
+	}
+
+	result = &RegistrationResult{}
+	start := time.Now()
+	defer func() {
+		result.Timings.Total = time.Since(start)
+	}()
+	step := func(name string, elapsed *time.Duration, run func() error) error {
+		stepStart := time.Now()
+		err := ctx.Err()
+		if err == nil {
+			err = run()
+		}
+		if elapsed != nil {
+			*elapsed = time.Since(stepStart)
+		}
+		if err != nil {
+			return &RegistrationError{Step: name, Err: err}
+		}
+		return nil
+	}
+
+	err = step(RegistrationStepCreateInbox, &result.Timings.CreateInbox, func() error {
+		identity, err := generator.IdentityWithInbox(client, f.Inbox)
+		result.Identity = identity
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+	if !f.KeepInbox {
+		defer func() {
+			// Ящик удаляется и после отмены ctx, поэтому используется исходный клиент
+			if delErr := f.Client.DeleteInbox(result.Identity.InboxID); delErr != nil && !errors.Is(delErr, ErrNotFound) {
+				if err == nil {
+					err = &RegistrationError{Step: RegistrationStepDeleteInbox, Err: delErr}
+				}
+				return
+			}
+			result.InboxDeleted = true
+		}()
+	}
+
+	err = step(RegistrationStepRegister, &result.Timings.Register, func() error {
+		return f.Register(ctx, httpClient, result.Identity)
+	})
+	if err != nil {
+		return result, err
+	}
+
+	err = step(RegistrationStepWaitForEmail, &result.Timings.WaitForEmail, func() error {
+		email, err := WaitForMatchingEmail(client, result.Identity.InboxID, timeout, f.Matchers...)
+		result.Email = email
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	err = step(RegistrationStepExtractLink, nil, func() error {
+		link, err := ExtractLink(result.Email, f.LinkHost)
+		result.ConfirmationLink = link
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	err = step(RegistrationStepConfirm, &result.Timings.Confirm, func() error {
+		return f.confirm(ctx, httpClient, result)
+	})
+	return result, err
+}
+
+// confirm переходит по ссылке подтверждения с cookie сессии регистрации
+func (f *RegistrationFlow) confirm(ctx context.Context, client *http.Client, result *RegistrationResult) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, result.ConfirmationLink, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	result.ConfirmStatus = resp.StatusCode
+	result.ConfirmedURL = resp.Request.URL.String()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%w: GET %s: %s", ErrUnexpectedStatus, result.ConfirmationLink, resp.Status)
+	}
+	return nil
+}
+
+// withCookieJar возвращает client, если у него есть cookie jar, иначе его
+// копию с новым jar; nil заменяется клиентом с таймаутом 30s
+func withCookieJar(client *http.Client) *http.Client {
+	if client != nil && client.Jar != nil {
+		return client
+	}
+	cp := &http.Client{Timeout: 30 * time.Second}
+	if client != nil {
+		*cp = *client
+	}
+	// cookiejar.New без PublicSuffixList не возвращает ошибок
+	cp.Jar, _ = cookiejar.New(nil)
+	return cp
+}
@@ -0,0 +1,185 @@
+package mailslurp_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	. "mailslurp-example"
+	"mailslurp-example/mailslurptest"
+)
+
+// registrationApp - тестовое приложение: /register создает сессию и
+// отправляет письмо со ссылкой, /confirm требует cookie той же сессии
+type registrationApp struct {
+	*httptest.Server
+	mock      *mailslurptest.MockClient
+	sendEmail bool
+
+	mu        sync.Mutex
+	passwords map[string]string
+	confirmed map[string]bool
+}
+
+func newRegistrationApp(t *testing.T, mock *mailslurptest.MockClient) *registrationApp {
+	app := &registrationApp{mock: mock, sendEmail: true, passwords: map[string]string{}, confirmed: map[string]bool{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/register", func(w http.ResponseWriter, r *http.Request) {
+		email := r.FormValue("email")
+		if r.Method != http.MethodPost || email == "" || r.FormValue("password") == "" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		app.mu.Lock()
+		app.passwords[email] = r.FormValue("password")
+		app.mu.Unlock()
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: email, Path: "/"})
+		if !app.sendEmail {
+			return
+		}
+
+		inboxes, _ := mock.GetInboxes()
+		for _, inbox := range inboxes {
+			if inbox.EmailAddress == email {
+				mock.DeliverMessage(inbox.ID, "noreply@example.com", "Подтвердите регистрацию",
+					fmt.Sprintf("Здравствуйте, %s! Перейдите по ссылке: %s/confirm?user=%s", r.FormValue("name"), app.URL, email))
+			}
+		}
+	})
+	mux.HandleFunc("/confirm", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if err != nil || cookie.Value != r.URL.Query().Get("user") {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		app.mu.Lock()
+		app.confirmed[cookie.Value] = true
+		app.mu.Unlock()
+		http.Redirect(w, r, "/welcome", http.StatusFound)
+	})
+	mux.HandleFunc("/welcome", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "Добро пожаловать")
+	})
+	app.Server = httptest.NewServer(mux)
+	t.Cleanup(app.Close)
+	return app
+}
+
+func (a *registrationApp) flow() *RegistrationFlow {
+	return &RegistrationFlow{
+		Client: a.mock,
+		Register: FormRegistration(a.URL+"/register", map[string]string{
+			"email":    "{email}",
+			"password": "{password}",
+			"name":     "{full_name}",
+		}),
+		Generator: NewIdentityGenerator(LocaleEN, 11),
+		Matchers:  []EmailMatcher{SubjectContains("подтвердите")},
+		Timeout:   time.Second,
+		LinkHost:  "127.0.0.1",
+	}
+}
+
+// TestRegistrationFlow проверяет полный сценарий регистрации
+func TestRegistrationFlow(t *testing.T) {
+	mock := mailslurptest.NewMockClient()
+	app := newRegistrationApp(t, mock)
+
+	result, err := app.flow().Run(context.Background())
+	if err != nil {
+		t.Fatalf("Сценарий завершился ошибкой: %v", err)
+	}
+	identity := result.Identity
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if !app.confirmed[identity.EmailAddress] {
+		t.Error("Регистрация не подтверждена")
+	}
+	if app.passwords[identity.EmailAddress] != identity.Password {
+		t.Error("Приложение получило неверный пароль")
+	}
+	if result.ConfirmStatus != http.StatusOK || result.ConfirmedURL != app.URL+"/welcome" {
+		t.Errorf("Неверный результат подтверждения: %d %s", result.ConfirmStatus, result.ConfirmedURL)
+	}
+	if result.ConfirmationLink != app.URL+"/confirm?user="+identity.EmailAddress {
+		t.Errorf("Неверная ссылка: %s", result.ConfirmationLink)
+	}
+	if !result.InboxDeleted || mock.CallCount("DeleteInbox") != 1 {
+		t.Error("Ящик должен быть удален")
+	}
+	timings := result.Timings
+	if timings.Total <= 0 || timings.Total < timings.CreateInbox+timings.Register+timings.WaitForEmail+timings.Confirm {
+		t.Errorf("Неверные длительности шагов: %+v", timings)
+	}
+}
+
+// TestRegistrationFlowErrors проверяет ошибки шагов и удаление ящика
+func TestRegistrationFlowErrors(t *testing.T) {
+	t.Run("регистрация отклонена", func(t *testing.T) {
+		mock := mailslurptest.NewMockClient()
+		app := newRegistrationApp(t, mock)
+		flow := app.flow()
+		flow.Register = FormRegistration(app.URL+"/register", map[string]string{"email": "{email}"})
+
+		result, err := flow.Run(context.Background())
+		var stepErr *RegistrationError
+		if !errors.As(err, &stepErr) || stepErr.Step != RegistrationStepRegister || !errors.Is(err, ErrUnexpectedStatus) {
+			t.Fatalf("Ожидалась ошибка шага register, получено: %v", err)
+		}
+		if !result.InboxDeleted || result.Identity == nil {
+			t.Errorf("Ящик должен быть удален и после ошибки: %+v", result)
+		}
+	})
+
+	t.Run("письмо не пришло", func(t *testing.T) {
+		mock := mailslurptest.NewMockClient()
+		app := newRegistrationApp(t, mock)
+		app.sendEmail = false
+		flow := app.flow()
+		flow.Timeout = 50 * time.Millisecond
+		flow.KeepInbox = true
+
+		result, err := flow.Run(context.Background())
+		if !errors.Is(err, ErrEmailTimeout) {
+			t.Fatalf("Ожидалась ErrEmailTimeout, получено: %v", err)
+		}
+		if result.InboxDeleted || mock.CallCount("DeleteInbox") != 0 {
+			t.Error("С KeepInbox ящик не должен удаляться")
+		}
+	})
+
+	t.Run("чужая cookie", func(t *testing.T) {
+		mock := mailslurptest.NewMockClient()
+		app := newRegistrationApp(t, mock)
+		flow := app.flow()
+		// Регистрация без cookie jar: подтверждение выполняется в другой сессии
+		register := flow.Register
+		flow.Register = func(ctx context.Context, _ *http.Client, identity *Identity) error {
+			return register(ctx, &http.Client{}, identity)
+		}
+
+		result, err := flow.Run(context.Background())
+		var stepErr *RegistrationError
+		if !errors.As(err, &stepErr) || stepErr.Step != RegistrationStepConfirm {
+			t.Fatalf("Ожидалась ошибка шага confirm, получено: %v", err)
+		}
+		if result.ConfirmStatus != http.StatusForbidden {
+			t.Errorf("Неверный статус: %d", result.ConfirmStatus)
+		}
+	})
+
+	t.Run("отмена контекста", func(t *testing.T) {
+		mock := mailslurptest.NewMockClient()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := newRegistrationApp(t, mock).flow().Run(ctx)
+		if !errors.Is(err, context.Canceled) || mock.CallCount("CreateInboxWithOptions") != 0 {
+			t.Errorf("Ожидалась context.Canceled до создания ящика, получено: %v", err)
+		}
+	})
+}
@@ -0,0 +1,892 @@
+package mailslurp
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUndefinedVariable - в сценарии используется неизвестная переменная
+	ErrUndefinedVariable = errors.New("переменная не определена")
+	// ErrAssertionFailed - не выполнена проверка assert
+	ErrAssertionFailed = errors.New("проверка не выполнена")
+)
+
+// Параметры выполнения сценария по умолчанию
+const (
+	defaultScenarioTimeout = 30 * time.Second
+	maxScenarioResponse    = 1 << 20
+)
+
+// scenarioVarPattern находит подстановки ${name} и ${env.NAME}
+var scenarioVarPattern = regexp.MustCompile(`\$\{([A-Za-z0-9_.-]+)\}`)
+
+// Scenario - сценарий проверки писем, описанный в YAML:
+//
+//	name: Подтверждение регистрации
+//	vars:
+//	  app: http://localhost:8080
+//	steps:
+//	  - create_inbox: {as: user}
+//	  - http_request:
+//	      url: ${app}/register
+//	      form: {email: "${user.email}"}
+//	  - wait_for_email: {inbox: "${user.id}", subject: Подтвердите}
+//	  - extract: {link: localhost, as: link}
+//	  - http_request: {url: "${link}", expect_status: 200}
+//
+// Созданные ящики удаляются после сценария, если не задан keep_inboxes.
+// Файл разбирается через ParseYAML: поддерживается только описанное там
+// подмножество YAML, без якорей, тегов и нескольких документов. Значения с
+// ": " внутри нужно брать в кавычки, например subject: "Re: заказ".
+type Scenario struct {
+	Name string `json:"name"`
+	// File - путь к файлу, из которого загружен сценарий
+	File string `json:"file,omitempty"`
+	// Vars - начальные значения переменных
+	Vars map[string]string `json:"vars,omitempty"`
+	// Timeout - ожидание писем по умолчанию, 30s
+	Timeout     time.Duration  `json:"timeout,omitempty"`
+	KeepInboxes bool           `json:"keepInboxes,omitempty"`
+	Steps       []ScenarioStep `json:"steps"`
+}
+
+// ScenarioStep - шаг сценария: действие и его параметры. Строковые
+// параметры могут содержать ${переменные}.
+type ScenarioStep struct {
+	Name   string                 `json:"name"`
+	Action string                 `json:"action"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// scenarioAction описывает действие шага: допустимые параметры и выполнение
+type scenarioAction struct {
+	params []string
+	run    func(r *scenarioRun, p scenarioParams) error
+}
+
+var scenarioActions map[string]scenarioAction
+
+func init() {
+	// Таблица заполняется в init, так как действия ссылаются на scenarioRun
+	scenarioActions = map[string]scenarioAction{
+		"create_inbox":   {[]string{"as", "name", "description", "tags"}, (*scenarioRun).createInbox},
+		"send":           {[]string{"inbox", "to", "subject", "body", "markdown"}, (*scenarioRun).send},
+		"http_request":   {[]string{"as", "method", "url", "headers", "form", "body", "expect_status"}, (*scenarioRun).httpRequest},
+		"wait_for_email": {[]string{"as", "inbox", "timeout", "subject", "from", "body"}, (*scenarioRun).waitForEmail},
+		"extract":        {[]string{"as", "email", "text", "link", "code", "regex"}, (*scenarioRun).extract},
+		"assert":         {[]string{"value", "equals", "not_equals", "contains", "not_contains", "matches", "message"}, (*scenarioRun).assert},
+		"delete_inbox":   {[]string{"inbox"}, (*scenarioRun).deleteInbox},
+	}
+}
+
+// LoadScenario читает сценарий из YAML файла
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать сценарий: %v", err)
+	}
+	scenario, err := ParseScenario(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	scenario.File = path
+	return scenario, nil
+}
+
+// ParseScenario разбирает сценарий из YAML и проверяет действия и их параметры
+func ParseScenario(data []byte) (*Scenario, error) {
+	doc, err := ParseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("сценарий должен быть словарем с ключом steps")
+	}
+	if err := checkScenarioKeys("сценарий", root, []string{"name", "vars", "timeout", "keep_inboxes", "steps"}); err != nil {
+		return nil, err
+	}
+
+	scenario := &Scenario{Name: scenarioString(root["name"]), Vars: map[string]string{}}
+	if scenario.Name == "" {
+		scenario.Name = "scenario"
+	}
+	if vars, err := scenarioParams(root).stringMap("vars"); err != nil {
+		return nil, err
+	} else if vars != nil {
+		scenario.Vars = vars
+	}
+	if scenario.Timeout, err = scenarioParams(root).duration("timeout", 0); err != nil {
+		return nil, err
+	}
+	if keep, ok := root["keep_inboxes"].(bool); ok {
+		scenario.KeepInboxes = keep
+	}
+
+	steps, ok := root["steps"].([]interface{})
+	if !ok || len(steps) == 0 {
+		return nil, fmt.Errorf("в сценарии нет шагов steps")
+	}
+	for i, item := range steps {
+		step, err := parseScenarioStep(i+1, item)
+		if err != nil {
+			return nil, err
+		}
+		scenario.Steps = append(scenario.Steps, step)
+	}
+	return scenario, nil
+}
+
+// parseScenarioStep разбирает шаг вида {действие: {параметры}, name: имя}
+func parseScenarioStep(n int, item interface{}) (ScenarioStep, error) {
+	fields, ok := item.(map[string]interface{})
+	if !ok {
+		return ScenarioStep{}, fmt.Errorf("шаг %d: ожидался словарь с действием", n)
+	}
+	step := ScenarioStep{Name: scenarioString(fields["name"])}
+	for key, value := range fields {
+		if key == "name" {
+			continue
+		}
+		if step.Action != "" {
+			return step, fmt.Errorf("шаг %d: несколько действий: %s и %s", n, step.Action, key)
+		}
+		action, ok := scenarioActions[key]
+		if !ok {
+			return step, fmt.Errorf("шаг %d: неизвестное действие %s", n, key)
+		}
+		step.Action = key
+		switch params := value.(type) {
+		case nil:
+			step.Params = map[string]interface{}{}
+		case map[string]interface{}:
+			step.Params = params
+		default:
+			return step, fmt.Errorf("шаг %d: параметры %s должны быть словарем", n, key)
+		}
+		if err := checkScenarioKeys(fmt.Sprintf("шаг %d (%s)", n, key), step.Params, action.params); err != nil {
+			return step, err
+		}
+	}
+	if step.Action == "" {
+		return step, fmt.Errorf("шаг %d: не указано действие", n)
+	}
+	if step.Name == "" {
+		step.Name = fmt.Sprintf("%d. %s", n, step.Action)
+	}
+	return step, nil
+}
+
+func checkScenarioKeys(where string, m map[string]interface{}, allowed []string) error {
+	for key := range m {
+		if !containsString(allowed, key) {
+			return fmt.Errorf("%s: неизвестный параметр %s (допустимы: %s)", where, key, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ScenarioStepStatus - итог шага сценария
+type ScenarioStepStatus string
+
+const (
+	ScenarioStepPassed  ScenarioStepStatus = "passed"
+	ScenarioStepFailed  ScenarioStepStatus = "failed"
+	ScenarioStepSkipped ScenarioStepStatus = "skipped"
+)
+
+// ScenarioStepResult - результат шага
+type ScenarioStepResult struct {
+	Name     string             `json:"name"`
+	Action   string             `json:"action"`
+	Status   ScenarioStepStatus `json:"status"`
+	Duration time.Duration      `json:"duration"`
+	Error    string             `json:"error,omitempty"`
+}
+
+// ScenarioResult - результат сценария. После первой ошибки остальные шаги
+// пропускаются; ошибки удаления ящиков добавляются шагом cleanup.
+type ScenarioResult struct {
+	Name     string               `json:"name"`
+	File     string               `json:"file,omitempty"`
+	Passed   bool                 `json:"passed"`
+	Started  time.Time            `json:"started"`
+	Duration time.Duration        `json:"duration"`
+	Steps    []ScenarioStepResult `json:"steps"`
+}
+
+// Count возвращает количество шагов с указанным статусом
+func (r *ScenarioResult) Count(status ScenarioStepStatus) int {
+	n := 0
+	for _, step := range r.Steps {
+		if step.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// ScenarioRunner выполняет сценарии через MailSlurpClient
+type ScenarioRunner struct {
+	Client MailSlurpClient
+	// HTTPClient - клиент для http_request; cookie сохраняются в пределах
+	// одного сценария
+	HTTPClient *http.Client
+	// Vars переопределяют переменные сценария, например из --var
+	Vars map[string]string
+}
+
+// scenarioRun - состояние выполняемого сценария
+type scenarioRun struct {
+	ctx      context.Context
+	client   MailSlurpClient
+	http     *http.Client
+	timeout  time.Duration
+	vars     map[string]string
+	emails   map[string]*Email
+	inboxIDs []string
+}
+
+// Run выполняет сценарий и возвращает результат по каждому шагу
+func (r *ScenarioRunner) Run(ctx context.Context, scenario *Scenario) *ScenarioResult {
+	result := &ScenarioResult{Name: scenario.Name, File: scenario.File, Started: time.Now()}
+	run := &scenarioRun{
+		ctx:     ctx,
+		client:  ClientWithContext(ctx, r.Client),
+		timeout: scenario.Timeout,
+		vars:    map[string]string{},
+		emails:  map[string]*Email{},
+	}
+	// Для каждого сценария свои cookie
+	var httpClient *http.Client
+	if r.HTTPClient != nil {
+		cp := *r.HTTPClient
+		cp.Jar = nil
+		httpClient = &cp
+	}
+	run.http = withCookieJar(httpClient)
+	if run.timeout <= 0 {
+		run.timeout = defaultScenarioTimeout
+	}
+	for name, value := range scenario.Vars {
+		run.vars[name] = value
+	}
+	for name, value := range r.Vars {
+		run.vars[name] = value
+	}
+
+	failed := false
+	for _, step := range scenario.Steps {
+		stepResult := ScenarioStepResult{Name: step.Name, Action: step.Action, Status: ScenarioStepSkipped}
+		if !failed {
+			start := time.Now()
+			err := ctx.Err()
+			if err == nil {
+				err = run.runStep(step)
+			}
+			stepResult.Duration = time.Since(start)
+			stepResult.Status = ScenarioStepPassed
+			if err != nil {
+				stepResult.Status = ScenarioStepFailed
+				stepResult.Error = err.Error()
+				failed = true
+			}
+		}
+		result.Steps = append(result.Steps, stepResult)
+	}
+
+	if !scenario.KeepInboxes && len(run.inboxIDs) > 0 {
+		// Ящики удаляются и после отмены ctx, поэтому используется исходный клиент
+		start := time.Now()
+		var errs []string
+		for _, id := range run.inboxIDs {
+			if err := r.Client.DeleteInbox(id); err != nil && !errors.Is(err, ErrNotFound) {
+				errs = append(errs, fmt.Sprintf("%s: %v", id, err))
+			}
+		}
+		if len(errs) > 0 {
+			result.Steps = append(result.Steps, ScenarioStepResult{
+				Name:     "cleanup",
+				Action:   "delete_inbox",
+				Status:   ScenarioStepFailed,
+				Duration: time.Since(start),
+				Error:    "не удалось удалить ящики: " + strings.Join(errs, "; "),
+			})
+		}
+	}
+
+	result.Duration = time.Since(result.Started)
+	result.Passed = result.Count(ScenarioStepFailed) == 0
+	return result
+}
+
+func (r *scenarioRun) runStep(step ScenarioStep) error {
+	// Сценарий может быть собран в коде, минуя ParseScenario
+	action, ok := scenarioActions[step.Action]
+	if !ok {
+		if step.Action == "" {
+			return fmt.Errorf("не указано действие")
+		}
+		return fmt.Errorf("неизвестное действие %s", step.Action)
+	}
+	if err := checkScenarioKeys(step.Action, step.Params, action.params); err != nil {
+		return err
+	}
+	params, err := r.interpolate(step.Params)
+	if err != nil {
+		return err
+	}
+	return action.run(r, params.(map[string]interface{}))
+}
+
+// interpolate подставляет переменные во все строки значения
+func (r *scenarioRun) interpolate(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		var missing []string
+		s := scenarioVarPattern.ReplaceAllStringFunc(v, func(m string) string {
+			name := m[2 : len(m)-1]
+			if strings.HasPrefix(name, "env.") {
+				if value, ok := os.LookupEnv(strings.TrimPrefix(name, "env.")); ok {
+					return value
+				}
+			} else if value, ok := r.vars[name]; ok {
+				return value
+			}
+			missing = append(missing, name)
+			return m
+		})
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrUndefinedVariable, strings.Join(missing, ", "))
+		}
+		return s, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			value, err := r.interpolate(item)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = value
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			value, err := r.interpolate(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = value
+		}
+		return out, nil
+	default:
+		return value, nil
+	}
+}
+
+// setVars сохраняет значения как переменные prefix.key
+func (r *scenarioRun) setVars(prefix string, values map[string]string) {
+	for key, value := range values {
+		r.vars[prefix+"."+key] = value
+	}
+}
+
+func (r *scenarioRun) createInbox(p scenarioParams) error {
+	tags, err := p.strings("tags")
+	if err != nil {
+		return err
+	}
+	inbox, err := r.client.CreateInboxWithOptions(CreateInboxOptions{
+		Name:        p.str("name"),
+		Description: p.str("description"),
+		Tags:        tags,
+	})
+	if err != nil {
+		return err
+	}
+	r.inboxIDs = append(r.inboxIDs, inbox.ID)
+	r.setVars(p.strDefault("as", "inbox"), map[string]string{
+		"id":    inbox.ID,
+		"email": inbox.EmailAddress,
+		"name":  inbox.Name,
+	})
+	return nil
+}
+
+func (r *scenarioRun) send(p scenarioParams) error {
+	inboxID, err := p.require("inbox")
+	if err != nil {
+		return err
+	}
+	to, err := p.require("to")
+	if err != nil {
+		return err
+	}
+	if markdown := p.str("markdown"); markdown != "" {
+		return SendSingleBody(r.client, inboxID, to, ComposeMarkdown(p.str("subject"), markdown))
+	}
+	return r.client.SendEmail(inboxID, to, p.str("subject"), p.str("body"))
+}
+
+func (r *scenarioRun) httpRequest(p scenarioParams) error {
+	rawURL, err := p.require("url")
+	if err != nil {
+		return err
+	}
+	headers, err := p.stringMap("headers")
+	if err != nil {
+		return err
+	}
+	form, err := p.stringMap("form")
+	if err != nil {
+		return err
+	}
+	expect, err := p.int("expect_status")
+	if err != nil {
+		return err
+	}
+
+	method := strings.ToUpper(p.str("method"))
+	var body io.Reader
+	switch {
+	case form != nil:
+		values := url.Values{}
+		for key, value := range form {
+			values.Set(key, value)
+		}
+		body = strings.NewReader(values.Encode())
+	case p.str("body") != "":
+		body = strings.NewReader(p.str("body"))
+	}
+	if method == "" {
+		method = http.MethodGet
+		if body != nil {
+			method = http.MethodPost
+		}
+	}
+
+	req, err := http.NewRequestWithContext(r.ctx, method, rawURL, body)
+	if err != nil {
+		return err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxScenarioResponse))
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать ответ: %v", err)
+	}
+
+	r.setVars(p.strDefault("as", "response"), map[string]string{
+		"status": fmt.Sprint(resp.StatusCode),
+		"body":   string(data),
+		"url":    resp.Request.URL.String(),
+	})
+	switch {
+	case expect != 0 && resp.StatusCode != expect:
+		return fmt.Errorf("%w: %s %s: %s, ожидался %d", ErrUnexpectedStatus, method, rawURL, resp.Status, expect)
+	case expect == 0 && resp.StatusCode >= http.StatusBadRequest:
+		return fmt.Errorf("%w: %s %s: %s", ErrUnexpectedStatus, method, rawURL, resp.Status)
+	}
+	return nil
+}
+
+func (r *scenarioRun) waitForEmail(p scenarioParams) error {
+	inboxID, err := p.require("inbox")
+	if err != nil {
+		return err
+	}
+	timeout, err := p.duration("timeout", r.timeout)
+	if err != nil {
+		return err
+	}
+	var matchers []EmailMatcher
+	if s := p.str("subject"); s != "" {
+		matchers = append(matchers, SubjectContains(s))
+	}
+	if s := p.str("from"); s != "" {
+		matchers = append(matchers, FromContains(s))
+	}
+	if s := p.str("body"); s != "" {
+		matchers = append(matchers, BodyContains(s))
+	}
+
+	email, err := WaitForMatchingEmail(r.client, inboxID, timeout, matchers...)
+	if err != nil {
+		return err
+	}
+	name := p.strDefault("as", "email")
+	r.emails[name] = email
+	r.setVars(name, map[string]string{
+		"id":      email.ID,
+		"subject": email.Subject,
+		"from":    email.From,
+		"to":      strings.Join(email.To, ", "),
+		"body":    email.Body,
+		"text":    email.TextBody(),
+	})
+	return nil
+}
+
+// extract извлекает ссылку, код или совпадение регулярного выражения из
+// письма (параметр email, по умолчанию email) или из текста text
+func (r *scenarioRun) extract(p scenarioParams) error {
+	name, err := p.require("as")
+	if err != nil {
+		return err
+	}
+	source := &Email{Body: p.str("text")}
+	if _, ok := p["text"]; !ok {
+		emailName := p.strDefault("email", "email")
+		if source = r.emails[emailName]; source == nil {
+			return fmt.Errorf("%w: письмо %s не получено", ErrUndefinedVariable, emailName)
+		}
+	}
+
+	var value string
+	switch {
+	case p["link"] != nil:
+		host := ""
+		if s, ok := p["link"].(string); ok {
+			host = s
+		}
+		value, err = ExtractLink(source, host)
+	case p["code"] != nil:
+		length, _ := p["code"].(int)
+		value, err = ExtractCode(source, length)
+	case p["regex"] != nil:
+		value, err = extractRegex(source, p.str("regex"))
+	default:
+		return fmt.Errorf("укажите link, code или regex")
+	}
+	if err != nil {
+		return err
+	}
+	r.vars[name] = value
+	return nil
+}
+
+// extractRegex возвращает первую группу или все совпадение из текста письма
+// (HTML преобразуется через TextBody) или из темы
+func extractRegex(email *Email, pattern string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("некорректное регулярное выражение: %v", err)
+	}
+	for _, text := range []string{email.TextBody(), email.Subject} {
+		if m := re.FindStringSubmatch(text); m != nil {
+			if len(m) > 1 {
+				return m[1], nil
+			}
+			return m[0], nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrNothingExtracted, pattern)
+}
+
+func (r *scenarioRun) assert(p scenarioParams) error {
+	if _, ok := p["value"]; !ok {
+		return fmt.Errorf("не указан параметр value")
+	}
+	value := p.str("value")
+	checks := 0
+	fail := func(format string, args ...interface{}) error {
+		msg := fmt.Sprintf(format, args...)
+		if m := p.str("message"); m != "" {
+			msg = m + ": " + msg
+		}
+		return fmt.Errorf("%w: %s", ErrAssertionFailed, msg)
+	}
+
+	for _, key := range []string{"equals", "not_equals", "contains", "not_contains", "matches"} {
+		if _, ok := p[key]; !ok {
+			continue
+		}
+		checks++
+		expected := p.str(key)
+		switch key {
+		case "equals":
+			if value != expected {
+				return fail("%q не равно %q", value, expected)
+			}
+		case "not_equals":
+			if value == expected {
+				return fail("значение равно %q", expected)
+			}
+		case "contains":
+			if !strings.Contains(value, expected) {
+				return fail("%q не содержит %q", value, expected)
+			}
+		case "not_contains":
+			if strings.Contains(value, expected) {
+				return fail("%q содержит %q", value, expected)
+			}
+		case "matches":
+			re, err := regexp.Compile(expected)
+			if err != nil {
+				return fmt.Errorf("некорректное регулярное выражение: %v", err)
+			}
+			if !re.MatchString(value) {
+				return fail("%q не соответствует %s", value, expected)
+			}
+		}
+	}
+	if checks == 0 {
+		return fmt.Errorf("укажите equals, not_equals, contains, not_contains или matches")
+	}
+	return nil
+}
+
+func (r *scenarioRun) deleteInbox(p scenarioParams) error {
+	inboxID, err := p.require("inbox")
+	if err != nil {
+		return err
+	}
+	if err := r.client.DeleteInbox(inboxID); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	for i, id := range r.inboxIDs {
+		if id == inboxID {
+			r.inboxIDs = append(r.inboxIDs[:i], r.inboxIDs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// scenarioParams - параметры шага после подстановки переменных
+type scenarioParams map[string]interface{}
+
+func (p scenarioParams) str(key string) string {
+	return scenarioString(p[key])
+}
+
+func (p scenarioParams) strDefault(key, def string) string {
+	if s := p.str(key); s != "" {
+		return s
+	}
+	return def
+}
+
+func (p scenarioParams) require(key string) (string, error) {
+	s := p.str(key)
+	if s == "" {
+		return "", fmt.Errorf("не указан параметр %s", key)
+	}
+	return s, nil
+}
+
+func (p scenarioParams) int(key string) (int, error) {
+	switch v := p[key].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return v, nil
+	default:
+		return 0, fmt.Errorf("параметр %s должен быть целым числом", key)
+	}
+}
+
+// duration принимает строку вида 30s или число секунд
+func (p scenarioParams) duration(key string, def time.Duration) (time.Duration, error) {
+	switch v := p[key].(type) {
+	case nil:
+		return def, nil
+	case int:
+		return time.Duration(v) * time.Second, nil
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("параметр %s: некорректная длительность %q", key, v)
+		}
+		return d, nil
+	default:
+		return 0, fmt.Errorf("параметр %s: некорректная длительность", key)
+	}
+}
+
+func (p scenarioParams) strings(key string) ([]string, error) {
+	switch v := p[key].(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		out := make([]string, len(v))
+		for i, item := range v {
+			out[i] = scenarioString(item)
+		}
+		return out, nil
+	case string:
+		return []string{v}, nil
+	default:
+		return nil, fmt.Errorf("параметр %s должен быть списком", key)
+	}
+}
+
+func (p scenarioParams) stringMap(key string) (map[string]string, error) {
+	switch v := p[key].(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		out := make(map[string]string, len(v))
+		for k, item := range v {
+			out[k] = scenarioString(item)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("параметр %s должен быть словарем", key)
+	}
+}
+
+// scenarioString преобразует скаляр YAML в строку; nil - пустая строка
+func scenarioString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// WriteScenarioText выводит результаты сценариев в читаемом виде
+func WriteScenarioText(w io.Writer, results []*ScenarioResult) error {
+	passed := 0
+	for _, result := range results {
+		status := "FAIL"
+		if result.Passed {
+			status = "PASS"
+			passed++
+		}
+		if _, err := fmt.Fprintf(w, "%s  %s (%s)\n", status, result.Name, result.Duration.Round(time.Millisecond)); err != nil {
+			return err
+		}
+		for _, step := range result.Steps {
+			line := fmt.Sprintf("  %-7s %s", step.Status, step.Name)
+			if step.Status != ScenarioStepSkipped {
+				line += fmt.Sprintf(" (%s)", step.Duration.Round(time.Millisecond))
+			}
+			if step.Error != "" {
+				line += ": " + step.Error
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, "Итого: сценариев %d, успешно %d, с ошибками %d\n", len(results), passed, len(results)-passed)
+	return err
+}
+
+// WriteScenarioJSON выводит результаты сценариев в формате JSON
+func WriteScenarioJSON(w io.Writer, results []*ScenarioResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+// Элементы отчета JUnit XML
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteScenarioJUnit выводит результаты в формате JUnit XML: сценарий -
+// testsuite, шаг - testcase. Формат понимают Jenkins, GitLab CI и другие.
+func WriteScenarioJUnit(w io.Writer, results []*ScenarioResult) error {
+	seconds := func(d time.Duration) string {
+		return fmt.Sprintf("%.3f", d.Seconds())
+	}
+	report := junitTestSuites{}
+	var total time.Duration
+	for _, result := range results {
+		suite := junitTestSuite{
+			Name:      result.Name,
+			Tests:     len(result.Steps),
+			Failures:  result.Count(ScenarioStepFailed),
+			Skipped:   result.Count(ScenarioStepSkipped),
+			Time:      seconds(result.Duration),
+			Timestamp: result.Started.Format("2006-01-02T15:04:05"),
+		}
+		for _, step := range result.Steps {
+			tc := junitTestCase{Name: step.Name, ClassName: result.Name, Time: seconds(step.Duration)}
+			switch step.Status {
+			case ScenarioStepFailed:
+				tc.Failure = &junitFailure{Message: step.Error, Type: step.Action, Text: step.Error}
+			case ScenarioStepSkipped:
+				tc.Skipped = &struct{}{}
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+		total += result.Duration
+		report.Suites = append(report.Suites, suite)
+	}
+	report.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
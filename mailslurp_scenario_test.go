@@ -0,0 +1,224 @@
+package mailslurp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "mailslurp-example"
+	"mailslurp-example/mailslurptest"
+)
+
+// TestScenarioRegistration выполняет сценарий из testdata против тестового приложения
+func TestScenarioRegistration(t *testing.T) {
+	scenario, err := LoadScenario(filepath.Join("testdata", "scenarios", "registration.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock := mailslurptest.NewMockClient()
+	app := newRegistrationApp(t, mock)
+
+	runner := &ScenarioRunner{Client: mock, Vars: map[string]string{"app": app.URL}}
+	result := runner.Run(context.Background(), scenario)
+	if !result.Passed {
+		var buf bytes.Buffer
+		WriteScenarioText(&buf, []*ScenarioResult{result})
+		t.Fatalf("Сценарий не пройден:\n%s", buf.String())
+	}
+	if len(result.Steps) != 7 || result.Steps[0].Name != "Создать ящик" || result.Steps[2].Name != "3. wait_for_email" {
+		t.Errorf("Неверные шаги: %+v", result.Steps)
+	}
+	app.mu.Lock()
+	confirmed := len(app.confirmed)
+	app.mu.Unlock()
+	if confirmed != 1 {
+		t.Error("Регистрация не подтверждена")
+	}
+	if mock.CallCount("DeleteInbox") != 1 {
+		t.Error("Созданный ящик должен быть удален после сценария")
+	}
+}
+
+// TestScenarioFailure проверяет пропуск шагов после ошибки и удаление ящиков
+func TestScenarioFailure(t *testing.T) {
+	scenario, err := ParseScenario([]byte(`
+name: Ошибка проверки
+steps:
+  - create_inbox: {as: sender}
+  - create_inbox: {as: receiver}
+  - send:
+      inbox: ${sender.id}
+      to: ${receiver.email}
+      subject: Код входа
+      body: "Ваш код: 482913. Токен: tok-abc"
+  - wait_for_email: {inbox: "${receiver.id}", from: "${sender.email}", timeout: 1s}
+  - extract: {code: 6, as: code}
+  - extract: {text: "${email.body}", regex: 'tok-(\w+)', as: token}
+  - delete_inbox: {inbox: "${sender.id}"}
+  - assert: {value: "${code}/${token}", equals: 482913/abc}
+  - assert: {value: "${code}", not_equals: 482913, message: код должен измениться}
+  - assert: {value: "${code}", equals: never}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock := mailslurptest.NewMockClient()
+	result := (&ScenarioRunner{Client: mock}).Run(context.Background(), scenario)
+
+	if result.Passed {
+		t.Fatal("Сценарий должен завершиться ошибкой")
+	}
+	statuses := make([]ScenarioStepStatus, len(result.Steps))
+	for i, step := range result.Steps {
+		statuses[i] = step.Status
+	}
+	if result.Count(ScenarioStepPassed) != 8 || statuses[8] != ScenarioStepFailed || statuses[9] != ScenarioStepSkipped {
+		t.Fatalf("Неверные статусы шагов: %v, %+v", statuses, result.Steps)
+	}
+	if !strings.Contains(result.Steps[8].Error, "код должен измениться") {
+		t.Errorf("Неверная ошибка: %s", result.Steps[8].Error)
+	}
+	// sender удален шагом, receiver - после сценария
+	if mock.CallCount("DeleteInbox") != 2 {
+		t.Errorf("Ожидалось 2 удаления ящиков, получено %d", mock.CallCount("DeleteInbox"))
+	}
+}
+
+// TestScenarioUndefinedVariable проверяет ошибку неизвестной переменной
+func TestScenarioUndefinedVariable(t *testing.T) {
+	scenario, err := ParseScenario([]byte("steps:\n  - assert: {value: \"${missing}\", equals: x}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SCENARIO_TEST_VALUE", "x")
+	result := (&ScenarioRunner{Client: mailslurptest.NewMockClient()}).Run(context.Background(), scenario)
+	if result.Passed || !strings.Contains(result.Steps[0].Error, ErrUndefinedVariable.Error()) {
+		t.Errorf("Ожидалась ошибка неизвестной переменной: %+v", result.Steps)
+	}
+
+	scenario, _ = ParseScenario([]byte("steps:\n  - assert: {value: \"${env.SCENARIO_TEST_VALUE}\", equals: x}\n"))
+	if result := (&ScenarioRunner{Client: mailslurptest.NewMockClient()}).Run(context.Background(), scenario); !result.Passed {
+		t.Errorf("Переменная окружения не подставлена: %+v", result.Steps)
+	}
+}
+
+// TestScenarioInvalidAction проверяет сценарий, собранный в коде, с пустым
+// или неизвестным действием: шаг завершается ошибкой, а не паникой
+func TestScenarioInvalidAction(t *testing.T) {
+	for _, step := range []ScenarioStep{
+		{Name: "пустое"},
+		{Name: "неизвестное", Action: "click"},
+		{Name: "параметр", Action: "create_inbox", Params: map[string]interface{}{"nmae": "x"}},
+	} {
+		scenario := &Scenario{Steps: []ScenarioStep{step}}
+		result := (&ScenarioRunner{Client: mailslurptest.NewMockClient()}).Run(context.Background(), scenario)
+		if result.Passed || result.Steps[0].Status != ScenarioStepFailed || result.Steps[0].Error == "" {
+			t.Errorf("%s: ожидалась ошибка шага: %+v", step.Name, result.Steps)
+		}
+	}
+}
+
+// TestParseScenarioErrors проверяет проверку структуры сценария при загрузке
+func TestParseScenarioErrors(t *testing.T) {
+	tests := map[string]string{
+		"нет шагов":             "name: x\n",
+		"неизвестное действие":  "steps:\n  - click: {}\n",
+		"неизвестный параметр":  "steps:\n  - create_inbox: {nmae: x}\n",
+		"два действия":          "steps:\n  - create_inbox: {}\n    delete_inbox: {}\n",
+		"параметры не словарь":  "steps:\n  - send: text\n",
+		"неизвестный ключ":      "step:\n  - create_inbox: {}\n",
+		"некорректный таймаут":  "timeout: soon\nsteps:\n  - create_inbox:\n",
+		"синтаксическая ошибка": "steps:\n  - create_inbox: {as: [x}\n",
+	}
+	for name, doc := range tests {
+		if _, err := ParseScenario([]byte(doc)); err == nil {
+			t.Errorf("%s: ожидалась ошибка", name)
+		}
+	}
+}
+
+// TestScenarioReports проверяет отчеты JUnit XML и JSON
+func TestScenarioReports(t *testing.T) {
+	results := []*ScenarioResult{{
+		Name:   "s1",
+		Passed: false,
+		Steps: []ScenarioStepResult{
+			{Name: "1. create_inbox", Action: "create_inbox", Status: ScenarioStepPassed},
+			{Name: "2. assert", Action: "assert", Status: ScenarioStepFailed, Error: "проверка не выполнена: <x>"},
+			{Name: "3. delete_inbox", Action: "delete_inbox", Status: ScenarioStepSkipped},
+		},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteScenarioJUnit(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	var report JUnitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Некорректный XML: %v\n%s", err, buf.String())
+	}
+	if report.Tests != 3 || report.Failures != 1 || report.Skipped != 1 || len(report.Suites) != 1 {
+		t.Errorf("Неверные счетчики: %+v", report)
+	}
+	if failure := report.Suites[0].Cases[1].Failure; failure == nil || failure.Message != "проверка не выполнена: <x>" {
+		t.Errorf("Неверная ошибка шага: %+v", failure)
+	}
+
+	buf.Reset()
+	if err := WriteScenarioJSON(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []ScenarioResult
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded[0].Steps[2].Status != ScenarioStepSkipped {
+		t.Errorf("Неверный JSON: %v\n%s", err, buf.String())
+	}
+}
+
+// TestScenarioCommand проверяет команду scenario и отчет JUnit
+func TestScenarioCommand(t *testing.T) {
+	mock := mailslurptest.NewMockClient()
+	app := newRegistrationApp(t, mock)
+	UseCLIClient(t, mock)
+
+	junit := filepath.Join(t.TempDir(), "report.xml")
+	var stdout, stderr bytes.Buffer
+	code := RunCLI([]string{"scenario", "--api-key", "key", "--var", "app=" + app.URL, "--junit", junit,
+		filepath.Join("testdata", "scenarios", "registration.yaml")}, &stdout, &stderr)
+	if code != ExitOK {
+		t.Fatalf("Неверный код завершения: %d, stdout: %s, stderr: %s", code, stdout.String(), stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "PASS  Подтверждение регистрации") {
+		t.Errorf("Неверный вывод: %s", stdout.String())
+	}
+	if data, err := os.ReadFile(junit); err != nil || !bytes.Contains(data, []byte(`<testsuite name="Подтверждение регистрации" tests="7" failures="0"`)) {
+		t.Errorf("Неверный отчет JUnit: %v\n%s", err, data)
+	}
+
+	// Без переменной app сценарий не проходит
+	stdout.Reset()
+	code = RunCLI([]string{"scenario", "--api-key", "key", "--json",
+		filepath.Join("testdata", "scenarios", "registration.yaml")}, &stdout, &stderr)
+	if code != ExitFailure || !strings.Contains(stdout.String(), `"passed": false`) {
+		t.Errorf("Ожидалась ошибка сценария: %d\n%s", code, stdout.String())
+	}
+
+	if code := RunCLI([]string{"scenario", "--api-key", "key", "missing.yaml"}, &stdout, &stderr); code != ExitUsage {
+		t.Errorf("Для отсутствующего файла ожидался ExitUsage, получено %d", code)
+	}
+}
+
+// TestScenarioContextCanceled проверяет, что отмена останавливает сценарий
+func TestScenarioContextCanceled(t *testing.T) {
+	scenario, _ := ParseScenario([]byte("steps:\n  - create_inbox:\n  - create_inbox:\n"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := (&ScenarioRunner{Client: mailslurptest.NewMockClient()}).Run(ctx, scenario)
+	if result.Passed || !strings.Contains(result.Steps[0].Error, context.Canceled.Error()) || result.Steps[1].Status != ScenarioStepSkipped {
+		t.Errorf("Неверный результат после отмены: %+v", result.Steps)
+	}
+}
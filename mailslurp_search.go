@@ -0,0 +1,285 @@
+package mailslurp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Поля письма, по которым строится индекс
+const (
+	searchFieldSubject = "subject"
+	searchFieldBody    = "body"
+	searchFieldFrom    = "from"
+	searchFieldTo      = "to"
+)
+
+var searchFields = []string{searchFieldSubject, searchFieldBody, searchFieldFrom, searchFieldTo}
+
+// SearchResult представляет собой найденное письмо
+type SearchResult struct {
+	InboxID string
+	Email   Email
+}
+
+// SearchIndex представляет собой инвертированный индекс по сохраненным письмам
+type SearchIndex struct {
+	docs     []SearchResult
+	postings map[string]map[string][]int // поле -> токен -> номера документов
+}
+
+// NewSearchIndex создает пустой индекс
+func NewSearchIndex() *SearchIndex {
+	postings := make(map[string]map[string][]int, len(searchFields))
+	for _, field := range searchFields {
+		postings[field] = map[string][]int{}
+	}
+	return &SearchIndex{postings: postings}
+}
+
+// BuildSearchIndex индексирует все письма из локального хранилища, включая письма удаленных ящиков
+func BuildSearchIndex(store *MessageStore) (*SearchIndex, error) {
+	index := NewSearchIndex()
+	inboxes, err := store.Inboxes()
+	if err != nil {
+		return nil, err
+	}
+	for _, inbox := range inboxes {
+		emails, err := store.Emails(inbox.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, email := range emails {
+			index.Add(inbox.ID, email)
+		}
+	}
+	return index, nil
+}
+
+// Add добавляет письмо в индекс
+func (idx *SearchIndex) Add(inboxID string, email Email) {
+	doc := len(idx.docs)
+	idx.docs = append(idx.docs, SearchResult{InboxID: inboxID, Email: email})
+
+	texts := map[string]string{
+		searchFieldSubject: email.Subject,
+		searchFieldBody:    email.TextBody(),
+		searchFieldFrom:    email.From,
+		searchFieldTo:      strings.Join(email.To, " "),
+	}
+	for field, text := range texts {
+		seen := map[string]bool{}
+		for _, token := range tokenize(text) {
+			if seen[token] {
+				continue
+			}
+			seen[token] = true
+			idx.postings[field][token] = append(idx.postings[field][token], doc)
+		}
+	}
+}
+
+// Len возвращает количество проиндексированных писем
+func (idx *SearchIndex) Len() int {
+	return len(idx.docs)
+}
+
+// Search выполняет запрос и возвращает письма от новых к старым.
+//
+// Синтаксис запроса:
+//
+//	заказ 1234            - слова в любом поле письма
+//	from:staging          - слова в адресе отправителя
+//	to:user@example.com   - слова в адресах получателей
+//	subject:"order 1234"  - слова в теме (кавычки объединяют несколько слов)
+//	body:подтверждение    - слова в теле письма
+//	inbox:ID              - письма конкретного ящика
+//	before:2024-03-29     - письма, полученные до даты
+//	after:2024-03-01      - письма, полученные после даты
+//	has:attachment        - письма с вложениями
+//
+// Все условия объединяются через И.
+func (idx *SearchIndex) Search(query string) ([]SearchResult, error) {
+	q, err := ParseSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates map[int]bool
+	intersect := func(docs map[int]bool) {
+		if candidates == nil {
+			candidates = docs
+			return
+		}
+		for doc := range candidates {
+			if !docs[doc] {
+				delete(candidates, doc)
+			}
+		}
+	}
+
+	for _, term := range q.Terms {
+		fields := searchFields
+		if term.Field != "" {
+			fields = []string{term.Field}
+		}
+		for _, token := range tokenize(term.Text) {
+			docs := map[int]bool{}
+			for _, field := range fields {
+				for _, doc := range idx.postings[field][token] {
+					docs[doc] = true
+				}
+			}
+			intersect(docs)
+		}
+	}
+
+	if candidates == nil {
+		candidates = make(map[int]bool, len(idx.docs))
+		for doc := range idx.docs {
+			candidates[doc] = true
+		}
+	}
+
+	results := make([]SearchResult, 0, len(candidates))
+	for doc := range candidates {
+		result := idx.docs[doc]
+		if q.matchesFilters(result) {
+			results = append(results, result)
+		}
+	}
+	// Сначала новые письма; письма с одинаковым временем - по ID, чтобы
+	// порядок не зависел от обхода map
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i].Email, results[j].Email
+		if !a.Created.Equal(b.Created) {
+			return a.Created.After(b.Created)
+		}
+		return a.ID < b.ID
+	})
+	return results, nil
+}
+
+// SearchTerm представляет собой условие поиска по словам
+type SearchTerm struct {
+	Field string // пустое поле означает поиск по всем полям
+	Text  string
+}
+
+// SearchQuery представляет собой разобранный поисковый запрос
+type SearchQuery struct {
+	Terms         []SearchTerm
+	InboxID       string
+	Before        time.Time
+	After         time.Time
+	HasAttachment bool
+}
+
+// ParseSearchQuery разбирает поисковый запрос
+func ParseSearchQuery(query string) (*SearchQuery, error) {
+	q := &SearchQuery{}
+	for _, part := range splitQuery(query) {
+		key, value, hasKey := strings.Cut(part, ":")
+		if !hasKey || value == "" {
+			if err := q.addTerm("", part, part); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case searchFieldFrom, searchFieldTo, searchFieldSubject, searchFieldBody:
+			if err := q.addTerm(strings.ToLower(key), value, part); err != nil {
+				return nil, err
+			}
+		case "inbox":
+			q.InboxID = value
+		case "before", "after":
+			date, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				return nil, fmt.Errorf("неверная дата в запросе %q: ожидается ГГГГ-ММ-ДД", part)
+			}
+			if strings.ToLower(key) == "before" {
+				q.Before = date
+			} else {
+				q.After = date
+			}
+		case "has":
+			if !strings.EqualFold(value, "attachment") {
+				return nil, fmt.Errorf("неизвестное условие %q: поддерживается только has:attachment", part)
+			}
+			q.HasAttachment = true
+		default:
+			// Двоеточие внутри обычного текста (например, "Re:") не считаем оператором
+			if err := q.addTerm("", part, part); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return q, nil
+}
+
+// addTerm добавляет условие поиска по словам. Условие без единого слова
+// (например, "subject:!!!") ничего не сужает и совпало бы со всеми письмами,
+// поэтому считается ошибкой.
+func (q *SearchQuery) addTerm(field, text, part string) error {
+	if len(tokenize(text)) == 0 {
+		return fmt.Errorf("в условии %q нет слов для поиска", part)
+	}
+	q.Terms = append(q.Terms, SearchTerm{Field: field, Text: text})
+	return nil
+}
+
+func (q *SearchQuery) matchesFilters(result SearchResult) bool {
+	if q.InboxID != "" && result.InboxID != q.InboxID {
+		return false
+	}
+	if !q.Before.IsZero() && !result.Email.Created.Before(q.Before) {
+		return false
+	}
+	if !q.After.IsZero() && result.Email.Created.Before(q.After) {
+		return false
+	}
+	if q.HasAttachment && len(result.Email.Attachments) == 0 {
+		return false
+	}
+	return true
+}
+
+// splitQuery делит запрос на части по пробелам с учетом кавычек
+func splitQuery(query string) []string {
+	var parts []string
+	var current strings.Builder
+	inQuotes := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			if current.Len() > 0 {
+				parts = append(parts, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
+
+// tokenize делит текст на слова в нижнем регистре. Буквы любого алфавита,
+// включая кириллицу, считаются частью слова; "ё" приводится к "е".
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, field := range fields {
+		fields[i] = strings.ReplaceAll(field, "ё", "е")
+	}
+	return fields
+}
@@ -0,0 +1,94 @@
+package mailslurp
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestSearchIndex() *SearchIndex {
+	index := NewSearchIndex()
+	index.Add("inbox-1", Email{
+		ID:      "email-1",
+		Subject: "Ваш заказ №1234 оформлен",
+		Body:    "<p>Спасибо за покупку! Ёлка будет доставлена завтра.</p>",
+		From:    "shop@staging.example.com",
+		To:      []string{"user@example.com"},
+		Created: time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC),
+	})
+	index.Add("inbox-2", Email{
+		ID:          "email-2",
+		Subject:     "Order 1234 invoice",
+		Body:        "Invoice attached",
+		From:        "billing@example.com",
+		To:          []string{"user2@example.com"},
+		Created:     time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC),
+		Attachments: []string{"att-1"},
+	})
+	// Письмо с той же датой, что email-1: порядок определяет ID
+	index.Add("inbox-3", Email{
+		ID:      "email-3",
+		Subject: "Скидка",
+		Body:    "<html><head><style>.promo { color: red }</style></head><body><p>Скидка 10%</p></body></html>",
+		From:    "promo@example.com",
+		To:      []string{"user@example.com"},
+		Created: time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC),
+	})
+	return index
+}
+
+// TestSearchQuerySyntax проверяет операторы поискового запроса
+func TestSearchQuerySyntax(t *testing.T) {
+	index := newTestSearchIndex()
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"1234", []string{"email-2", "email-1"}},
+		{"from:staging 1234", []string{"email-1"}},
+		{"ЗАКАЗ", []string{"email-1"}},
+		{"елка", []string{"email-1"}},
+		{`subject:"order 1234"`, []string{"email-2"}},
+		{"has:attachment", []string{"email-2"}},
+		{"1234 before:2024-03-15", []string{"email-1"}},
+		{"after:2024-03-15", []string{"email-2"}},
+		{"inbox:inbox-1", []string{"email-1"}},
+		{"p", nil},
+		{"to:user2", []string{"email-2"}},
+		{"несуществующее", nil},
+		{"color", nil},
+		{"body:скидка", []string{"email-3"}},
+		{"to:user@example.com", []string{"email-1", "email-3"}},
+		{"after:2024-03-01", []string{"email-2", "email-1", "email-3"}},
+	}
+	for _, tc := range cases {
+		results, err := index.Search(tc.query)
+		if err != nil {
+			t.Errorf("Ошибка запроса %q: %v", tc.query, err)
+			continue
+		}
+		var got []string
+		for _, r := range results {
+			got = append(got, r.Email.ID)
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("Запрос %q: ожидалось %v, получено %v", tc.query, tc.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("Запрос %q: ожидалось %v, получено %v", tc.query, tc.want, got)
+				break
+			}
+		}
+	}
+}
+
+// TestSearchQueryErrors проверяет ошибки разбора запроса
+func TestSearchQueryErrors(t *testing.T) {
+	for _, query := range []string{"before:вчера", "has:star", "subject:!!!", "№", "заказ -"} {
+		if _, err := ParseSearchQuery(query); err == nil {
+			t.Errorf("Ожидалась ошибка для запроса %q", query)
+		}
+	}
+}
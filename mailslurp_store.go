@@ -0,0 +1,300 @@
+package mailslurp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotCached возвращается, если запись отсутствует в локальном хранилище
+var ErrNotCached = errors.New("запись отсутствует в локальном хранилище")
+
+// StoredInbox представляет собой почтовый ящик в локальном хранилище.
+// Удаленные в MailSlurp ящики остаются в истории с заполненным DeletedAt.
+type StoredInbox struct {
+	Inbox
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+// MessageStore представляет собой локальное хранилище ящиков, писем и вложений.
+// Каждая запись хранится отдельным JSON файлом в каталоге хранилища:
+//
+//	inboxes/<inboxID>.json
+//	emails/<inboxID>/<emailID>.json
+//	attachments/<emailID>/<имя файла>
+//	meta.json - время последней синхронизации
+type MessageStore struct {
+	dir  string
+	mu   sync.RWMutex
+	meta map[string]time.Time
+}
+
+// DefaultStoreDir возвращает каталог хранилища по умолчанию: ~/.cache/neuromail
+func DefaultStoreDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "neuromail")
+	}
+	return filepath.Join(dir, "neuromail")
+}
+
+// OpenMessageStore открывает (и при необходимости создает) хранилище в каталоге dir
+func OpenMessageStore(dir string) (*MessageStore, error) {
+	for _, sub := range []string{"inboxes", "emails", "attachments"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0700); err != nil {
+			return nil, fmt.Errorf("не удалось создать хранилище: %v", err)
+		}
+	}
+
+	s := &MessageStore{dir: dir, meta: map[string]time.Time{}}
+	if err := readJSONFile(s.metaPath(), &s.meta); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Dir возвращает каталог хранилища
+func (s *MessageStore) Dir() string {
+	return s.dir
+}
+
+// SaveInbox сохраняет почтовый ящик. Отметка об удалении сбрасывается.
+func (s *MessageStore) SaveInbox(inbox Inbox) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeJSONFile(s.inboxPath(inbox.ID), StoredInbox{Inbox: inbox})
+}
+
+// MarkInboxDeleted отмечает ящик удаленным, сохраняя его письма в истории
+func (s *MessageStore) MarkInboxDeleted(inboxID string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stored StoredInbox
+	if err := readJSONFile(s.inboxPath(inboxID), &stored); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if stored.DeletedAt != nil {
+		return nil
+	}
+	stored.DeletedAt = &at
+	return writeJSONFile(s.inboxPath(inboxID), stored)
+}
+
+// Inbox возвращает сохраненный почтовый ящик
+func (s *MessageStore) Inbox(inboxID string) (*StoredInbox, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var stored StoredInbox
+	if err := readJSONFile(s.inboxPath(inboxID), &stored); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: ящик %s", ErrNotCached, inboxID)
+		}
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// Inboxes возвращает все сохраненные ящики, включая удаленные, от новых к старым
+func (s *MessageStore) Inboxes() ([]StoredInbox, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files, err := filepath.Glob(filepath.Join(s.dir, "inboxes", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	inboxes := make([]StoredInbox, 0, len(files))
+	for _, file := range files {
+		var stored StoredInbox
+		if err := readJSONFile(file, &stored); err != nil {
+			return nil, err
+		}
+		inboxes = append(inboxes, stored)
+	}
+	sort.Slice(inboxes, func(i, j int) bool {
+		return inboxes[i].CreatedAt.After(inboxes[j].CreatedAt)
+	})
+	return inboxes, nil
+}
+
+// SaveEmails сохраняет письма ящика. Ранее сохраненные письма не удаляются.
+func (s *MessageStore) SaveEmails(inboxID string, emails []Email) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir := filepath.Join(s.dir, "emails", escapeFileName(inboxID))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	for _, email := range emails {
+		path := filepath.Join(dir, escapeFileName(email.ID)+".json")
+		// Не затираем сохраненное тело пустым: списки писем могут приходить без тела
+		if email.Body == "" {
+			var cached Email
+			if err := readJSONFile(path, &cached); err == nil {
+				email.Body = cached.Body
+			}
+		}
+		if err := writeJSONFile(path, email); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Emails возвращает сохраненные письма ящика от старых к новым
+func (s *MessageStore) Emails(inboxID string) ([]Email, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files, err := filepath.Glob(filepath.Join(s.dir, "emails", escapeFileName(inboxID), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	emails := make([]Email, 0, len(files))
+	for _, file := range files {
+		var email Email
+		if err := readJSONFile(file, &email); err != nil {
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+	sort.SliceStable(emails, func(i, j int) bool {
+		return emails[i].Created.Before(emails[j].Created)
+	})
+	return emails, nil
+}
+
+// DeleteEmails удаляет сохраненные письма ящика вместе с их вложениями
+func (s *MessageStore) DeleteEmails(inboxID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir := filepath.Join(s.dir, "emails", escapeFileName(inboxID))
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		emailID := strings.TrimSuffix(filepath.Base(file), ".json")
+		if err := os.RemoveAll(filepath.Join(s.dir, "attachments", emailID)); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(dir)
+}
+
+// SaveAttachment сохраняет содержимое вложения письма
+func (s *MessageStore) SaveAttachment(emailID, name string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir := filepath.Join(s.dir, "attachments", escapeFileName(emailID))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, escapeFileName(name)), data)
+}
+
+// Attachment возвращает сохраненное вложение письма
+func (s *MessageStore) Attachment(emailID, name string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := os.ReadFile(filepath.Join(s.dir, "attachments", escapeFileName(emailID), escapeFileName(name)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: вложение %s письма %s", ErrNotCached, name, emailID)
+	}
+	return data, err
+}
+
+// HasAttachment сообщает, сохранено ли вложение письма
+func (s *MessageStore) HasAttachment(emailID, name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, err := os.Stat(filepath.Join(s.dir, "attachments", escapeFileName(emailID), escapeFileName(name)))
+	return err == nil
+}
+
+// SyncedAt возвращает время последней синхронизации по ключу
+func (s *MessageStore) SyncedAt(key string) (time.Time, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	at, ok := s.meta[key]
+	return at, ok
+}
+
+// MarkSynced запоминает время синхронизации по ключу
+func (s *MessageStore) MarkSynced(key string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.meta[key] = at
+	return writeJSONFile(s.metaPath(), s.meta)
+}
+
+func (s *MessageStore) inboxPath(inboxID string) string {
+	return filepath.Join(s.dir, "inboxes", escapeFileName(inboxID)+".json")
+}
+
+func (s *MessageStore) metaPath() string {
+	return filepath.Join(s.dir, "meta.json")
+}
+
+// escapeFileName делает идентификатор безопасным для использования в имени файла
+func escapeFileName(name string) string {
+	escaped := url.PathEscape(name)
+	if escaped == "" || escaped == "." || escaped == ".." {
+		escaped = "_" + escaped
+	}
+	return escaped
+}
+
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("поврежденная запись %s: %v", path, err)
+	}
+	return nil
+}
+
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic записывает файл через временный файл и переименование,
+// чтобы при сбое не оставалось наполовину записанных записей
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,296 @@
+package mailslurp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Изоляция пользователей на общем ключе, как в frontend/js/apiKeyManager.js:
+// имя ящика "<префикс>-<имя>" и/или тег "prefix:<префикс>"
+const (
+	tenantNameSeparator = "-"
+	tenantTagPrefix     = "prefix:"
+)
+
+// frontendPrefixPattern - формат префикса generateUserPrefix из
+// frontend/js/apiKeyManager.js: "u" и до 6 символов base36
+// (Math.random().toString(36).substring(2, 8) бывает короче 6 символов)
+var frontendPrefixPattern = regexp.MustCompile(`^u[0-9a-z]{1,6}$`)
+
+// ErrEmptySweepCriteria - не задано ни одного условия удаления ящиков
+var ErrEmptySweepCriteria = errors.New("не заданы условия очистки: укажите максимальный возраст или период без писем")
+
+// TenantPrefix возвращает префикс пользователя, которому принадлежит ящик:
+// из тега prefix:<префикс> или из имени до первого "-", если эта часть имени
+// совпадает с форматом префикса frontend. Имена вроде "ci-1" из
+// CreateInboxes префикса не дают. Для ящиков без префикса возвращается
+// пустая строка.
+func TenantPrefix(inbox Inbox) string {
+	for _, tag := range inbox.Tags {
+		if prefix, ok := strings.CutPrefix(tag, tenantTagPrefix); ok && prefix != "" {
+			return prefix
+		}
+	}
+	if prefix, _, ok := strings.Cut(inbox.Name, tenantNameSeparator); ok && frontendPrefixPattern.MatchString(prefix) {
+		return prefix
+	}
+	return ""
+}
+
+// SweepOptions задает условия очистки брошенных ящиков. Ящик удаляется, если
+// выполнено хотя бы одно из условий MaxAge и IdleFor.
+type SweepOptions struct {
+	// MaxAge - удалять ящики, созданные раньше, чем MaxAge назад
+	MaxAge time.Duration
+	// IdleFor - удалять ящики, в которые не приходили письма за IdleFor.
+	// Ящик без писем считается неактивным с момента создания.
+	IdleFor time.Duration
+	// Tenants - проверять только ящики с этими префиксами; пусто - все префиксы
+	Tenants []string
+	// IncludeUntenanted - проверять и ящики без префикса
+	IncludeUntenanted bool
+	// DryRun - только составить отчет, ничего не удаляя
+	DryRun bool
+	// Bulk - параметры параллельных запросов
+	Bulk BulkOptions
+	// Now - текущее время, по умолчанию time.Now
+	Now func() time.Time
+}
+
+// SweptInbox - решение по одному ящику
+type SweptInbox struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name,omitempty"`
+	EmailAddress string     `json:"emailAddress"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	LastEmailAt  *time.Time `json:"lastEmailAt,omitempty"`
+	// Reason - почему ящик удаляется; пусто, если ящик остается
+	Reason  string `json:"reason,omitempty"`
+	Deleted bool   `json:"deleted"`
+	Error   string `json:"error,omitempty"`
+}
+
+// TenantSweepReport - итог очистки ящиков одного пользователя
+type TenantSweepReport struct {
+	Prefix  string       `json:"prefix"`
+	Total   int          `json:"total"`
+	Expired int          `json:"expired"`
+	Deleted int          `json:"deleted"`
+	Failed  int          `json:"failed"`
+	Inboxes []SweptInbox `json:"inboxes"`
+}
+
+// SweepReport - отчет об очистке, сгруппированный по префиксам
+type SweepReport struct {
+	DryRun  bool                `json:"dryRun"`
+	Scanned int                 `json:"scanned"`
+	Expired int                 `json:"expired"`
+	Deleted int                 `json:"deleted"`
+	Failed  int                 `json:"failed"`
+	Tenants []TenantSweepReport `json:"tenants"`
+}
+
+// Sweep находит брошенные ящики и удаляет их (или только перечисляет при
+// DryRun). Ошибки чтения писем и удаления отдельных ящиков попадают в отчет
+// и в возвращаемую ошибку (*BulkError); отчет возвращается и в этом случае.
+// Ящик, письма которого получить не удалось, пропускается: без них нельзя
+// проверить условие IdleFor.
+func Sweep(client MailSlurpClient, opts SweepOptions) (*SweepReport, error) {
+	if opts.MaxAge <= 0 && opts.IdleFor <= 0 {
+		return nil, ErrEmptySweepCriteria
+	}
+	now := time.Now()
+	if opts.Now != nil {
+		now = opts.Now()
+	}
+
+	all, err := client.GetInboxes()
+	if err != nil {
+		return nil, err
+	}
+	var inboxes []Inbox
+	for _, inbox := range all {
+		if sweepTenantSelected(TenantPrefix(inbox), opts) {
+			inboxes = append(inboxes, inbox)
+		}
+	}
+
+	// Время последнего письма нужно только для условия IdleFor
+	lastEmail := map[string]time.Time{}
+	emailErrs := map[string]error{}
+	var emailsErr error
+	if opts.IdleFor > 0 {
+		ids := make([]string, len(inboxes))
+		for i, inbox := range inboxes {
+			ids[i] = inbox.ID
+		}
+		var results []EmailsResult
+		results, emailsErr = GetEmailsForInboxes(client, ids, opts.Bulk)
+		for _, r := range results {
+			if r.Err != nil {
+				emailErrs[r.InboxID] = r.Err
+				continue
+			}
+			for _, email := range r.Emails {
+				if email.Created.After(lastEmail[r.InboxID]) {
+					lastEmail[r.InboxID] = email.Created
+				}
+			}
+		}
+	}
+
+	report := &SweepReport{DryRun: opts.DryRun, Scanned: len(inboxes)}
+	swept := make([]SweptInbox, len(inboxes))
+	var expiredIDs []string
+	var expiredIdx []int
+	for i, inbox := range inboxes {
+		swept[i] = SweptInbox{
+			ID:           inbox.ID,
+			Name:         inbox.Name,
+			EmailAddress: inbox.EmailAddress,
+			CreatedAt:    inbox.CreatedAt,
+		}
+		if err, failed := emailErrs[inbox.ID]; failed {
+			swept[i].Error = "не удалось получить письма: " + err.Error()
+			continue
+		}
+		last, ok := lastEmail[inbox.ID]
+		if ok {
+			swept[i].LastEmailAt = &last
+		}
+		swept[i].Reason = sweepReason(inbox, last, ok, now, opts)
+		if swept[i].Reason != "" {
+			expiredIDs = append(expiredIDs, inbox.ID)
+			expiredIdx = append(expiredIdx, i)
+		}
+	}
+
+	var deleteErr error
+	if !opts.DryRun && len(expiredIDs) > 0 {
+		var results []InboxResult
+		results, deleteErr = DeleteInboxes(client, expiredIDs, opts.Bulk)
+		for j, r := range results {
+			if r.Err != nil {
+				swept[expiredIdx[j]].Error = r.Err.Error()
+			} else {
+				swept[expiredIdx[j]].Deleted = true
+			}
+		}
+	}
+
+	tenants := map[string]*TenantSweepReport{}
+	for i, inbox := range inboxes {
+		prefix := TenantPrefix(inbox)
+		tenant, ok := tenants[prefix]
+		if !ok {
+			tenant = &TenantSweepReport{Prefix: prefix}
+			tenants[prefix] = tenant
+		}
+		tenant.Total++
+		if swept[i].Reason != "" {
+			tenant.Expired++
+		}
+		if swept[i].Deleted {
+			tenant.Deleted++
+		}
+		if swept[i].Error != "" {
+			tenant.Failed++
+		}
+		tenant.Inboxes = append(tenant.Inboxes, swept[i])
+	}
+	for _, tenant := range tenants {
+		report.Expired += tenant.Expired
+		report.Deleted += tenant.Deleted
+		report.Failed += tenant.Failed
+		report.Tenants = append(report.Tenants, *tenant)
+	}
+	sort.Slice(report.Tenants, func(i, j int) bool {
+		return report.Tenants[i].Prefix < report.Tenants[j].Prefix
+	})
+	return report, errors.Join(emailsErr, deleteErr)
+}
+
+// sweepTenantSelected проверяет, входит ли префикс в область очистки
+func sweepTenantSelected(prefix string, opts SweepOptions) bool {
+	if prefix == "" {
+		return opts.IncludeUntenanted
+	}
+	if len(opts.Tenants) == 0 {
+		return true
+	}
+	for _, tenant := range opts.Tenants {
+		if tenant == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// sweepReason возвращает причину удаления ящика или пустую строку
+func sweepReason(inbox Inbox, lastEmail time.Time, hasEmail bool, now time.Time, opts SweepOptions) string {
+	if age := now.Sub(inbox.CreatedAt); opts.MaxAge > 0 && age >= opts.MaxAge {
+		return fmt.Sprintf("создан %s назад", age.Round(time.Minute))
+	}
+	if opts.IdleFor <= 0 {
+		return ""
+	}
+	if !hasEmail {
+		if now.Sub(inbox.CreatedAt) >= opts.IdleFor {
+			return fmt.Sprintf("нет писем за %s", opts.IdleFor)
+		}
+		return ""
+	}
+	if idle := now.Sub(lastEmail); idle >= opts.IdleFor {
+		return fmt.Sprintf("последнее письмо %s назад", idle.Round(time.Minute))
+	}
+	return ""
+}
+
+// WriteText выводит отчет в виде таблицы по пользователям и ящикам
+func (r *SweepReport) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, tenant := range r.Tenants {
+		prefix := tenant.Prefix
+		if prefix == "" {
+			prefix = "(без префикса)"
+		}
+		fmt.Fprintf(tw, "%s\tящиков: %d\tк удалению: %d\tудалено: %d\tошибок: %d\n",
+			prefix, tenant.Total, tenant.Expired, tenant.Deleted, tenant.Failed)
+		for _, inbox := range tenant.Inboxes {
+			if inbox.Reason == "" && inbox.Error == "" {
+				continue
+			}
+			status := "будет удален"
+			switch {
+			case inbox.Error != "":
+				status = "ошибка: " + inbox.Error
+			case inbox.Deleted:
+				status = "удален"
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", inbox.ID, inbox.EmailAddress, inbox.Reason, status)
+		}
+	}
+
+	if r.DryRun {
+		fmt.Fprintf(tw, "Итого: проверено %d, к удалению %d (пробный запуск, ничего не удалено)\n",
+			r.Scanned, r.Expired)
+	} else {
+		fmt.Fprintf(tw, "Итого: проверено %d, к удалению %d, удалено %d, ошибок %d\n",
+			r.Scanned, r.Expired, r.Deleted, r.Failed)
+	}
+	return tw.Flush()
+}
+
+// WriteJSON выводит отчет в формате JSON
+func (r *SweepReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
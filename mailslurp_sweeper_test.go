@@ -0,0 +1,199 @@
+package mailslurp_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "mailslurp-example"
+	"mailslurp-example/mailslurptest"
+)
+
+// TestTenantPrefix проверяет определение префикса по тегу и имени
+func TestTenantPrefix(t *testing.T) {
+	tests := []struct {
+		inbox Inbox
+		want  string
+	}{
+		{Inbox{Name: "uabc123-inbox-1700000000"}, "uabc123"},
+		{Inbox{Name: "u1a2-inbox-1700000000"}, "u1a2"},
+		{Inbox{Name: "u-inbox"}, ""},
+		{Inbox{Name: "uabc1234-inbox"}, ""},
+		{Inbox{Name: "other-name", Tags: []string{"ci", "prefix:utag"}}, "utag"},
+		{Inbox{Name: "plain"}, ""},
+		{Inbox{Name: "ci-1"}, ""},
+		{Inbox{Name: "manual-run"}, ""},
+		{Inbox{Name: "uABC123-inbox"}, ""},
+		{Inbox{Tags: []string{"prefix:"}}, ""},
+	}
+	for _, tt := range tests {
+		if got := TenantPrefix(tt.inbox); got != tt.want {
+			t.Errorf("TenantPrefix(%+v) = %q, ожидалось %q", tt.inbox, got, tt.want)
+		}
+	}
+}
+
+func newSweepMock(now time.Time) *mailslurptest.MockClient {
+	mock := mailslurptest.NewMockClient(
+		Inbox{ID: "a-old", Name: "uaaaaaa-1", CreatedAt: now.Add(-96 * time.Hour)},
+		Inbox{ID: "a-active", Name: "uaaaaaa-2", CreatedAt: now.Add(-30 * time.Hour)},
+		Inbox{ID: "a-idle", Name: "uaaaaaa-3", CreatedAt: now.Add(-30 * time.Hour)},
+		Inbox{ID: "b-new", Name: "ubbbbbb-1", CreatedAt: now.Add(-time.Hour)},
+		Inbox{ID: "manual", Name: "manual", CreatedAt: now.Add(-200 * time.Hour)},
+	)
+	mock.Deliver("a-active", Email{Subject: "свежее", Created: now.Add(-time.Hour)})
+	mock.Deliver("a-idle", Email{Subject: "старое", Created: now.Add(-29 * time.Hour)})
+	return mock
+}
+
+// TestSweep проверяет выбор ящиков по возрасту и активности и группировку отчета
+func TestSweep(t *testing.T) {
+	now := time.Now()
+	mock := newSweepMock(now)
+	opts := SweepOptions{MaxAge: 72 * time.Hour, IdleFor: 24 * time.Hour, Now: func() time.Time { return now }}
+
+	if _, err := Sweep(mock, SweepOptions{}); !errors.Is(err, ErrEmptySweepCriteria) {
+		t.Errorf("Ожидалась ошибка ErrEmptySweepCriteria, получено: %v", err)
+	}
+
+	opts.DryRun = true
+	report, err := Sweep(mock, opts)
+	if err != nil {
+		t.Fatalf("Ошибка Sweep: %v", err)
+	}
+	if report.Scanned != 4 || report.Expired != 2 || report.Deleted != 0 {
+		t.Errorf("Неверный отчет пробного запуска: %+v", report)
+	}
+	if mock.CallCount("DeleteInbox") != 0 {
+		t.Error("Пробный запуск не должен удалять ящики")
+	}
+	if len(report.Tenants) != 2 || report.Tenants[0].Prefix != "uaaaaaa" || report.Tenants[0].Expired != 2 {
+		t.Errorf("Неверная группировка: %+v", report.Tenants)
+	}
+
+	opts.DryRun = false
+	opts.Tenants = []string{"uaaaaaa"}
+	opts.Bulk.Concurrency = 1
+	mock.FailOn("DeleteInbox", 2, ErrRateLimited)
+	report, err = Sweep(mock, opts)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Ожидалась ошибка удаления, получено: %v", err)
+	}
+	if report.Scanned != 3 || report.Deleted != 1 || report.Failed != 1 {
+		t.Errorf("Неверный отчет: %+v", report)
+	}
+	deleted := map[string]bool{}
+	for _, inbox := range report.Tenants[0].Inboxes {
+		if inbox.Deleted {
+			deleted[inbox.ID] = true
+		}
+		if inbox.ID == "a-active" && (inbox.Reason != "" || inbox.LastEmailAt == nil) {
+			t.Errorf("Активный ящик не должен удаляться: %+v", inbox)
+		}
+	}
+	if !deleted["a-old"] {
+		t.Errorf("Старый ящик должен быть удален: %+v", report.Tenants[0].Inboxes)
+	}
+
+	var out bytes.Buffer
+	report.WriteText(&out)
+	if !strings.Contains(out.String(), "uaaaaaa") || !strings.Contains(out.String(), "ошибка:") {
+		t.Errorf("Неверный текстовый отчет:\n%s", out.String())
+	}
+}
+
+// TestSweepSkipsUnreadableInboxes проверяет, что ошибка чтения писем одного
+// ящика не прерывает очистку остальных
+func TestSweepSkipsUnreadableInboxes(t *testing.T) {
+	now := time.Now()
+	mock := newSweepMock(now)
+	mock.FailOn("GetEmails", 1, ErrRateLimited)
+	report, err := Sweep(mock, SweepOptions{
+		IdleFor: 24 * time.Hour,
+		Tenants: []string{"uaaaaaa"},
+		Bulk:    BulkOptions{Concurrency: 1},
+		Now:     func() time.Time { return now },
+	})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Ожидалась ошибка чтения писем, получено: %v", err)
+	}
+	if report == nil || report.Scanned != 3 || report.Failed != 1 || report.Deleted != 1 {
+		t.Fatalf("Неверный отчет: %+v", report)
+	}
+	for _, inbox := range report.Tenants[0].Inboxes {
+		switch inbox.ID {
+		case "a-old":
+			if inbox.Error == "" || inbox.Reason != "" || inbox.Deleted {
+				t.Errorf("Ящик с ошибкой чтения писем должен быть пропущен: %+v", inbox)
+			}
+		case "a-idle":
+			if !inbox.Deleted {
+				t.Errorf("Неактивный ящик должен быть удален: %+v", inbox)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	report.WriteText(&out)
+	if !strings.Contains(out.String(), "a-old") || !strings.Contains(out.String(), "не удалось получить письма") {
+		t.Errorf("Ошибка чтения писем не попала в отчет:\n%s", out.String())
+	}
+}
+
+// TestSweepKeepsUntenanted проверяет, что без IncludeUntenanted общие ящики
+// с "-" в имени не принимаются за ящики пользователей
+func TestSweepKeepsUntenanted(t *testing.T) {
+	now := time.Now()
+	mock := mailslurptest.NewMockClient(
+		Inbox{ID: "ci", Name: "ci-1", CreatedAt: now.Add(-100 * time.Hour)},
+		Inbox{ID: "manual", Name: "manual-run", CreatedAt: now.Add(-100 * time.Hour)},
+		Inbox{ID: "user", Name: "uabc123-signup", CreatedAt: now.Add(-100 * time.Hour)},
+	)
+	report, err := Sweep(mock, SweepOptions{MaxAge: 72 * time.Hour, Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("Ошибка Sweep: %v", err)
+	}
+	if report.Scanned != 1 || report.Deleted != 1 {
+		t.Errorf("Неверный отчет: %+v", report)
+	}
+	inboxes, _ := mock.GetInboxes()
+	if len(inboxes) != 2 {
+		t.Fatalf("Общие ящики должны остаться: %+v", inboxes)
+	}
+	for _, inbox := range inboxes {
+		if inbox.ID == "user" {
+			t.Errorf("Ящик пользователя должен быть удален")
+		}
+	}
+}
+
+// TestSweepCommand проверяет команду sweep в режиме dry-run с выводом JSON
+func TestSweepCommand(t *testing.T) {
+	mock := newSweepMock(time.Now())
+	UseCLIClient(t, mock)
+
+	var stdout, stderr bytes.Buffer
+	if code := RunCLI([]string{"sweep", "--api-key", "key"}, &stdout, &stderr); code != ExitUsage {
+		t.Errorf("Без условий ожидался код %d, получено %d", ExitUsage, code)
+	}
+
+	stderr.Reset()
+	code := RunCLI([]string{"sweep", "--api-key", "key", "--max-age", "72h",
+		"--include-untenanted", "--dry-run", "--json"}, &stdout, &stderr)
+	if code != ExitOK {
+		t.Fatalf("Неверный код завершения: %d, stderr: %s", code, stderr.String())
+	}
+	var report SweepReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("Вывод не является JSON: %v\n%s", err, stdout.String())
+	}
+	if !report.DryRun || report.Scanned != 5 || report.Expired != 2 {
+		t.Errorf("Неверный отчет: %+v", report)
+	}
+	if mock.CallCount("DeleteInbox") != 0 {
+		t.Error("Пробный запуск не должен удалять ящики")
+	}
+}
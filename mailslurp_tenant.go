@@ -0,0 +1,311 @@
+package mailslurp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInboxNotOwned возвращается при обращении к ящику другого пользователя.
+	// Такие ошибки TenantClient также соответствуют ErrNotFound, чтобы не
+	// раскрывать существование чужих ящиков.
+	ErrInboxNotOwned = errors.New("почтовый ящик не принадлежит пользователю")
+	// ErrInvalidTenant - недопустимый идентификатор пользователя
+	ErrInvalidTenant = errors.New("недопустимый идентификатор пользователя")
+)
+
+// OwnershipStore хранит, какому пользователю принадлежит ящик.
+// InboxOwner возвращает пустую строку для неизвестных ящиков.
+type OwnershipStore interface {
+	InboxOwner(inboxID string) (string, error)
+	SetInboxOwner(inboxID, tenant string) error
+	RemoveInboxOwner(inboxID string) error
+}
+
+// MemoryOwnershipStore хранит владельцев ящиков в памяти
+type MemoryOwnershipStore struct {
+	mu     sync.RWMutex
+	owners map[string]string
+}
+
+// NewMemoryOwnershipStore создает пустое хранилище владельцев в памяти
+func NewMemoryOwnershipStore() *MemoryOwnershipStore {
+	return &MemoryOwnershipStore{owners: map[string]string{}}
+}
+
+// InboxOwner возвращает владельца ящика
+func (s *MemoryOwnershipStore) InboxOwner(inboxID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.owners[inboxID], nil
+}
+
+// SetInboxOwner назначает владельца ящика. Ящик другого владельца
+// переназначить нельзя.
+func (s *MemoryOwnershipStore) SetInboxOwner(inboxID, tenant string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return setOwner(s.owners, inboxID, tenant)
+}
+
+// RemoveInboxOwner удаляет запись о владельце ящика
+func (s *MemoryOwnershipStore) RemoveInboxOwner(inboxID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.owners, inboxID)
+	return nil
+}
+
+// FileOwnershipStore хранит владельцев ящиков в JSON файле вида
+// {"<inboxID>": "<пользователь>"}. Файл перезаписывается атомарно при каждом
+// изменении.
+type FileOwnershipStore struct {
+	path   string
+	mu     sync.RWMutex
+	owners map[string]string
+}
+
+// OpenFileOwnershipStore открывает хранилище владельцев; отсутствующий файл
+// считается пустым хранилищем
+func OpenFileOwnershipStore(path string) (*FileOwnershipStore, error) {
+	s := &FileOwnershipStore{path: path, owners: map[string]string{}}
+	if err := readJSONFile(path, &s.owners); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return s, nil
+}
+
+// InboxOwner возвращает владельца ящика
+func (s *FileOwnershipStore) InboxOwner(inboxID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.owners[inboxID], nil
+}
+
+// SetInboxOwner назначает владельца ящика и сохраняет файл
+func (s *FileOwnershipStore) SetInboxOwner(inboxID, tenant string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := setOwner(s.owners, inboxID, tenant); err != nil {
+		return err
+	}
+	return s.saveLocked()
+}
+
+// RemoveInboxOwner удаляет запись о владельце ящика и сохраняет файл
+func (s *FileOwnershipStore) RemoveInboxOwner(inboxID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.owners[inboxID]; !ok {
+		return nil
+	}
+	delete(s.owners, inboxID)
+	return s.saveLocked()
+}
+
+func (s *FileOwnershipStore) saveLocked() error {
+	return writeJSONFile(s.path, s.owners)
+}
+
+func setOwner(owners map[string]string, inboxID, tenant string) error {
+	if owner, ok := owners[inboxID]; ok && owner != tenant {
+		return fmt.Errorf("ящик %s: %w", inboxID, ErrInboxNotOwned)
+	}
+	owners[inboxID] = tenant
+	return nil
+}
+
+// TenantClient изолирует одного пользователя на общем API ключе, как
+// ApiKeyManager во frontend: имена создаваемых ящиков получают префикс
+// "<пользователь>-", а теги - "prefix:<пользователь>". Принадлежность ящиков
+// определяется по OwnershipStore, а не по имени, поэтому чужой ящик с
+// подходящим именем недоступен. GetInboxes и CreateInbox возвращают ящики
+// без префикса в имени и без тега prefix:, так, как их видит пользователь.
+//
+// Обернутый клиент недоступен снаружи: каждый метод MailSlurpClient
+// реализован явно и проверяет владельца ящика.
+type TenantClient struct {
+	client MailSlurpClient
+	tenant string
+	store  OwnershipStore
+}
+
+// NewTenantClient создает клиент пользователя tenant. Идентификатор не может
+// быть пустым и содержать "-", который отделяет префикс в имени ящика.
+func NewTenantClient(client MailSlurpClient, tenant string, store OwnershipStore) (*TenantClient, error) {
+	if tenant == "" || strings.Contains(tenant, tenantNameSeparator) || strings.TrimSpace(tenant) != tenant {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTenant, tenant)
+	}
+	return &TenantClient{client: client, tenant: tenant, store: store}, nil
+}
+
+// Tenant возвращает идентификатор пользователя
+func (c *TenantClient) Tenant() string {
+	return c.tenant
+}
+
+// GetInboxes возвращает только ящики пользователя
+func (c *TenantClient) GetInboxes() ([]Inbox, error) {
+	inboxes, err := c.client.GetInboxes()
+	if err != nil {
+		return nil, err
+	}
+	owned := make([]Inbox, 0, len(inboxes))
+	for _, inbox := range inboxes {
+		owner, err := c.store.InboxOwner(inbox.ID)
+		if err != nil {
+			return nil, err
+		}
+		if owner == c.tenant {
+			owned = append(owned, c.withoutMarkers(inbox))
+		}
+	}
+	return owned, nil
+}
+
+// CreateInbox создает ящик пользователя с именем по умолчанию
+func (c *TenantClient) CreateInbox() (*Inbox, error) {
+	return c.CreateInboxWithOptions(CreateInboxOptions{})
+}
+
+// CreateInboxWithOptions создает ящик с префиксом пользователя в имени и
+// тегах и записывает владельца. Если записать владельца не удалось, ящик
+// удаляется.
+func (c *TenantClient) CreateInboxWithOptions(opts CreateInboxOptions) (*Inbox, error) {
+	name := opts.Name
+	if name == "" {
+		name = fmt.Sprintf("inbox-%d", time.Now().UnixMilli())
+	}
+	opts.Name = c.tenant + tenantNameSeparator + name
+
+	// Чужие теги prefix: отбрасываются, чтобы ящик не выдавал себя за чужой
+	tags := []string{tenantTagPrefix + c.tenant}
+	for _, tag := range opts.Tags {
+		if !strings.HasPrefix(tag, tenantTagPrefix) {
+			tags = append(tags, tag)
+		}
+	}
+	opts.Tags = tags
+
+	inbox, err := c.client.CreateInboxWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.store.SetInboxOwner(inbox.ID, c.tenant); err != nil {
+		c.client.DeleteInbox(inbox.ID)
+		return nil, fmt.Errorf("не удалось сохранить владельца ящика %s: %w", inbox.ID, err)
+	}
+	own := c.withoutMarkers(*inbox)
+	return &own, nil
+}
+
+// withoutMarkers убирает из имени и тегов ящика префикс пользователя
+func (c *TenantClient) withoutMarkers(inbox Inbox) Inbox {
+	inbox.Name = strings.TrimPrefix(inbox.Name, c.tenant+tenantNameSeparator)
+	tags := make([]string, 0, len(inbox.Tags))
+	for _, tag := range inbox.Tags {
+		if tag != tenantTagPrefix+c.tenant {
+			tags = append(tags, tag)
+		}
+	}
+	inbox.Tags = tags
+	return inbox
+}
+
+// DeleteInbox удаляет ящик пользователя и запись о владельце
+func (c *TenantClient) DeleteInbox(inboxID string) error {
+	if err := c.checkOwner(inboxID); err != nil {
+		return err
+	}
+	// Ящик, уже удаленный в MailSlurp, тоже забываем
+	err := c.client.DeleteInbox(inboxID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if storeErr := c.store.RemoveInboxOwner(inboxID); storeErr != nil {
+		return storeErr
+	}
+	return err
+}
+
+// SendEmail отправляет письмо из ящика пользователя
+func (c *TenantClient) SendEmail(inboxID, to, subject, body string) error {
+	if err := c.checkOwner(inboxID); err != nil {
+		return err
+	}
+	return c.client.SendEmail(inboxID, to, subject, body)
+}
+
+// SendHTMLEmail отправляет HTML письмо из ящика пользователя
+func (c *TenantClient) SendHTMLEmail(inboxID, to, subject, html string) error {
+	if err := c.checkOwner(inboxID); err != nil {
+		return err
+	}
+	return sendHTMLEmail(c.client, inboxID, to, subject, html)
+}
+
+// WaitForLatestEmail ждет письмо в ящике пользователя
+func (c *TenantClient) WaitForLatestEmail(inboxID string, timeout time.Duration) (*Email, error) {
+	if err := c.checkOwner(inboxID); err != nil {
+		return nil, err
+	}
+	return c.client.WaitForLatestEmail(inboxID, timeout)
+}
+
+// GetEmails возвращает письма ящика пользователя
+func (c *TenantClient) GetEmails(inboxID string) ([]Email, error) {
+	if err := c.checkOwner(inboxID); err != nil {
+		return nil, err
+	}
+	return c.client.GetEmails(inboxID)
+}
+
+// DeleteAllInboxEmails очищает ящик пользователя, если обернутый клиент это
+// поддерживает, иначе возвращает ErrCleanupNotSupported
+func (c *TenantClient) DeleteAllInboxEmails(inboxID string) error {
+	if err := c.checkOwner(inboxID); err != nil {
+		return err
+	}
+	return deleteAllInboxEmails(c.client, inboxID)
+}
+
+// GetAPIKey возвращает API ключ обернутого клиента
+func (c *TenantClient) GetAPIKey() string {
+	return c.client.GetAPIKey()
+}
+
+// GetBaseURL возвращает базовый URL обернутого клиента
+func (c *TenantClient) GetBaseURL() string {
+	return c.client.GetBaseURL()
+}
+
+// checkOwner возвращает ErrInboxNotOwned, если ящик не принадлежит пользователю
+func (c *TenantClient) checkOwner(inboxID string) error {
+	owner, err := c.store.InboxOwner(inboxID)
+	if err != nil {
+		return err
+	}
+	if owner != c.tenant {
+		return &notOwnedError{inboxID: inboxID}
+	}
+	return nil
+}
+
+// notOwnedError - ошибка доступа к чужому ящику
+type notOwnedError struct {
+	inboxID string
+}
+
+func (e *notOwnedError) Error() string {
+	return fmt.Sprintf("ящик %s: %v", e.inboxID, ErrInboxNotOwned)
+}
+
+// Unwrap позволяет проверять ошибку через errors.Is(err, ErrInboxNotOwned)
+// и errors.Is(err, ErrNotFound)
+func (e *notOwnedError) Unwrap() []error {
+	return []error{ErrInboxNotOwned, ErrNotFound}
+}
@@ -0,0 +1,105 @@
+package mailslurp_test
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	. "mailslurp-example"
+	"mailslurp-example/mailslurptest"
+)
+
+// TestTenantClientIsolation проверяет, что пользователи не видят и не трогают чужие ящики
+func TestTenantClientIsolation(t *testing.T) {
+	mock := mailslurptest.NewMockClient(Inbox{ID: "legacy", Name: "ualice-spoofed"})
+	store := NewMemoryOwnershipStore()
+	alice, err := NewTenantClient(mock, "ualice", store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, _ := NewTenantClient(mock, "ubob", store)
+
+	inbox, err := alice.CreateInboxWithOptions(CreateInboxOptions{Name: "signup", Tags: []string{"ci", "prefix:ubob"}})
+	if err != nil {
+		t.Fatalf("Ошибка при создании ящика: %v", err)
+	}
+	// Пользователь видит ящик без префикса, а в API он создан с префиксом
+	if inbox.Name != "signup" || len(inbox.Tags) != 1 || inbox.Tags[0] != "ci" {
+		t.Errorf("Неверное имя или теги ящика: %+v", inbox)
+	}
+	raw, _ := mock.GetInboxes()
+	if created := raw[len(raw)-1]; created.Name != "ualice-signup" || TenantPrefix(created) != "ualice" ||
+		HasTag(created.Tags, "prefix:ubob") || !HasTag(created.Tags, "ci") {
+		t.Errorf("Неверное имя, префикс или теги ящика в API: %+v", created)
+	}
+	bobInbox, _ := bob.CreateInbox()
+
+	// Ящик с подходящим именем, но без записи о владельце, не считается своим
+	inboxes, _ := alice.GetInboxes()
+	if len(inboxes) != 1 || inboxes[0].ID != inbox.ID || inboxes[0].Name != "signup" {
+		t.Errorf("Alice должна видеть только свой ящик: %+v", inboxes)
+	}
+
+	for name, err := range map[string]error{
+		"GetEmails":   func() error { _, err := bob.GetEmails(inbox.ID); return err }(),
+		"SendEmail":   bob.SendEmail(inbox.ID, "x@example.com", "тема", "тело"),
+		"DeleteInbox": bob.DeleteInbox(inbox.ID),
+		"Legacy":      alice.DeleteInbox("legacy"),
+	} {
+		if !errors.Is(err, ErrInboxNotOwned) || !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: ожидалась ErrInboxNotOwned и ErrNotFound, получено: %v", name, err)
+		}
+	}
+	if mock.CallCount("DeleteInbox") != 0 || mock.CallCount("SendEmail") != 0 {
+		t.Error("Запросы к чужим ящикам не должны доходить до API")
+	}
+
+	if err := alice.SendEmail(inbox.ID, bobInbox.EmailAddress, "Привет", "тело"); err != nil {
+		t.Errorf("Отправка из своего ящика: %v", err)
+	}
+	if err := alice.DeleteInbox(inbox.ID); err != nil {
+		t.Errorf("Удаление своего ящика: %v", err)
+	}
+	if owner, _ := store.InboxOwner(inbox.ID); owner != "" {
+		t.Errorf("Запись о владельце должна быть удалена, получено %q", owner)
+	}
+
+	if _, err := NewTenantClient(mock, "u-bad", store); !errors.Is(err, ErrInvalidTenant) {
+		t.Errorf("Ожидалась ErrInvalidTenant, получено: %v", err)
+	}
+}
+
+// TestTenantClientHidesInnerClient проверяет, что обернутый клиент нельзя
+// получить из TenantClient и обойти проверку владельца
+func TestTenantClientHidesInnerClient(t *testing.T) {
+	typ := reflect.TypeOf(TenantClient{})
+	for i := 0; i < typ.NumField(); i++ {
+		if field := typ.Field(i); field.IsExported() || field.Anonymous {
+			t.Errorf("Поле %s открывает обернутый клиент", field.Name)
+		}
+	}
+}
+
+// TestFileOwnershipStore проверяет сохранение владельцев между запусками
+func TestFileOwnershipStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "owners.json")
+	store, err := OpenFileOwnershipStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetInboxOwner("inbox-1", "ualice"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetInboxOwner("inbox-1", "ubob"); !errors.Is(err, ErrInboxNotOwned) {
+		t.Errorf("Чужой ящик нельзя переназначить, получено: %v", err)
+	}
+
+	reopened, err := OpenFileOwnershipStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner, _ := reopened.InboxOwner("inbox-1"); owner != "ualice" {
+		t.Errorf("Владелец не сохранен: %q", owner)
+	}
+}
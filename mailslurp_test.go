@@ -1,117 +1,163 @@
-package main
+package mailslurp_test
 
 import (
-	"fmt"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	. "mailslurp-example"
+	"mailslurp-example/mailslurptest"
+	"mailslurp-example/mailtest"
 )
 
-// Тестовый API ключ - замените на свой
-const testAPIKey = "fac5b6d2020a14edc74b54e9f1b09513df1c2ca3fc1901ec9e5933df11052d5a"
+// envRecordCassettes включает запись кассет для тестов с реальным API
+const envRecordCassettes = "NEUROMAIL_RECORD"
 
-// TestCreateInbox тестирует создание почтового ящика
-func TestCreateInbox(t *testing.T) {
-	client := NewMailSlurpClient(testAPIKey)
-	
-	inbox, err := client.CreateInbox()
+// newLiveTestClient создает клиент для тестов с реальным API.
+// Если есть кассета testdata/cassettes/<тест>.json, запросы воспроизводятся из нее
+// без сети. С NEUROMAIL_RECORD=1 запросы к реальному API записываются в кассету.
+// Ключ берется из профиля конфигурации или NEUROMAIL_API_KEY;
+// если ключа и кассеты нет, тест пропускается.
+func newLiveTestClient(t *testing.T) MailSlurpClient {
+	t.Helper()
+	cassettePath := filepath.Join("testdata", "cassettes", t.Name()+".json")
+	opts := CassetteOptions{IgnoreQuery: []string{"timeout"}}
+
+	if os.Getenv(envRecordCassettes) == "" {
+		if _, err := os.Stat(cassettePath); err == nil {
+			cassette, err := NewCassetteTransport(cassettePath, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return NewMailSlurpClient("recorded-api-key", WithTransport(cassette))
+		}
+	}
+
+	profile, err := ResolveProfile("")
+	if errors.Is(err, ErrNoAPIKey) {
+		t.Skip("API ключ не задан, тест с реальным API пропущен")
+	}
 	if err != nil {
-		t.Fatalf("Ошибка при создании почтового ящика: %v", err)
+		t.Fatalf("Ошибка конфигурации: %v", err)
 	}
-	
+	if os.Getenv(envRecordCassettes) == "" {
+		return NewMailSlurpClientFromProfile(profile)
+	}
+
+	opts.Mode = CassetteRecord
+	cassette, err := NewCassetteTransport(cassettePath, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := cassette.Save(); err != nil {
+			t.Errorf("Не удалось сохранить кассету: %v", err)
+		}
+	})
+	return NewMailSlurpClientFromProfile(profile, WithTransport(cassette))
+}
+
+// TestCreateInbox тестирует создание почтового ящика; ящик удаляется
+// после теста через mailtest.Inbox
+func TestCreateInbox(t *testing.T) {
+	inbox := mailtest.Inbox(t, newLiveTestClient(t))
+
 	if inbox.ID == "" {
 		t.Error("ID почтового ящика пустой")
 	}
-	
+
 	if inbox.EmailAddress == "" {
 		t.Error("Email адрес почтового ящика пустой")
 	}
-	
-	fmt.Printf("Создан тестовый почтовый ящик: %s\n", inbox.EmailAddress)
-	
-	// Удаляем созданный ящик
-	err = client.DeleteInbox(inbox.ID)
-	if err != nil {
-		t.Logf("Предупреждение: не удалось удалить почтовый ящик: %v", err)
-	}
+
+	t.Logf("Создан тестовый почтовый ящик: %s", inbox.EmailAddress)
 }
 
-// TestSendAndReceiveEmail тестирует отправку и получение письма
-func TestSendAndReceiveEmail(t *testing.T) {
-	client := NewMailSlurpClient(testAPIKey)
-	
-	// Создаем почтовый ящик
-	inbox, err := client.CreateInbox()
+// TestCreateInboxWithOptions проверяет передачу параметров ящика на фейковом сервере
+func TestCreateInboxWithOptions(t *testing.T) {
+	server := newFakeMailSlurpServer(t)
+	client := server.client()
+	
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	inbox, err := client.CreateInboxWithOptions(CreateInboxOptions{
+		Name:        "tenant1_signup",
+		Description: "Ящик для теста регистрации",
+		Tags:        []string{"e2e", "signup"},
+		LocalPart:   "signup",
+		Domain:      "example.com",
+		ExpiresAt:   expiresAt,
+		Favourite:   true,
+		InboxType:   InboxTypeSMTP,
+	})
 	if err != nil {
 		t.Fatalf("Ошибка при создании почтового ящика: %v", err)
 	}
 	
-	defer func() {
-		// Удаляем почтовый ящик после теста
-		err := client.DeleteInbox(inbox.ID)
-		if err != nil {
-			t.Logf("Предупреждение: не удалось удалить почтовый ящик: %v", err)
-		}
-	}()
-	
-	// Отправляем письмо
-	subject := "Тестовое письмо"
-	body := "Это тестовое письмо для проверки API"
-	
-	err = client.SendEmail(inbox.ID, inbox.EmailAddress, subject, body)
-	if err != nil {
-		t.Fatalf("Ошибка при отправке письма: %v", err)
+	q := server.lastRequest().URL.Query()
+	if q.Get("emailAddress") != "signup@example.com" || q.Get("inboxType") != "SMTP_INBOX" || len(q["tags"]) != 2 {
+		t.Errorf("Неверные параметры запроса: %s", q.Encode())
 	}
 	
-	// Ждем и получаем письмо
-	email, err := client.WaitForLatestEmail(inbox.ID, 30*time.Second)
-	if err != nil {
-		t.Fatalf("Ошибка при ожидании письма: %v", err)
+	if inbox.EmailAddress != "signup@example.com" || inbox.Name != "tenant1_signup" ||
+		inbox.Description != "Ящик для теста регистрации" || !inbox.Favourite || inbox.InboxType != InboxTypeSMTP {
+		t.Errorf("Неверный почтовый ящик: %+v", inbox)
 	}
-	
-	// Проверяем содержимое письма
-	if email.Subject != subject {
-		t.Errorf("Тема письма не совпадает: ожидалось '%s', получено '%s'", subject, email.Subject)
+	if len(inbox.Tags) != 2 || inbox.Tags[0] != "e2e" {
+		t.Errorf("Неверные теги: %v", inbox.Tags)
 	}
-	
-	if email.Body != body {
-		t.Errorf("Тело письма не совпадает: ожидалось '%s', получено '%s'", body, email.Body)
+	if inbox.ExpiresAt == nil || !inbox.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("Неверное время удаления: %v", inbox.ExpiresAt)
 	}
 	
-	// Получаем список писем
-	emails, err := client.GetEmails(inbox.ID)
+	// Поля должны сохраниться при получении списка ящиков
+	inboxes, err := client.GetInboxes()
 	if err != nil {
-		t.Fatalf("Ошибка при получении списка писем: %v", err)
+		t.Fatalf("Ошибка при получении списка почтовых ящиков: %v", err)
+	}
+	if len(inboxes) != 1 || inboxes[0].Name != inbox.Name || len(inboxes[0].Tags) != 2 {
+		t.Errorf("Неверный список ящиков: %+v", inboxes)
 	}
 	
-	if len(emails) == 0 {
-		t.Error("Список писем пуст")
+	if _, err := client.CreateInboxWithOptions(CreateInboxOptions{LocalPart: "signup"}); err == nil {
+		t.Error("Ожидалась ошибка для LocalPart без Domain")
 	}
 }
 
-// TestMockMailSlurpClient демонстрирует использование интерфейса с моком
-func TestMockMailSlurpClient(t *testing.T) {
-	// Создаем мок клиента
-	mockClient := &MockMailSlurpClient{
-		inboxes: []Inbox{
-			{
-				ID:           "mock-inbox-id",
-				EmailAddress: "mock@example.com",
-				CreatedAt:    time.Now(),
-			},
-		},
-		emails: map[string][]Email{
-			"mock-inbox-id": {
-				{
-					ID:      "mock-email-id",
-					Subject: "Mock Subject",
-					Body:    "Mock Body",
-					From:    "sender@example.com",
-					To:      []string{"mock@example.com"},
-					Created: time.Now(),
-				},
-			},
-		},
+// TestCreateInboxDefaultTTL проверяет время жизни ящика по умолчанию из профиля
+func TestCreateInboxDefaultTTL(t *testing.T) {
+	server := newFakeMailSlurpServer(t)
+	client := NewMailSlurpClientFromProfile(&Profile{
+		APIKey:     server.apiKey,
+		BaseURL:    server.URL,
+		DefaultTTL: Duration(30 * time.Minute),
+	})
+	
+	if _, err := client.CreateInbox(); err != nil {
+		t.Fatalf("Ошибка при создании почтового ящика: %v", err)
+	}
+	if got := server.lastRequest().URL.Query().Get("expiresIn"); got != "1800000" {
+		t.Errorf("Неверный expiresIn: %s", got)
 	}
+}
+
+// TestMockClient демонстрирует использование интерфейса с моком
+func TestMockClient(t *testing.T) {
+	// Создаем мок клиента
+	mockClient := mailslurptest.NewMockClient(Inbox{
+		ID:           "mock-inbox-id",
+		EmailAddress: "mock@example.com",
+		CreatedAt:    time.Now(),
+	})
+	mockClient.Deliver("mock-inbox-id", Email{
+		ID:      "mock-email-id",
+		Subject: "Mock Subject",
+		Body:    "Mock Body",
+		From:    "sender@example.com",
+		To:      []string{"mock@example.com"},
+		Created: time.Now(),
+	})
 	
 	// Получаем список почтовых ящиков
 	inboxes, err := mockClient.GetInboxes()
@@ -137,74 +183,3 @@ func TestMockMailSlurpClient(t *testing.T) {
 		t.Errorf("Неверная тема письма: ожидалось 'Mock Subject', получено '%s'", emails[0].Subject)
 	}
 }
-
-// MockMailSlurpClient представляет собой мок реализацию интерфейса MailSlurpClient для тестирования
-type MockMailSlurpClient struct {
-	inboxes []Inbox
-	emails  map[string][]Email
-}
-
-func (m *MockMailSlurpClient) GetInboxes() ([]Inbox, error) {
-	return m.inboxes, nil
-}
-
-func (m *MockMailSlurpClient) CreateInbox() (*Inbox, error) {
-	inbox := &Inbox{
-		ID:           fmt.Sprintf("mock-inbox-%d", len(m.inboxes)+1),
-		EmailAddress: fmt.Sprintf("mock%d@example.com", len(m.inboxes)+1),
-		CreatedAt:    time.Now(),
-	}
-	m.inboxes = append(m.inboxes, *inbox)
-	return inbox, nil
-}
-
-func (m *MockMailSlurpClient) DeleteInbox(inboxID string) error {
-	for i, inbox := range m.inboxes {
-		if inbox.ID == inboxID {
-			m.inboxes = append(m.inboxes[:i], m.inboxes[i+1:]...)
-			delete(m.emails, inboxID)
-			return nil
-		}
-	}
-	return fmt.Errorf("почтовый ящик с ID %s не найден", inboxID)
-}
-
-func (m *MockMailSlurpClient) SendEmail(inboxID, to, subject, body string) error {
-	email := Email{
-		ID:      fmt.Sprintf("mock-email-%d", len(m.emails[inboxID])+1),
-		Subject: subject,
-		Body:    body,
-		From:    "sender@example.com",
-		To:      []string{to},
-		Created: time.Now(),
-	}
-	
-	if m.emails == nil {
-		m.emails = make(map[string][]Email)
-	}
-	
-	m.emails[inboxID] = append(m.emails[inboxID], email)
-	return nil
-}
-
-func (m *MockMailSlurpClient) WaitForLatestEmail(inboxID string, timeout time.Duration) (*Email, error) {
-	if emails, ok := m.emails[inboxID]; ok && len(emails) > 0 {
-		return &emails[len(emails)-1], nil
-	}
-	return nil, fmt.Errorf("письма не найдены для почтового ящика с ID %s", inboxID)
-}
-
-func (m *MockMailSlurpClient) GetEmails(inboxID string) ([]Email, error) {
-	if emails, ok := m.emails[inboxID]; ok {
-		return emails, nil
-	}
-	return []Email{}, nil
-}
-
-func (m *MockMailSlurpClient) GetAPIKey() string {
-	return "mock-api-key"
-}
-
-func (m *MockMailSlurpClient) GetBaseURL() string {
-	return "https://mock-api.mailslurp.com"
-} 
\ No newline at end of file
@@ -0,0 +1,554 @@
+package mailslurp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidTraceparent - заголовок traceparent не соответствует формату W3C Trace Context
+var ErrInvalidTraceparent = errors.New("неверный формат traceparent")
+
+// TraceID - идентификатор трассировки
+type TraceID [16]byte
+
+// String возвращает идентификатор в hex
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+// IsValid сообщает, что идентификатор не нулевой
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+// SpanID - идентификатор спана
+type SpanID [8]byte
+
+// String возвращает идентификатор в hex
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// IsValid сообщает, что идентификатор не нулевой
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+// SpanContext - данные трассировки, которые передаются между сервисами
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid сообщает, что контекст содержит идентификаторы трассировки и спана
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent возвращает значение заголовка traceparent
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent разбирает заголовок traceparent версии 00
+func ParseTraceparent(value string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, ErrInvalidTraceparent
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, ErrInvalidTraceparent
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil || !sc.IsValid() {
+		return sc, ErrInvalidTraceparent
+	}
+	sc.Sampled = flags&1 == 1
+	return sc, nil
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext добавляет в контекст удаленный родительский спан,
+// например полученный из входящего заголовка traceparent
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext возвращает данные текущего спана из контекста
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.data.SpanContext
+	}
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
+
+type spanKey struct{}
+
+// SpanFromContext возвращает текущий спан из контекста или nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SpanStatus - итог операции спана
+type SpanStatus int
+
+const (
+	SpanStatusUnset SpanStatus = iota
+	SpanStatusOK
+	SpanStatusError
+)
+
+// String возвращает название статуса
+func (s SpanStatus) String() string {
+	switch s {
+	case SpanStatusOK:
+		return "OK"
+	case SpanStatusError:
+		return "ERROR"
+	}
+	return "UNSET"
+}
+
+// SpanData - завершенный спан, который получают экспортеры
+type SpanData struct {
+	Name          string                 `json:"name"`
+	SpanContext   SpanContext            `json:"-"`
+	Parent        SpanID                 `json:"-"`
+	Start         time.Time              `json:"start"`
+	End           time.Time              `json:"end"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	Status        SpanStatus             `json:"-"`
+	StatusMessage string                 `json:"statusMessage,omitempty"`
+}
+
+// Duration возвращает длительность спана
+func (d SpanData) Duration() time.Duration {
+	return d.End.Sub(d.Start)
+}
+
+// MarshalJSON добавляет идентификаторы и статус в читаемом виде
+func (d SpanData) MarshalJSON() ([]byte, error) {
+	type plain SpanData
+	out := struct {
+		plain
+		TraceID  string `json:"traceId"`
+		SpanID   string `json:"spanId"`
+		ParentID string `json:"parentSpanId,omitempty"`
+		Duration string `json:"duration"`
+		Status   string `json:"status"`
+	}{
+		plain:    plain(d),
+		TraceID:  d.SpanContext.TraceID.String(),
+		SpanID:   d.SpanContext.SpanID.String(),
+		Duration: d.Duration().String(),
+		Status:   d.Status.String(),
+	}
+	if d.Parent.IsValid() {
+		out.ParentID = d.Parent.String()
+	}
+	return json.Marshal(out)
+}
+
+// Span - незавершенная операция трассировки
+type Span struct {
+	tracer *Tracer
+	mu     sync.Mutex
+	data   SpanData
+	ended  bool
+}
+
+// SpanContext возвращает идентификаторы спана
+func (s *Span) SpanContext() SpanContext {
+	return s.data.SpanContext
+}
+
+// SetAttribute задает атрибут спана. Поддерживаются string, bool, int, int64 и float64.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.data.Attributes[key] = value
+	}
+}
+
+// SetStatus задает итог операции
+func (s *Span) SetStatus(status SpanStatus, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.data.Status = status
+		s.data.StatusMessage = message
+	}
+}
+
+// RecordError отмечает спан как завершившийся ошибкой
+func (s *Span) RecordError(err error) {
+	if err != nil {
+		s.SetStatus(SpanStatusError, err.Error())
+	}
+}
+
+// End завершает спан и передает его экспортерам. Повторные вызовы игнорируются.
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = s.tracer.now()
+	data := s.data
+	s.mu.Unlock()
+
+	s.tracer.export(data)
+}
+
+// SpanExporter отправляет завершенные спаны во внешнюю систему
+type SpanExporter interface {
+	ExportSpans(ctx context.Context, spans []SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+// Tracer создает спаны и передает их экспортерам
+type Tracer struct {
+	exporters []SpanExporter
+	now       func() time.Time
+	// OnError вызывается при ошибке экспорта, по умолчанию ошибка пишется в slog
+	OnError func(err error)
+}
+
+// NewTracer создает трассировщик с заданными экспортерами
+func NewTracer(exporters ...SpanExporter) *Tracer {
+	return &Tracer{exporters: exporters, now: time.Now}
+}
+
+// Start начинает спан. Родителем становится спан из ctx (локальный или
+// удаленный из ContextWithSpanContext), иначе начинается новая трассировка.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	parent := SpanContextFromContext(ctx)
+	sc := SpanContext{TraceID: parent.TraceID, Sampled: true}
+	if parent.IsValid() {
+		sc.Sampled = parent.Sampled
+	} else {
+		rand.Read(sc.TraceID[:])
+	}
+	rand.Read(sc.SpanID[:])
+
+	span := &Span{
+		tracer: t,
+		data: SpanData{
+			Name:        name,
+			SpanContext: sc,
+			Parent:      parent.SpanID,
+			Start:       t.now(),
+			Attributes:  map[string]interface{}{},
+		},
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Shutdown завершает работу экспортеров, отправляя накопленные спаны
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, exporter := range t.exporters {
+		if err := exporter.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *Tracer) export(data SpanData) {
+	if !data.SpanContext.Sampled {
+		return
+	}
+	for _, exporter := range t.exporters {
+		if err := exporter.ExportSpans(context.Background(), []SpanData{data}); err != nil {
+			if t.OnError != nil {
+				t.OnError(err)
+			} else {
+				slog.Warn("не удалось экспортировать спан", "span", data.Name, "error", err)
+			}
+		}
+	}
+}
+
+// WithTracer включает трассировку: каждый вызов API становится спаном с
+// атрибутами операции, ящика, письма, статуса и количества повторов.
+// Контекст трассировки передается серверу в заголовке traceparent.
+func WithTracer(tracer *Tracer) ClientOption {
+	return func(c *DefaultMailSlurpClient) {
+		c.tracer = tracer
+	}
+}
+
+// startSpan начинает спан вызова API, если трассировка включена
+func (c *DefaultMailSlurpClient) startSpan(ctx context.Context, call apiCall) (context.Context, *Span) {
+	if c.tracer == nil {
+		return ctx, nil
+	}
+	ctx, span := c.tracer.Start(ctx, "MailSlurp "+call.operation)
+	span.SetAttribute("mailslurp.operation", call.operation)
+	span.SetAttribute("http.request.method", call.method)
+	span.SetAttribute("url.path", call.path)
+	return ctx, span
+}
+
+// endSpan записывает итог вызова API и завершает спан
+func endSpan(span *Span, info *RequestInfo, resp *http.Response, err error) {
+	if span == nil {
+		return
+	}
+	if info.InboxID != "" {
+		span.SetAttribute("mailslurp.inbox_id", info.InboxID)
+	}
+	if info.EmailID != "" {
+		span.SetAttribute("mailslurp.email_id", info.EmailID)
+	}
+	span.SetAttribute("mailslurp.attempts", info.Attempts)
+	span.SetAttribute("mailslurp.retries", max(info.Attempts-1, 0))
+	if resp != nil {
+		span.SetAttribute("http.response.status_code", resp.StatusCode)
+	}
+	if err != nil {
+		span.RecordError(err)
+	} else {
+		span.SetStatus(SpanStatusOK, "")
+	}
+	span.End()
+}
+
+// InMemoryExporter хранит спаны в памяти, используется в тестах
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+// NewInMemoryExporter создает пустой экспортер в память
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// ExportSpans сохраняет спаны
+func (e *InMemoryExporter) ExportSpans(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+// Shutdown ничего не делает
+func (e *InMemoryExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// Spans возвращает копию сохраненных спанов в порядке завершения
+func (e *InMemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]SpanData(nil), e.spans...)
+}
+
+// Reset удаляет сохраненные спаны
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
+
+// StdoutExporter пишет каждый спан строкой JSON
+type StdoutExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewStdoutExporter создает экспортер в w (обычно os.Stdout)
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	return &StdoutExporter{w: w}
+}
+
+// ExportSpans записывает спаны
+func (e *StdoutExporter) ExportSpans(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	enc := json.NewEncoder(e.w)
+	for _, span := range spans {
+		if err := enc.Encode(span); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Shutdown ничего не делает
+func (e *StdoutExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// defaultOTLPBatchSize - количество спанов, после которого OTLPExporter отправляет пакет
+const defaultOTLPBatchSize = 64
+
+// OTLPExporter отправляет спаны в коллектор OpenTelemetry по OTLP/HTTP в формате JSON.
+// Спаны накапливаются пакетами, остаток отправляется в Shutdown.
+type OTLPExporter struct {
+	// Endpoint - адрес коллектора, например http://localhost:4318
+	Endpoint string
+	// Headers - дополнительные заголовки, например для авторизации
+	Headers map[string]string
+	// ServiceName - значение атрибута ресурса service.name
+	ServiceName string
+	// BatchSize - размер пакета, по умолчанию 64
+	BatchSize int
+	Client    *http.Client
+
+	mu      sync.Mutex
+	pending []SpanData
+}
+
+// NewOTLPExporter создает экспортер в коллектор по адресу endpoint
+func NewOTLPExporter(endpoint, serviceName string) *OTLPExporter {
+	return &OTLPExporter{
+		Endpoint:    strings.TrimRight(endpoint, "/"),
+		ServiceName: serviceName,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ExportSpans добавляет спаны в пакет и отправляет его, когда он заполнен
+func (e *OTLPExporter) ExportSpans(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	e.pending = append(e.pending, spans...)
+	batchSize := e.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultOTLPBatchSize
+	}
+	if len(e.pending) < batchSize {
+		e.mu.Unlock()
+		return nil
+	}
+	batch := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+	return e.send(ctx, batch)
+}
+
+// Flush отправляет накопленные спаны
+func (e *OTLPExporter) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	batch := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+	return e.send(ctx, batch)
+}
+
+// Shutdown отправляет накопленные спаны
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	return e.Flush(ctx)
+}
+
+func (e *OTLPExporter) send(ctx context.Context, spans []SpanData) error {
+	payload, err := json.Marshal(otlpRequest(e.ServiceName, spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Endpoint+"/v1/traces", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range e.Headers {
+		req.Header.Set(name, value)
+	}
+
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("коллектор OTLP вернул %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// otlpRequest формирует тело ExportTraceServiceRequest в JSON-кодировке OTLP
+func otlpRequest(serviceName string, spans []SpanData) map[string]interface{} {
+	otlpSpans := make([]map[string]interface{}, len(spans))
+	for i, span := range spans {
+		s := map[string]interface{}{
+			"traceId":           span.SpanContext.TraceID.String(),
+			"spanId":            span.SpanContext.SpanID.String(),
+			"name":              span.Name,
+			"kind":              3, // SPAN_KIND_CLIENT
+			"startTimeUnixNano": strconv.FormatInt(span.Start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(span.End.UnixNano(), 10),
+			"attributes":        otlpAttributes(span.Attributes),
+		}
+		if span.Parent.IsValid() {
+			s["parentSpanId"] = span.Parent.String()
+		}
+		if span.Status != SpanStatusUnset {
+			s["status"] = map[string]interface{}{"code": int(span.Status), "message": span.StatusMessage}
+		}
+		otlpSpans[i] = s
+	}
+
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": otlpAttributes(map[string]interface{}{"service.name": serviceName}),
+			},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]interface{}{"name": "mailslurp-client"},
+				"spans": otlpSpans,
+			}},
+		}},
+	}
+}
+
+func otlpAttributes(attrs map[string]interface{}) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(attrs))
+	for _, key := range sortedKeys(attrs) {
+		var value map[string]interface{}
+		switch v := attrs[key].(type) {
+		case string:
+			value = map[string]interface{}{"stringValue": v}
+		case bool:
+			value = map[string]interface{}{"boolValue": v}
+		case int:
+			value = map[string]interface{}{"intValue": strconv.Itoa(v)}
+		case int64:
+			value = map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+		case float64:
+			value = map[string]interface{}{"doubleValue": v}
+		default:
+			value = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+		}
+		out = append(out, map[string]interface{}{"key": key, "value": value})
+	}
+	return out
+}
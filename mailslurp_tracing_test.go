@@ -0,0 +1,151 @@
+package mailslurp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "mailslurp-example"
+)
+
+// TestClientTracing проверяет спаны вызовов API и передачу контекста трассировки
+func TestClientTracing(t *testing.T) {
+	server := newFakeMailSlurpServer(t)
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	client := NewMailSlurpClientFromProfile(&Profile{APIKey: server.apiKey, BaseURL: server.URL},
+		WithTracer(tracer),
+		WithMiddleware(RetryMiddleware(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})))
+
+	ctx, root := tracer.Start(context.Background(), "регистрация пользователя")
+	traced := ClientWithContext(ctx, client)
+
+	inbox, err := traced.CreateInbox()
+	if err != nil {
+		t.Fatalf("Ошибка при создании почтового ящика: %v", err)
+	}
+	if got := server.lastRequest().Header.Get("traceparent"); !strings.Contains(got, root.SpanContext().TraceID.String()) {
+		t.Errorf("traceparent не содержит ID трассировки: %q", got)
+	}
+
+	server.deliver(inbox.ID, "app@example.com", "Подтверждение", "Код 123456")
+	server.failNext(http.StatusBadGateway, "BAD_GATEWAY")
+	email, err := traced.WaitForLatestEmail(inbox.ID, time.Second)
+	if err != nil {
+		t.Fatalf("Ошибка при ожидании письма: %v", err)
+	}
+	if _, err := traced.WaitForLatestEmail(inbox.ID, 10*time.Millisecond); err == nil {
+		t.Fatal("Ожидалась ошибка таймаута")
+	}
+	root.End()
+
+	spans := exporter.Spans()
+	if len(spans) != 4 {
+		t.Fatalf("Ожидалось 4 спана, получено %d", len(spans))
+	}
+	rootData := spans[3]
+	for _, span := range spans[:3] {
+		if span.SpanContext.TraceID != rootData.SpanContext.TraceID || span.Parent != rootData.SpanContext.SpanID {
+			t.Errorf("Спан %s не является потомком корневого", span.Name)
+		}
+	}
+
+	create, wait, timeout := spans[0], spans[1], spans[2]
+	if create.Name != "MailSlurp CreateInbox" || create.Attributes["mailslurp.inbox_id"] != inbox.ID || create.Status != SpanStatusOK {
+		t.Errorf("Неверный спан создания ящика: %+v", create)
+	}
+	if wait.Attributes["mailslurp.email_id"] != email.ID || wait.Attributes["mailslurp.retries"] != 1 ||
+		wait.Attributes["http.response.status_code"] != http.StatusOK {
+		t.Errorf("Неверный спан ожидания письма: %+v", wait)
+	}
+	if timeout.Status != SpanStatusError || timeout.Attributes["http.response.status_code"] != http.StatusRequestTimeout {
+		t.Errorf("Спан таймаута должен завершиться ошибкой: %+v", timeout)
+	}
+}
+
+// TestClientWithCanceledContext проверяет, что отмененный контекст прерывает вызов
+func TestClientWithCanceledContext(t *testing.T) {
+	server := newFakeMailSlurpServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := ClientWithContext(ctx, server.client())
+	if _, err := client.GetInboxes(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Ожидалась ошибка context.Canceled, получено: %v", err)
+	}
+}
+
+// TestTraceparent проверяет разбор и формирование заголовка traceparent
+func TestTraceparent(t *testing.T) {
+	const header = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := ParseTraceparent(header)
+	if err != nil {
+		t.Fatalf("Ошибка разбора traceparent: %v", err)
+	}
+	if !sc.Sampled || sc.Traceparent() != header {
+		t.Errorf("Неверный результат: %+v", sc)
+	}
+
+	for _, invalid := range []string{"", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01", "00-xyz-00f067aa0ba902b7-01"} {
+		if _, err := ParseTraceparent(invalid); !errors.Is(err, ErrInvalidTraceparent) {
+			t.Errorf("Ожидалась ошибка для %q", invalid)
+		}
+	}
+
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+	_, span := tracer.Start(ContextWithSpanContext(context.Background(), sc), "входящий запрос")
+	span.End()
+	got := exporter.Spans()[0]
+	if got.SpanContext.TraceID != sc.TraceID || got.Parent != sc.SpanID {
+		t.Errorf("Спан не продолжает удаленную трассировку: %+v", got)
+	}
+}
+
+// TestSpanExporters проверяет экспорт в stdout и OTLP
+func TestSpanExporters(t *testing.T) {
+	var received map[string]interface{}
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer collector.Close()
+
+	var stdout bytes.Buffer
+	otlp := NewOTLPExporter(collector.URL, "neuromail-e2e")
+	otlp.Headers = map[string]string{"Authorization": "Bearer token"}
+	tracer := NewTracer(NewStdoutExporter(&stdout), otlp)
+
+	_, span := tracer.Start(context.Background(), "MailSlurp GetInboxes")
+	span.SetAttribute("mailslurp.attempts", 2)
+	span.End()
+
+	if !strings.Contains(stdout.String(), `"name":"MailSlurp GetInboxes"`) || !strings.Contains(stdout.String(), `"status":"UNSET"`) {
+		t.Errorf("Неверный вывод stdout: %s", stdout.String())
+	}
+	if received != nil {
+		t.Fatal("OTLP экспортер должен копить спаны до заполнения пакета")
+	}
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Ошибка при завершении трассировщика: %v", err)
+	}
+
+	payload, _ := json.Marshal(received)
+	for _, want := range []string{`"stringValue":"neuromail-e2e"`, `"name":"MailSlurp GetInboxes"`,
+		`"key":"mailslurp.attempts","value":{"intValue":"2"}`, `"kind":3`} {
+		if !strings.Contains(string(payload), want) {
+			t.Errorf("В запросе OTLP нет %s: %s", want, payload)
+		}
+	}
+}
@@ -0,0 +1,562 @@
+package mailslurp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// YAMLError - ошибка разбора YAML с номером строки
+type YAMLError struct {
+	Line int
+	Msg  string
+}
+
+func (e *YAMLError) Error() string {
+	return fmt.Sprintf("yaml: строка %d: %s", e.Line, e.Msg)
+}
+
+// ParseYAML разбирает подмножество YAML, достаточное для сценариев:
+// вложенные словари и списки с отступами пробелами, скаляры без кавычек и
+// в кавычках, блоки | и >, однострочные [a, b] и {k: v}, комментарии #.
+// Словари возвращаются как map[string]interface{}, списки - как
+// []interface{}, скаляры - как string, bool, int, float64 или nil.
+//
+// Конструкции вне подмножества не угадываются, а возвращают YAMLError:
+// якоря, ссылки и теги (&a, *a, !tag), несколько документов в одном файле,
+// скаляры без кавычек с ": " внутри (key: a: b) и многострочные скаляры без
+// кавычек и блока |. В строках в двойных кавычках действуют
+// escape-последовательности YAML 1.2, включая \/ и \uXXXX.
+func ParseYAML(data []byte) (interface{}, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	p := &yamlParser{}
+	for i, raw := range strings.Split(text, "\n") {
+		p.lines = append(p.lines, newYAMLLine(i+1, raw))
+	}
+	p.skipDocumentStart()
+	for _, line := range p.lines[p.pos:] {
+		if line.indent == 0 && (isYAMLDocumentMarker(line.text, "---") || isYAMLDocumentMarker(line.text, "...")) {
+			return nil, p.errorf(line, "несколько документов в одном файле не поддерживаются")
+		}
+	}
+
+	line := p.peek()
+	if line == nil {
+		return nil, nil
+	}
+	if line.indent != 0 {
+		return nil, p.errorf(line, "документ должен начинаться без отступа")
+	}
+	value, err := p.parseNode(0)
+	if err != nil {
+		return nil, err
+	}
+	if line := p.peek(); line != nil {
+		return nil, p.errorf(line, "неожиданный отступ")
+	}
+	return value, nil
+}
+
+// yamlLine - строка документа; text - содержимое без отступа и комментария
+type yamlLine struct {
+	num    int
+	raw    string
+	indent int
+	text   string
+	tabs   bool
+}
+
+func newYAMLLine(num int, raw string) *yamlLine {
+	body := strings.TrimLeft(raw, " ")
+	line := &yamlLine{num: num, raw: raw, indent: len(raw) - len(body)}
+	line.tabs = strings.HasPrefix(body, "\t")
+	line.text = strings.TrimRight(stripYAMLComment(body), " \t")
+	return line
+}
+
+type yamlParser struct {
+	lines []*yamlLine
+	pos   int
+}
+
+func (p *yamlParser) errorf(line *yamlLine, format string, args ...interface{}) error {
+	return &YAMLError{Line: line.num, Msg: fmt.Sprintf(format, args...)}
+}
+
+// peek возвращает следующую значимую строку, пропуская пустые и комментарии
+func (p *yamlParser) peek() *yamlLine {
+	for ; p.pos < len(p.lines); p.pos++ {
+		if line := p.lines[p.pos]; line.text != "" || line.tabs {
+			return line
+		}
+	}
+	return nil
+}
+
+func (p *yamlParser) skipDocumentStart() {
+	if line := p.peek(); line != nil && line.indent == 0 && isYAMLDocumentMarker(line.text, "---") {
+		p.pos++
+	}
+}
+
+// isYAMLDocumentMarker проверяет, что строка - маркер документа --- или ...
+func isYAMLDocumentMarker(text, marker string) bool {
+	return text == marker || strings.HasPrefix(text, marker+" ")
+}
+
+// parseNode разбирает словарь, список или скаляр с отступом не меньше indent
+func (p *yamlParser) parseNode(indent int) (interface{}, error) {
+	line := p.peek()
+	if line == nil || line.indent < indent {
+		return nil, nil
+	}
+	if line.tabs {
+		return nil, p.errorf(line, "табуляция в отступе")
+	}
+	switch {
+	case isYAMLSequenceItem(line.text):
+		return p.parseSequence(line.indent)
+	case yamlKeyEnd(line.text) >= 0:
+		return p.parseMapping(line.indent)
+	default:
+		p.pos++
+		return p.parseInlineValue(line, line.text, line.indent)
+	}
+}
+
+func (p *yamlParser) parseSequence(indent int) (interface{}, error) {
+	list := []interface{}{}
+	for {
+		line := p.peek()
+		// Список под ключом словаря может иметь отступ ключа; тогда его
+		// заканчивает следующий ключ
+		if line == nil || line.indent < indent || (line.indent == indent && !isYAMLSequenceItem(line.text)) {
+			return list, nil
+		}
+		if line.indent > indent {
+			return nil, p.errorf(line, "ожидался элемент списка")
+		}
+
+		item := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+		if item == "" {
+			p.pos++
+			value, err := p.parseNode(indent + 1)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+			continue
+		}
+		// Элемент "- key: value" - словарь с отступом по первому ключу:
+		// строка заменяется ее содержимым после "- "
+		itemIndent := indent + len(line.text) - len(item)
+		if isYAMLSequenceItem(item) || yamlKeyEnd(item) >= 0 {
+			p.lines[p.pos] = &yamlLine{num: line.num, raw: line.raw, indent: itemIndent, text: item}
+			value, err := p.parseNode(itemIndent)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+			continue
+		}
+		p.pos++
+		value, err := p.parseInlineValue(line, item, indent)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+}
+
+func (p *yamlParser) parseMapping(indent int) (interface{}, error) {
+	m := map[string]interface{}{}
+	for {
+		line := p.peek()
+		if line == nil || line.indent < indent {
+			return m, nil
+		}
+		if line.tabs {
+			return nil, p.errorf(line, "табуляция в отступе")
+		}
+		if line.indent > indent {
+			return nil, p.errorf(line, "неожиданный отступ")
+		}
+		end := yamlKeyEnd(line.text)
+		if end < 0 || isYAMLSequenceItem(line.text) {
+			return nil, p.errorf(line, "ожидалась пара ключ: значение")
+		}
+		key, err := parseYAMLKey(strings.TrimSpace(line.text[:end]))
+		if err != nil {
+			return nil, p.errorf(line, "%v", err)
+		}
+		if _, ok := m[key]; ok {
+			return nil, p.errorf(line, "повторяющийся ключ %q", key)
+		}
+		rest := strings.TrimSpace(line.text[end+1:])
+		p.pos++
+
+		var value interface{}
+		if rest == "" {
+			// Значение на следующих строках; список может начинаться
+			// с того же отступа, что и ключ
+			next := p.peek()
+			switch {
+			case next == nil:
+			case next.indent > indent:
+				value, err = p.parseNode(next.indent)
+			case next.indent == indent && isYAMLSequenceItem(next.text):
+				value, err = p.parseSequence(indent)
+			}
+		} else {
+			value, err = p.parseInlineValue(line, rest, indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m[key] = value
+	}
+}
+
+// parseInlineValue разбирает значение, записанное в строке после ключа или
+// "- ". Блоки | и > читают следующие строки с отступом больше indent.
+func (p *yamlParser) parseInlineValue(line *yamlLine, text string, indent int) (interface{}, error) {
+	if text[0] == '|' || text[0] == '>' {
+		chomp := strings.TrimSpace(text[1:])
+		if chomp != "" && chomp != "-" && chomp != "+" {
+			return nil, p.errorf(line, "неподдерживаемый заголовок блока %q", text)
+		}
+		return p.parseBlockScalar(indent, text[0] == '>', chomp), nil
+	}
+	// Скаляр без кавычек занимает всю строку и может содержать запятые
+	if !strings.ContainsRune("[{\"'", rune(text[0])) {
+		if err := checkYAMLPlain(text); err != nil {
+			return nil, p.errorf(line, "%v", err)
+		}
+		// Продолжение скаляра на следующей строке иначе было бы принято
+		// за ошибку отступа
+		if next := p.peek(); next != nil && next.indent > indent && !isYAMLSequenceItem(next.text) && yamlKeyEnd(next.text) < 0 {
+			return nil, p.errorf(next, "многострочные значения без кавычек не поддерживаются; используйте | или >")
+		}
+		return parseYAMLScalar(text), nil
+	}
+	value, rest, err := parseYAMLFlow(text)
+	if err != nil {
+		return nil, p.errorf(line, "%v", err)
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, p.errorf(line, "лишние символы после значения: %q", rest)
+	}
+	return value, nil
+}
+
+// parseBlockScalar читает многострочный текст. Комментарии внутри блока
+// являются частью текста, поэтому используются исходные строки.
+func (p *yamlParser) parseBlockScalar(parent int, folded bool, chomp string) string {
+	var lines []string
+	blockIndent := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		raw := p.lines[p.pos].raw
+		trimmed := strings.TrimLeft(raw, " ")
+		if strings.TrimSpace(raw) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		indent := len(raw) - len(trimmed)
+		if indent <= parent || (blockIndent >= 0 && indent < blockIndent) {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = indent
+		}
+		lines = append(lines, raw[blockIndent:])
+	}
+
+	// Пустые строки в конце блока относятся к нему только при "+"
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	var text string
+	if folded {
+		var b strings.Builder
+		for i, line := range lines {
+			switch {
+			case i == 0:
+			case line == "":
+				// Пустая строка в сложенном блоке - перевод строки
+				b.WriteString("\n")
+			case lines[i-1] == "":
+			case strings.HasPrefix(line, " ") || strings.HasPrefix(lines[i-1], " "):
+				b.WriteString("\n")
+			default:
+				b.WriteString(" ")
+			}
+			b.WriteString(line)
+		}
+		text = b.String()
+	} else {
+		text = strings.Join(lines, "\n")
+	}
+
+	switch chomp {
+	case "-":
+		return text
+	case "+":
+		return text + strings.Repeat("\n", trailing+1)
+	default:
+		return text + "\n"
+	}
+}
+
+// parseYAMLFlow разбирает скаляр, [список] или {словарь} в начале s и
+// возвращает остаток строки
+func parseYAMLFlow(s string) (interface{}, string, error) {
+	s = strings.TrimLeft(s, " ")
+	if s == "" {
+		return nil, "", nil
+	}
+	switch s[0] {
+	case '[':
+		list := []interface{}{}
+		rest := strings.TrimLeft(s[1:], " ")
+		if strings.HasPrefix(rest, "]") {
+			return list, rest[1:], nil
+		}
+		for {
+			value, next, err := parseYAMLFlow(rest)
+			if err != nil {
+				return nil, "", err
+			}
+			list = append(list, value)
+			next = strings.TrimLeft(next, " ")
+			switch {
+			case strings.HasPrefix(next, ","):
+				rest = next[1:]
+			case strings.HasPrefix(next, "]"):
+				return list, next[1:], nil
+			default:
+				return nil, "", fmt.Errorf("не закрыт список [")
+			}
+		}
+	case '{':
+		m := map[string]interface{}{}
+		rest := strings.TrimLeft(s[1:], " ")
+		if strings.HasPrefix(rest, "}") {
+			return m, rest[1:], nil
+		}
+		for {
+			end := yamlKeyEnd(rest)
+			if end < 0 {
+				return nil, "", fmt.Errorf("ожидалась пара ключ: значение в {}")
+			}
+			key, err := parseYAMLKey(strings.TrimSpace(rest[:end]))
+			if err != nil {
+				return nil, "", err
+			}
+			value, next, err := parseYAMLFlow(rest[end+1:])
+			if err != nil {
+				return nil, "", err
+			}
+			m[key] = value
+			next = strings.TrimLeft(next, " ")
+			switch {
+			case strings.HasPrefix(next, ","):
+				rest = strings.TrimLeft(next[1:], " ")
+			case strings.HasPrefix(next, "}"):
+				return m, next[1:], nil
+			default:
+				return nil, "", fmt.Errorf("не закрыт словарь {")
+			}
+		}
+	case '"', '\'':
+		end := yamlQuoteEnd(s)
+		if end < 0 {
+			return nil, "", fmt.Errorf("не закрыта кавычка %c", s[0])
+		}
+		value, err := unquoteYAML(s[:end+1])
+		return value, s[end+1:], err
+	}
+
+	// Скаляр без кавычек; внутри [] и {} заканчивается на , ] }
+	end := len(s)
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == ',' || c == ']' || c == '}' {
+			end = i
+			break
+		}
+	}
+	plain := strings.TrimSpace(s[:end])
+	if err := checkYAMLPlain(plain); err != nil {
+		return nil, "", err
+	}
+	return parseYAMLScalar(plain), s[end:], nil
+}
+
+// checkYAMLPlain отклоняет скаляры без кавычек, которые в YAML означают
+// что-то другое или недопустимы, вместо того чтобы молча принять их как текст
+func checkYAMLPlain(s string) error {
+	if s == "" {
+		return nil
+	}
+	switch s[0] {
+	case '&', '*':
+		return fmt.Errorf("якоря и ссылки не поддерживаются: %q", s)
+	case '!':
+		return fmt.Errorf("теги не поддерживаются: %q", s)
+	case '%', '@', '`', '?', '|', '>':
+		return fmt.Errorf("значение не может начинаться с %q; заключите его в кавычки", s[0])
+	}
+	if strings.Contains(s, ": ") || strings.HasSuffix(s, ":") {
+		return fmt.Errorf("значение %q содержит \": \"; заключите его в кавычки", s)
+	}
+	return nil
+}
+
+// parseYAMLScalar определяет тип скаляра без кавычек
+func parseYAMLScalar(s string) interface{} {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return n
+	}
+	if strings.ContainsAny(s, ".eE") && strings.IndexFunc(s, func(r rune) bool {
+		return !strings.ContainsRune("0123456789.eE+-", r)
+	}) < 0 {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return s
+}
+
+func parseYAMLKey(s string) (string, error) {
+	if s == "" {
+		return "", fmt.Errorf("пустой ключ")
+	}
+	if s[0] == '"' || s[0] == '\'' {
+		return unquoteYAML(s)
+	}
+	return s, nil
+}
+
+// unquoteYAML снимает кавычки: внутри одинарных удвоенная кавычка
+// означает одну, внутри двойных действуют escape-последовательности YAML
+func unquoteYAML(s string) (string, error) {
+	if s[0] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	var b strings.Builder
+	body := s[1 : len(s)-1]
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i == len(body) {
+			return "", fmt.Errorf("некорректная строка %s", s)
+		}
+		if r, ok := yamlEscapes[body[i]]; ok {
+			b.WriteRune(r)
+			continue
+		}
+		digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[body[i]]
+		if digits == 0 {
+			return "", fmt.Errorf("неизвестная escape-последовательность \\%c в строке %s", body[i], s)
+		}
+		if i+digits >= len(body) {
+			return "", fmt.Errorf("некорректная строка %s", s)
+		}
+		code, err := strconv.ParseUint(body[i+1:i+1+digits], 16, 32)
+		if err != nil {
+			return "", fmt.Errorf("некорректная escape-последовательность \\%s в строке %s", body[i:i+1+digits], s)
+		}
+		b.WriteRune(rune(code))
+		i += digits
+	}
+	return b.String(), nil
+}
+
+// yamlEscapes - односимвольные escape-последовательности YAML 1.2
+var yamlEscapes = map[byte]rune{
+	'0': 0, 'a': '\a', 'b': '\b', 't': '\t', '\t': '\t', 'n': '\n', 'v': '\v',
+	'f': '\f', 'r': '\r', 'e': 0x1b, ' ': ' ', '"': '"', '/': '/', '\\': '\\',
+	'N': 0x85, '_': 0xa0, 'L': 0x2028, 'P': 0x2029,
+}
+
+// yamlQuoteEnd возвращает индекс закрывающей кавычки строки s
+func yamlQuoteEnd(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case quote == '\'' && s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// yamlKeyEnd возвращает индекс двоеточия после ключа или -1, если строка
+// не является парой ключ: значение
+func yamlKeyEnd(s string) int {
+	if s == "" || s[0] == '[' || s[0] == '{' {
+		return -1
+	}
+	start := 0
+	if s[0] == '"' || s[0] == '\'' {
+		if start = yamlQuoteEnd(s); start < 0 {
+			return -1
+		}
+	}
+	for i := start; i < len(s); i++ {
+		if s[i] == ':' && (i+1 == len(s) || s[i+1] == ' ') {
+			return i
+		}
+	}
+	return -1
+}
+
+func isYAMLSequenceItem(s string) bool {
+	return s == "-" || strings.HasPrefix(s, "- ")
+}
+
+// stripYAMLComment удаляет комментарий: # в начале строки или после пробела
+// вне кавычек
+func stripYAMLComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			// Кавычка открывает строку только в начале значения
+			if i == 0 || strings.ContainsRune(" [{,:-", rune(s[i-1])) {
+				quote = c
+			}
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
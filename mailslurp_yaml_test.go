@@ -0,0 +1,129 @@
+package mailslurp
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestParseYAML проверяет поддерживаемое подмножество YAML
+func TestParseYAML(t *testing.T) {
+	doc := `---
+# Комментарий
+name: Проверка письма   # комментарий после значения
+count: 3
+ratio: 0.5
+enabled: true
+empty:
+nothing: ~
+quoted: "a # не комментарий: \"x\"\n"
+single: 'it''s'
+subject: "Re: hello, world"
+escapes: "a\/b\tc\u00e9\x41"
+url: http://localhost:8080/a?b=c#frag
+tags: [signup, "two words", 3]
+inline: {a: 1, b: [x, y], c: "}"}
+list:
+- first
+- second
+nested:
+  steps:
+    - create_inbox:
+        as: user
+      name: Создать ящик
+    - wait_for_email: {inbox: "${user.id}"}
+    -
+      deep: value
+    - - inner
+      - list
+literal: |
+  Строка 1
+    с отступом
+
+  Строка 3
+folded: >-
+  сложенный
+  текст
+
+  абзац
+keep: |+
+  текст
+
+last: end
+`
+	got, err := ParseYAML([]byte(doc))
+	if err != nil {
+		t.Fatalf("ParseYAML: %v", err)
+	}
+	want := map[string]interface{}{
+		"name":    "Проверка письма",
+		"count":   3,
+		"ratio":   0.5,
+		"enabled": true,
+		"empty":   nil,
+		"nothing": nil,
+		"quoted":  "a # не комментарий: \"x\"\n",
+		"single":  "it's",
+		"subject": "Re: hello, world",
+		"escapes": "a/b\tcéA",
+		"url":     "http://localhost:8080/a?b=c#frag",
+		"tags":    []interface{}{"signup", "two words", 3},
+		"inline":  map[string]interface{}{"a": 1, "b": []interface{}{"x", "y"}, "c": "}"},
+		"list":    []interface{}{"first", "second"},
+		"nested": map[string]interface{}{
+			"steps": []interface{}{
+				map[string]interface{}{"create_inbox": map[string]interface{}{"as": "user"}, "name": "Создать ящик"},
+				map[string]interface{}{"wait_for_email": map[string]interface{}{"inbox": "${user.id}"}},
+				map[string]interface{}{"deep": "value"},
+				[]interface{}{"inner", "list"},
+			},
+		},
+		"literal": "Строка 1\n  с отступом\n\nСтрока 3\n",
+		"folded":  "сложенный текст\nабзац",
+		"keep":    "текст\n\n",
+		"last":    "end",
+	}
+	if !reflect.DeepEqual(got, want) {
+		for key, value := range want {
+			if !reflect.DeepEqual(got.(map[string]interface{})[key], value) {
+				t.Errorf("%s: получено %#v, ожидалось %#v", key, got.(map[string]interface{})[key], value)
+			}
+		}
+	}
+}
+
+// TestParseYAMLErrors проверяет сообщения об ошибках с номером строки
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		line int
+	}{
+		{"лишний отступ", "a: 1\n   b: 2\n", 2},
+		{"повтор ключа", "a: 1\na: 2\n", 2},
+		{"табуляция", "a:\n\tb: 1\n", 2},
+		{"незакрытая кавычка", "a: \"abc\n", 1},
+		{"незакрытый список", "a: [1, 2\n", 1},
+		{"список в словаре", "a: 1\n- b\n", 2},
+		{"двоеточие в значении", "a: 1\nkey: value: x\n", 2},
+		{"двоеточие в списке", "a: [b: c]\n", 1},
+		{"неизвестный escape", "a: 1\nb: \"\\q\"\n", 2},
+		{"якорь", "a: &x 1\n", 1},
+		{"ссылка", "a: 1\nb: *x\n", 2},
+		{"тег", "a: !!str 1\n", 1},
+		{"несколько документов", "a: 1\n---\nb: 2\n", 2},
+		{"многострочный скаляр", "a: первая\n  вторая\n", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseYAML([]byte(tt.doc))
+			var yamlErr *YAMLError
+			if !errors.As(err, &yamlErr) {
+				t.Fatalf("Ожидалась YAMLError, получено: %v", err)
+			}
+			if yamlErr.Line != tt.line {
+				t.Errorf("Неверная строка ошибки: %d, ожидалась %d (%v)", yamlErr.Line, tt.line, err)
+			}
+		})
+	}
+}
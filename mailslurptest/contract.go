@@ -0,0 +1,264 @@
+package mailslurptest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	mailslurp "mailslurp-example"
+)
+
+// contractWaitTimeout - сколько контракт ждет письмо, которое должно прийти
+const contractWaitTimeout = 10 * time.Second
+
+// contractNoEmailTimeout - сколько контракт ждет, убеждаясь, что письма нет
+const contractNoEmailTimeout = 200 * time.Millisecond
+
+// ClientFactory создает новый клиент для одного подтеста контракта.
+// Освобождать ресурсы следует через t.Cleanup.
+type ClientFactory func(t *testing.T) mailslurp.MailSlurpClient
+
+// RunClientContract проверяет, что реализация MailSlurpClient ведет себя так же,
+// как настоящий API: создание, список и удаление ящиков, доставка писем,
+// непрочитанные письма в WaitForLatestEmail, порядок писем, ошибки ErrNotFound
+// для удаленных ящиков и параллельное использование. Каждая реализация в
+// репозитории должна проходить этот набор.
+func RunClientContract(t *testing.T, factory ClientFactory) {
+	t.Run("CreateListDelete", func(t *testing.T) {
+		client := factory(t)
+		first := contractCreateInbox(t, client)
+		second := contractCreateInbox(t, client)
+		if first.ID == second.ID || first.EmailAddress == second.EmailAddress {
+			t.Errorf("Ящики должны быть уникальными: %+v, %+v", first, second)
+		}
+
+		inboxes, err := client.GetInboxes()
+		if err != nil {
+			t.Fatalf("GetInboxes: %v", err)
+		}
+		if !containsInbox(inboxes, first.ID) || !containsInbox(inboxes, second.ID) {
+			t.Errorf("GetInboxes не вернул созданные ящики: %+v", inboxes)
+		}
+
+		if err := client.DeleteInbox(first.ID); err != nil {
+			t.Fatalf("DeleteInbox: %v", err)
+		}
+		inboxes, err = client.GetInboxes()
+		if err != nil {
+			t.Fatalf("GetInboxes: %v", err)
+		}
+		if containsInbox(inboxes, first.ID) || !containsInbox(inboxes, second.ID) {
+			t.Errorf("После удаления должен остаться только второй ящик: %+v", inboxes)
+		}
+	})
+
+	t.Run("CreateWithOptions", func(t *testing.T) {
+		client := factory(t)
+		inbox, err := client.CreateInboxWithOptions(mailslurp.CreateInboxOptions{
+			Name: "contract", Description: "проверка контракта", Tags: []string{"contract"},
+		})
+		if err != nil {
+			t.Fatalf("CreateInboxWithOptions: %v", err)
+		}
+		t.Cleanup(func() { client.DeleteInbox(inbox.ID) })
+		if inbox.Name != "contract" || len(inbox.Tags) != 1 || inbox.Tags[0] != "contract" {
+			t.Errorf("Параметры ящика не сохранены: %+v", inbox)
+		}
+		if _, err := client.CreateInboxWithOptions(mailslurp.CreateInboxOptions{LocalPart: "no-domain"}); err == nil {
+			t.Error("LocalPart без Domain должен вернуть ошибку")
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		client := factory(t)
+		inbox := contractCreateInbox(t, client)
+		if err := client.DeleteInbox(inbox.ID); err != nil {
+			t.Fatalf("DeleteInbox: %v", err)
+		}
+		if err := client.DeleteInbox(inbox.ID); !errors.Is(err, mailslurp.ErrNotFound) {
+			t.Errorf("Повторное удаление должно вернуть ErrNotFound, получено: %v", err)
+		}
+		if err := client.SendEmail(inbox.ID, "user@example.com", "тема", "тело"); !errors.Is(err, mailslurp.ErrNotFound) {
+			t.Errorf("Отправка из удаленного ящика должна вернуть ErrNotFound, получено: %v", err)
+		}
+		if _, err := client.GetEmails(inbox.ID); !errors.Is(err, mailslurp.ErrNotFound) {
+			t.Errorf("Письма удаленного ящика должны вернуть ErrNotFound, получено: %v", err)
+		}
+		start := time.Now()
+		if _, err := client.WaitForLatestEmail(inbox.ID, contractWaitTimeout); !errors.Is(err, mailslurp.ErrNotFound) {
+			t.Errorf("Ожидание письма в удаленном ящике должно вернуть ErrNotFound, получено: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > contractWaitTimeout/2 {
+			t.Errorf("Ошибка для удаленного ящика должна возвращаться сразу, а не через %v", elapsed)
+		}
+	})
+
+	t.Run("SendReceive", func(t *testing.T) {
+		client := factory(t)
+		sender := contractCreateInbox(t, client)
+		recipient := contractCreateInbox(t, client)
+
+		if err := client.SendEmail(sender.ID, recipient.EmailAddress, "Контракт", "Тело письма"); err != nil {
+			t.Fatalf("SendEmail: %v", err)
+		}
+		email, err := client.WaitForLatestEmail(recipient.ID, contractWaitTimeout)
+		if err != nil {
+			t.Fatalf("WaitForLatestEmail: %v", err)
+		}
+		if email.ID == "" || email.Subject != "Контракт" || email.Body != "Тело письма" {
+			t.Errorf("Неверное письмо: %+v", email)
+		}
+		if email.From != sender.EmailAddress {
+			t.Errorf("Неверный отправитель: ожидалось %s, получено %s", sender.EmailAddress, email.From)
+		}
+		if len(email.To) == 0 || email.To[0] != recipient.EmailAddress {
+			t.Errorf("Неверные получатели: %v", email.To)
+		}
+	})
+
+	t.Run("UnreadOnly", func(t *testing.T) {
+		client := factory(t)
+		inbox := contractCreateInbox(t, client)
+
+		if _, err := client.WaitForLatestEmail(inbox.ID, contractNoEmailTimeout); err == nil {
+			t.Error("WaitForLatestEmail в пустом ящике должен вернуть ошибку по таймауту")
+		}
+
+		contractSend(t, client, inbox, "Первое")
+		first, err := client.WaitForLatestEmail(inbox.ID, contractWaitTimeout)
+		if err != nil || first.Subject != "Первое" {
+			t.Fatalf("Первое письмо не получено: %+v, %v", first, err)
+		}
+		if _, err := client.WaitForLatestEmail(inbox.ID, contractNoEmailTimeout); err == nil {
+			t.Error("Прочитанное письмо не должно возвращаться повторно")
+		}
+
+		contractSend(t, client, inbox, "Второе")
+		second, err := client.WaitForLatestEmail(inbox.ID, contractWaitTimeout)
+		if err != nil || second.Subject != "Второе" || second.ID == first.ID {
+			t.Fatalf("Второе письмо не получено: %+v, %v", second, err)
+		}
+	})
+
+	t.Run("Ordering", func(t *testing.T) {
+		client := factory(t)
+		inbox := contractCreateInbox(t, client)
+		subjects := []string{"Первое", "Второе", "Третье"}
+		for _, subject := range subjects {
+			contractSend(t, client, inbox, subject)
+		}
+
+		var emails []mailslurp.Email
+		deadline := time.Now().Add(contractWaitTimeout)
+		for {
+			var err error
+			emails, err = client.GetEmails(inbox.ID)
+			if err != nil {
+				t.Fatalf("GetEmails: %v", err)
+			}
+			if len(emails) >= len(subjects) || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		if len(emails) != len(subjects) {
+			t.Fatalf("Ожидалось %d писем, получено %d", len(subjects), len(emails))
+		}
+		for i, subject := range subjects {
+			if emails[i].Subject != subject {
+				t.Errorf("GetEmails должен возвращать письма от старых к новым: %d: %s", i, emails[i].Subject)
+			}
+		}
+
+		// Без прочтения WaitForLatestEmail возвращает самое новое письмо
+		latest, err := client.WaitForLatestEmail(inbox.ID, contractWaitTimeout)
+		if err != nil || latest.Subject != "Третье" {
+			t.Errorf("Ожидалось самое новое письмо: %+v, %v", latest, err)
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		client := factory(t)
+		const n = 8
+		inboxes := make([]*mailslurp.Inbox, n)
+		errs := make([]error, n)
+
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				inboxes[i], errs[i] = client.CreateInboxWithOptions(mailslurp.CreateInboxOptions{Name: fmt.Sprintf("contract-%d", i)})
+			}(i)
+		}
+		wg.Wait()
+
+		ids := map[string]bool{}
+		for i, inbox := range inboxes {
+			if errs[i] != nil {
+				t.Fatalf("CreateInbox %d: %v", i, errs[i])
+			}
+			t.Cleanup(func() { client.DeleteInbox(inbox.ID) })
+			ids[inbox.ID] = true
+		}
+		if len(ids) != n {
+			t.Fatalf("Ожидалось %d уникальных ящиков, получено %d", n, len(ids))
+		}
+
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				inbox := inboxes[i]
+				subject := fmt.Sprintf("Параллельно %d", i)
+				if errs[i] = client.SendEmail(inbox.ID, inbox.EmailAddress, subject, "тело"); errs[i] != nil {
+					return
+				}
+				email, err := client.WaitForLatestEmail(inbox.ID, contractWaitTimeout)
+				if err == nil && email.Subject != subject {
+					err = fmt.Errorf("получено чужое письмо %q вместо %q", email.Subject, subject)
+				}
+				errs[i] = err
+			}(i)
+		}
+		wg.Wait()
+		for i, err := range errs {
+			if err != nil {
+				t.Errorf("Ящик %d: %v", i, err)
+			}
+		}
+	})
+}
+
+// contractCreateInbox создает ящик и удаляет его по завершении подтеста
+func contractCreateInbox(t *testing.T, client mailslurp.MailSlurpClient) *mailslurp.Inbox {
+	t.Helper()
+	inbox, err := client.CreateInbox()
+	if err != nil {
+		t.Fatalf("CreateInbox: %v", err)
+	}
+	if inbox.ID == "" || inbox.EmailAddress == "" {
+		t.Fatalf("CreateInbox вернул ящик без ID или адреса: %+v", inbox)
+	}
+	t.Cleanup(func() { client.DeleteInbox(inbox.ID) })
+	return inbox
+}
+
+// contractSend отправляет письмо самому себе
+func contractSend(t *testing.T, client mailslurp.MailSlurpClient, inbox *mailslurp.Inbox, subject string) {
+	t.Helper()
+	if err := client.SendEmail(inbox.ID, inbox.EmailAddress, subject, "тело: "+subject); err != nil {
+		t.Fatalf("SendEmail: %v", err)
+	}
+}
+
+func containsInbox(inboxes []mailslurp.Inbox, id string) bool {
+	for _, inbox := range inboxes {
+		if inbox.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,440 @@
+// Package mailslurptest содержит тестовые двойники для пакета mailslurp:
+// потокобезопасный MockClient в памяти и набор RunClientContract, который
+// проверяет реализации MailSlurpClient на соответствие поведению API.
+package mailslurptest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	mailslurp "mailslurp-example"
+)
+
+// MockCall описывает один вызов MockClient
+type MockCall struct {
+	Method string
+	Args   []interface{}
+	Err    error
+	Time   time.Time
+}
+
+// MockClient представляет собой потокобезопасную реализацию MailSlurpClient
+// в памяти для тестов. Нулевое значение готово к использованию.
+//
+// WaitForLatestEmail блокируется до прихода непрочитанного письма или таймаута,
+// как настоящий API с unreadOnly=true. Ошибки задаются через FailOn и FailNext,
+// входящие письма добавляются через Deliver и DeliverMessage, а все вызовы
+// записываются и доступны через Calls.
+type MockClient struct {
+	mu          sync.Mutex
+	inboxes     []mailslurp.Inbox
+	emails      map[string][]mailslurp.Email
+	sent        []mailslurp.Email
+	calls       []MockCall
+	counts      map[string]int
+	failures    map[string]map[int]error
+	next        map[string][]error
+	attachments map[string][]byte
+	changed     chan struct{}
+	inboxSeq    int
+	emailSeq    int
+}
+
+// NewMockClient создает мок с заданными почтовыми ящиками
+func NewMockClient(inboxes ...mailslurp.Inbox) *MockClient {
+	m := &MockClient{}
+	for _, inbox := range inboxes {
+		m.AddInbox(inbox)
+	}
+	return m
+}
+
+// init лениво создает внутренние структуры; вызывается под m.mu
+func (m *MockClient) init() {
+	if m.emails == nil {
+		m.emails = map[string][]mailslurp.Email{}
+		m.counts = map[string]int{}
+		m.failures = map[string]map[int]error{}
+		m.next = map[string][]error{}
+		m.attachments = map[string][]byte{}
+		m.changed = make(chan struct{})
+	}
+}
+
+// FailOn задает ошибку для n-го вызова метода (нумерация с 1), например
+// FailOn("CreateInbox", 3, ErrPlanLimitExceeded)
+func (m *MockClient) FailOn(method string, n int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.init()
+	if m.failures[method] == nil {
+		m.failures[method] = map[int]error{}
+	}
+	m.failures[method][n] = err
+}
+
+// FailNext задает ошибку для следующего вызова метода.
+// Несколько вызовов подряд ставят ошибки в очередь.
+func (m *MockClient) FailNext(method string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.init()
+	m.next[method] = append(m.next[method], err)
+}
+
+// AddInbox добавляет существующий почтовый ящик
+func (m *MockClient) AddInbox(inbox mailslurp.Inbox) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.init()
+	if inbox.CreatedAt.IsZero() {
+		inbox.CreatedAt = time.Now()
+	}
+	m.inboxes = append(m.inboxes, inbox)
+}
+
+// Deliver помещает входящее письмо в ящик и будит ожидающие WaitForLatestEmail.
+// Пустые ID, To и Created заполняются автоматически.
+func (m *MockClient) Deliver(inboxID string, email mailslurp.Email) mailslurp.Email {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.init()
+	return m.deliverLocked(inboxID, email)
+}
+
+// AddAttachment задает содержимое вложения, которое возвращает
+// DownloadAttachment. ID вложения указывается в Email.Attachments.
+func (m *MockClient) AddAttachment(attachmentID string, data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.init()
+	m.attachments[attachmentID] = append([]byte(nil), data...)
+}
+
+// DeliverMessage помещает во входящие письмо с заданными отправителем, темой и телом
+func (m *MockClient) DeliverMessage(inboxID, from, subject, body string) mailslurp.Email {
+	return m.Deliver(inboxID, mailslurp.Email{From: from, Subject: subject, Body: body})
+}
+
+func (m *MockClient) deliverLocked(inboxID string, email mailslurp.Email) mailslurp.Email {
+	m.emailSeq++
+	if email.ID == "" {
+		email.ID = fmt.Sprintf("mock-email-%d", m.emailSeq)
+	}
+	if email.Created.IsZero() {
+		email.Created = time.Now()
+	}
+	if len(email.To) == 0 {
+		if inbox := m.findLocked(inboxID); inbox != nil {
+			email.To = []string{inbox.EmailAddress}
+		}
+	}
+	m.emails[inboxID] = append(m.emails[inboxID], email)
+	close(m.changed)
+	m.changed = make(chan struct{})
+	return email
+}
+
+// Sent возвращает письма, отправленные через SendEmail
+func (m *MockClient) Sent() []mailslurp.Email {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]mailslurp.Email(nil), m.sent...)
+}
+
+// Calls возвращает все записанные вызовы в порядке их выполнения
+func (m *MockClient) Calls() []MockCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MockCall(nil), m.calls...)
+}
+
+// CallsTo возвращает записанные вызовы метода
+func (m *MockClient) CallsTo(method string) []MockCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var calls []MockCall
+	for _, call := range m.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// CallCount возвращает количество вызовов метода
+func (m *MockClient) CallCount(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[method]
+}
+
+// ResetCalls очищает записанные вызовы; заданные ошибки и данные сохраняются
+func (m *MockClient) ResetCalls() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+	m.counts = map[string]int{}
+}
+
+// begin записывает вызов и возвращает заданную для него ошибку; вызывается под m.mu
+func (m *MockClient) begin(method string, args ...interface{}) error {
+	_, err := m.record(method, args...)
+	return err
+}
+
+// record записывает вызов и возвращает его номер в m.calls и заданную ошибку
+func (m *MockClient) record(method string, args ...interface{}) (int, error) {
+	m.init()
+	m.counts[method]++
+	var err error
+	if queue := m.next[method]; len(queue) > 0 {
+		err = queue[0]
+		m.next[method] = queue[1:]
+	} else if scripted, ok := m.failures[method][m.counts[method]]; ok {
+		err = scripted
+	}
+	m.calls = append(m.calls, MockCall{Method: method, Args: args, Err: err, Time: time.Now()})
+	return len(m.calls) - 1, err
+}
+
+// finish дописывает в вызов с номером i ошибку, возникшую при выполнении; вызывается под m.mu
+func (m *MockClient) finish(i int, err error) error {
+	if i < len(m.calls) {
+		m.calls[i].Err = err
+	}
+	return err
+}
+
+func (m *MockClient) findLocked(inboxID string) *mailslurp.Inbox {
+	for i := range m.inboxes {
+		if m.inboxes[i].ID == inboxID {
+			return &m.inboxes[i]
+		}
+	}
+	return nil
+}
+
+// mockAPIError формирует ошибку в том же виде, что и настоящий клиент
+func mockAPIError(status int, code, message string) error {
+	return &mailslurp.APIError{StatusCode: status, ErrorCode: code, Message: message}
+}
+
+// notFoundError - ошибка API для отсутствующего ящика
+func notFoundError(inboxID string) error {
+	return mockAPIError(http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("почтовый ящик с ID %s не найден", inboxID))
+}
+
+// GetInboxes возвращает копию списка почтовых ящиков
+func (m *MockClient) GetInboxes() ([]mailslurp.Inbox, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.begin("GetInboxes"); err != nil {
+		return nil, err
+	}
+	return append([]mailslurp.Inbox{}, m.inboxes...), nil
+}
+
+// CreateInbox создает почтовый ящик mock-inbox-N с адресом mockN@example.com
+func (m *MockClient) CreateInbox() (*mailslurp.Inbox, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.begin("CreateInbox"); err != nil {
+		return nil, err
+	}
+	inbox := m.createLocked(mailslurp.CreateInboxOptions{})
+	return &inbox, nil
+}
+
+// CreateInboxWithOptions создает почтовый ящик с заданными параметрами
+func (m *MockClient) CreateInboxWithOptions(opts mailslurp.CreateInboxOptions) (*mailslurp.Inbox, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	call, err := m.record("CreateInboxWithOptions", opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, m.finish(call, err)
+	}
+	inbox := m.createLocked(opts)
+	return &inbox, nil
+}
+
+func (m *MockClient) createLocked(opts mailslurp.CreateInboxOptions) mailslurp.Inbox {
+	m.inboxSeq++
+	now := time.Now()
+	inbox := mailslurp.Inbox{
+		ID:           fmt.Sprintf("mock-inbox-%d", m.inboxSeq),
+		EmailAddress: fmt.Sprintf("mock%d@example.com", m.inboxSeq),
+		CreatedAt:    now,
+		Name:         opts.Name,
+		Description:  opts.Description,
+		Tags:         opts.Tags,
+		Favourite:    opts.Favourite,
+		InboxType:    opts.InboxType,
+		DomainID:     opts.DomainID,
+	}
+	switch {
+	case opts.EmailAddress != "":
+		inbox.EmailAddress = opts.EmailAddress
+	case opts.LocalPart != "":
+		inbox.EmailAddress = opts.LocalPart + "@" + opts.Domain
+	case opts.Domain != "":
+		inbox.EmailAddress = fmt.Sprintf("mock%d@%s", m.inboxSeq, opts.Domain)
+	}
+	if !opts.ExpiresAt.IsZero() {
+		expiresAt := opts.ExpiresAt
+		inbox.ExpiresAt = &expiresAt
+	} else if opts.ExpiresIn > 0 {
+		expiresAt := now.Add(opts.ExpiresIn)
+		inbox.ExpiresAt = &expiresAt
+	}
+	m.inboxes = append(m.inboxes, inbox)
+	return inbox
+}
+
+// DeleteInbox удаляет почтовый ящик вместе с письмами
+func (m *MockClient) DeleteInbox(inboxID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	call, err := m.record("DeleteInbox", inboxID)
+	if err != nil {
+		return err
+	}
+	for i, inbox := range m.inboxes {
+		if inbox.ID == inboxID {
+			m.inboxes = append(m.inboxes[:i], m.inboxes[i+1:]...)
+			delete(m.emails, inboxID)
+			return nil
+		}
+	}
+	return m.finish(call, mockAPIError(http.StatusNotFound, "NOT_FOUND",
+		fmt.Sprintf("почтовый ящик с ID %s не найден", inboxID)))
+}
+
+// DeleteAllInboxEmails удаляет все письма ящика
+func (m *MockClient) DeleteAllInboxEmails(inboxID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	call, err := m.record("DeleteAllInboxEmails", inboxID)
+	if err != nil {
+		return err
+	}
+	if m.findLocked(inboxID) == nil {
+		return m.finish(call, notFoundError(inboxID))
+	}
+	delete(m.emails, inboxID)
+	return nil
+}
+
+// SendEmail записывает письмо в Sent и доставляет его, если адрес получателя
+// принадлежит одному из ящиков мока
+func (m *MockClient) SendEmail(inboxID, to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	call, err := m.record("SendEmail", inboxID, to, subject, body)
+	if err != nil {
+		return err
+	}
+	sender := m.findLocked(inboxID)
+	if sender == nil {
+		return m.finish(call, notFoundError(inboxID))
+	}
+	email := mailslurp.Email{From: sender.EmailAddress, To: []string{to}, Subject: subject, Body: body, Created: time.Now()}
+	m.sent = append(m.sent, email)
+	for _, inbox := range m.inboxes {
+		if strings.EqualFold(inbox.EmailAddress, to) {
+			m.deliverLocked(inbox.ID, email)
+		}
+	}
+	return nil
+}
+
+// WaitForLatestEmail ожидает непрочитанное письмо и помечает его прочитанным.
+// По истечении таймаута возвращает ошибку API с кодом 408, как настоящий сервер,
+// а для отсутствующего ящика сразу возвращает ошибку 404.
+func (m *MockClient) WaitForLatestEmail(inboxID string, timeout time.Duration) (*mailslurp.Email, error) {
+	m.mu.Lock()
+	call, err := m.record("WaitForLatestEmail", inboxID, timeout)
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	if m.findLocked(inboxID) == nil {
+		defer m.mu.Unlock()
+		return nil, m.finish(call, notFoundError(inboxID))
+	}
+	m.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		m.mu.Lock()
+		emails := m.emails[inboxID]
+		for i := len(emails) - 1; i >= 0; i-- {
+			if !emails[i].Read {
+				emails[i].Read = true
+				email := emails[i]
+				m.mu.Unlock()
+				return &email, nil
+			}
+		}
+		changed := m.changed
+		m.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			return nil, m.finish(call, mockAPIError(http.StatusRequestTimeout, "TIMEOUT",
+				fmt.Sprintf("письма не найдены для почтового ящика с ID %s", inboxID)))
+		}
+	}
+}
+
+// GetEmails возвращает копию писем ящика в порядке получения или ошибку 404
+// для отсутствующего ящика
+func (m *MockClient) GetEmails(inboxID string) ([]mailslurp.Email, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	call, err := m.record("GetEmails", inboxID)
+	if err != nil {
+		return nil, err
+	}
+	if m.findLocked(inboxID) == nil {
+		return nil, m.finish(call, notFoundError(inboxID))
+	}
+	return append([]mailslurp.Email{}, m.emails[inboxID]...), nil
+}
+
+// DownloadAttachment возвращает содержимое вложения, заданное через AddAttachment
+func (m *MockClient) DownloadAttachment(emailID, attachmentID string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	call, err := m.record("DownloadAttachment", emailID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := m.attachments[attachmentID]
+	if !ok {
+		return nil, m.finish(call, mockAPIError(http.StatusNotFound, "NOT_FOUND",
+			fmt.Sprintf("вложение %s не найдено", attachmentID)))
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// GetAPIKey возвращает тестовый API ключ
+func (m *MockClient) GetAPIKey() string {
+	return "mock-api-key"
+}
+
+// GetBaseURL возвращает тестовый базовый URL
+func (m *MockClient) GetBaseURL() string {
+	return "https://mock-api.mailslurp.com"
+}
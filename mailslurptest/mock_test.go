@@ -0,0 +1,138 @@
+package mailslurptest
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	mailslurp "mailslurp-example"
+)
+
+// TestMockScriptedFailures проверяет заданные ошибки и запись вызовов
+func TestMockScriptedFailures(t *testing.T) {
+	mock := NewMockClient()
+	mock.FailOn("CreateInbox", 3, mailslurp.ErrPlanLimitExceeded)
+
+	for i := 1; i <= 2; i++ {
+		if _, err := mock.CreateInbox(); err != nil {
+			t.Fatalf("Вызов %d: неожиданная ошибка: %v", i, err)
+		}
+	}
+	if _, err := mock.CreateInbox(); !errors.Is(err, mailslurp.ErrPlanLimitExceeded) {
+		t.Errorf("Третий вызов должен вернуть ErrPlanLimitExceeded, получено: %v", err)
+	}
+	if _, err := mock.CreateInbox(); err != nil {
+		t.Errorf("Четвертый вызов должен пройти: %v", err)
+	}
+
+	mock.FailNext("DeleteInbox", mailslurp.ErrRateLimited)
+	if err := mock.DeleteInbox("mock-inbox-1"); !errors.Is(err, mailslurp.ErrRateLimited) {
+		t.Errorf("Ожидалась ErrRateLimited, получено: %v", err)
+	}
+	if err := mock.DeleteInbox("mock-inbox-1"); err != nil {
+		t.Errorf("Повторное удаление должно пройти: %v", err)
+	}
+	if err := mock.DeleteInbox("mock-inbox-1"); !errors.Is(err, mailslurp.ErrNotFound) {
+		t.Errorf("Удаление отсутствующего ящика должно вернуть ErrNotFound, получено: %v", err)
+	}
+
+	if got := mock.CallCount("CreateInbox"); got != 4 {
+		t.Errorf("Неверное количество вызовов CreateInbox: %d", got)
+	}
+	deletes := mock.CallsTo("DeleteInbox")
+	if len(deletes) != 3 || deletes[0].Args[0] != "mock-inbox-1" || !errors.Is(deletes[2].Err, mailslurp.ErrNotFound) {
+		t.Errorf("Неверно записаны вызовы DeleteInbox: %+v", deletes)
+	}
+	inboxes, _ := mock.GetInboxes()
+	if len(inboxes) != 2 {
+		t.Errorf("Ожидалось 2 ящика, получено %d", len(inboxes))
+	}
+}
+
+// TestMockWaitForLatestEmail проверяет блокирующее ожидание письма
+func TestMockWaitForLatestEmail(t *testing.T) {
+	mock := NewMockClient()
+	inbox, _ := mock.CreateInbox()
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		mock.DeliverMessage(inbox.ID, "app@example.com", "Код", "Ваш код 4242")
+	}()
+
+	start := time.Now()
+	email, err := mock.WaitForLatestEmail(inbox.ID, time.Second)
+	if err != nil {
+		t.Fatalf("Ошибка при ожидании письма: %v", err)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("WaitForLatestEmail должен ждать доставки")
+	}
+	if email.Subject != "Код" || email.To[0] != inbox.EmailAddress || !email.Read {
+		t.Errorf("Неверное письмо: %+v", email)
+	}
+
+	// Прочитанное письмо повторно не возвращается
+	var apiErr *mailslurp.APIError
+	if _, err := mock.WaitForLatestEmail(inbox.ID, 10*time.Millisecond); !errors.As(err, &apiErr) || apiErr.StatusCode != 408 {
+		t.Errorf("Ожидалась ошибка таймаута 408, получено: %v", err)
+	}
+
+	// Для отсутствующего ящика ошибка 404 возвращается сразу, без ожидания
+	start = time.Now()
+	if _, err := mock.WaitForLatestEmail("missing", time.Second); !errors.Is(err, mailslurp.ErrNotFound) || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Ожидалась немедленная ErrNotFound, получено: %v", err)
+	}
+	if _, err := mock.GetEmails("missing"); !errors.Is(err, mailslurp.ErrNotFound) {
+		t.Errorf("Ожидалась ErrNotFound, получено: %v", err)
+	}
+
+	// Письмо между ящиками мока доставляется получателю
+	other, _ := mock.CreateInbox()
+	if err := mock.SendEmail(inbox.ID, other.EmailAddress, "Привет", "Тело"); err != nil {
+		t.Fatalf("Ошибка при отправке письма: %v", err)
+	}
+	email, err = mailslurp.WaitForMatchingEmail(mock, other.ID, time.Second, mailslurp.SubjectContains("привет"))
+	if err != nil || email.From != inbox.EmailAddress {
+		t.Errorf("Письмо не доставлено: %+v, %v", email, err)
+	}
+	if len(mock.Sent()) != 1 {
+		t.Errorf("Ожидалось одно отправленное письмо")
+	}
+}
+
+// TestMockConcurrency проверяет параллельное использование мока (запускать с -race)
+func TestMockConcurrency(t *testing.T) {
+	mock := NewMockClient()
+	inbox, _ := mock.CreateInbox()
+
+	var wg sync.WaitGroup
+	received := make(chan string, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if email, err := mock.WaitForLatestEmail(inbox.ID, time.Second); err == nil {
+				received <- email.ID
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			mock.DeliverMessage(inbox.ID, "app@example.com", "Тема", "Тело")
+			mock.GetInboxes()
+		}()
+	}
+	wg.Wait()
+	close(received)
+
+	seen := map[string]bool{}
+	for id := range received {
+		if seen[id] {
+			t.Errorf("Письмо %s получено дважды", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != 10 {
+		t.Errorf("Ожидалось 10 писем, получено %d", len(seen))
+	}
+}
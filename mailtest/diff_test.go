@@ -0,0 +1,14 @@
+package mailtest
+
+import (
+	"testing"
+)
+
+// TestLineDiff проверяет построчную разницу
+func TestLineDiff(t *testing.T) {
+	got := lineDiff("Здравствуйте!\nВаш код: 1234\nСпасибо", "Здравствуйте!\nВаш код: 4321\nСпасибо")
+	want := "  Здравствуйте!\n- Ваш код: 1234\n+ Ваш код: 4321\n  Спасибо\n"
+	if got != want {
+		t.Errorf("Неверная разница:\n%s", got)
+	}
+}
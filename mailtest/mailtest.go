@@ -0,0 +1,182 @@
+// Package mailtest содержит помощники для тестов с MailSlurpClient: ящик на
+// время теста с удалением через t.Cleanup и проверки писем с понятными
+// сообщениями об ошибках.
+package mailtest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	mailslurp "mailslurp-example"
+)
+
+// Параметры удаления тестовых ящиков
+const (
+	testInboxDeleteAttempts = 3
+	testInboxDeleteDelay    = 500 * time.Millisecond
+	testInboxNameMaxLength  = 100
+)
+
+// TestInbox - почтовый ящик, созданный для теста, вместе с клиентом
+type TestInbox struct {
+	*mailslurp.Inbox
+	Client mailslurp.MailSlurpClient
+}
+
+// TestInboxOption настраивает параметры создания тестового ящика
+type TestInboxOption func(opts *mailslurp.CreateInboxOptions)
+
+// WithInboxName задает имя ящика вместо имени теста
+func WithInboxName(name string) TestInboxOption {
+	return func(opts *mailslurp.CreateInboxOptions) { opts.Name = name }
+}
+
+// WithInboxTags добавляет теги ящику
+func WithInboxTags(tags ...string) TestInboxOption {
+	return func(opts *mailslurp.CreateInboxOptions) { opts.Tags = append(opts.Tags, tags...) }
+}
+
+// WithInboxTTL задает время жизни ящика на случай, если удаление не сработает
+func WithInboxTTL(ttl time.Duration) TestInboxOption {
+	return func(opts *mailslurp.CreateInboxOptions) { opts.ExpiresIn = ttl }
+}
+
+// WithInboxOptions задает все параметры создания ящика сразу. Пустое имя
+// в base не заменяет имя теста, а теги base добавляются к заданным раньше
+// через WithInboxTags.
+func WithInboxOptions(base mailslurp.CreateInboxOptions) TestInboxOption {
+	return func(opts *mailslurp.CreateInboxOptions) {
+		name, tags := opts.Name, opts.Tags
+		*opts = base
+		if opts.Name == "" {
+			opts.Name = name
+		}
+		opts.Tags = append(append([]string(nil), tags...), base.Tags...)
+	}
+}
+
+// Inbox создает почтовый ящик с именем теста и удаляет его после теста
+// через tb.Cleanup с повторами. Если создать ящик не удалось, тест завершается.
+func Inbox(tb testing.TB, client mailslurp.MailSlurpClient, opts ...TestInboxOption) *TestInbox {
+	tb.Helper()
+	createOpts := mailslurp.CreateInboxOptions{Name: testInboxName(tb.Name())}
+	for _, opt := range opts {
+		opt(&createOpts)
+	}
+
+	inbox, err := client.CreateInboxWithOptions(createOpts)
+	if err != nil {
+		tb.Fatalf("Ошибка при создании почтового ящика %q: %v", createOpts.Name, err)
+	}
+	tb.Cleanup(func() {
+		if err := deleteTestInbox(client, inbox.ID); err != nil {
+			tb.Logf("Предупреждение: не удалось удалить почтовый ящик %s: %v", inbox.ID, err)
+		}
+	})
+	return &TestInbox{Inbox: inbox, Client: client}
+}
+
+// testInboxName превращает имя теста в имя ящика: TestSignup/ru -> TestSignup_ru
+func testInboxName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == ' ' || r == '#' {
+			return '_'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > testInboxNameMaxLength {
+		name = string(runes[:testInboxNameMaxLength])
+	}
+	return name
+}
+
+// deleteTestInbox удаляет ящик с повторами; уже удаленный ящик не считается ошибкой
+func deleteTestInbox(client mailslurp.MailSlurpClient, inboxID string) error {
+	var err error
+	delay := testInboxDeleteDelay
+	for attempt := 1; attempt <= testInboxDeleteAttempts; attempt++ {
+		err = client.DeleteInbox(inboxID)
+		if err == nil || errors.Is(err, mailslurp.ErrNotFound) {
+			return nil
+		}
+		if attempt < testInboxDeleteAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return err
+}
+
+// ExpectEmail ожидает письмо, подходящее под matcher. Если письмо не пришло,
+// тест завершается с перечнем писем, которые есть в ящике.
+func ExpectEmail(tb testing.TB, inbox *TestInbox, matcher mailslurp.EmailMatcher, timeout time.Duration) *mailslurp.Email {
+	tb.Helper()
+	email, err := mailslurp.WaitForMatchingEmail(inbox.Client, inbox.ID, timeout, matcher)
+	if err == nil {
+		return email
+	}
+	if !errors.Is(err, mailslurp.ErrEmailTimeout) {
+		tb.Fatalf("Ошибка при ожидании письма в ящике %s: %v", inbox.EmailAddress, err)
+	}
+	tb.Fatalf("Подходящее письмо не пришло в ящик %s за %s.\n%s",
+		inbox.EmailAddress, timeout, describeInboxEmails(inbox))
+	return nil
+}
+
+// ExpectNoEmail проверяет, что подходящее письмо не придет в течение within
+func ExpectNoEmail(tb testing.TB, inbox *TestInbox, matcher mailslurp.EmailMatcher, within time.Duration) {
+	tb.Helper()
+	email, err := mailslurp.WaitForMatchingEmail(inbox.Client, inbox.ID, within, matcher)
+	if err == nil {
+		tb.Fatalf("Неожиданное письмо в ящике %s:\n%s", inbox.EmailAddress, describeEmail(email))
+	}
+	if !errors.Is(err, mailslurp.ErrEmailTimeout) {
+		tb.Fatalf("Ошибка при ожидании письма в ящике %s: %v", inbox.EmailAddress, err)
+	}
+}
+
+// AssertSubject сравнивает тему письма с ожидаемой
+func AssertSubject(tb testing.TB, email *mailslurp.Email, want string) {
+	tb.Helper()
+	if email.Subject != want {
+		tb.Errorf("Тема письма %s не совпадает (-ожидалось +получено):\n%s", email.ID, lineDiff(want, email.Subject))
+	}
+}
+
+// AssertBody сравнивает тело письма с ожидаемым построчно
+func AssertBody(tb testing.TB, email *mailslurp.Email, want string) {
+	tb.Helper()
+	if email.Body != want {
+		tb.Errorf("Тело письма %s не совпадает (-ожидалось +получено):\n%s", email.ID, lineDiff(want, email.Body))
+	}
+}
+
+// describeInboxEmails перечисляет письма ящика для сообщения об ошибке
+func describeInboxEmails(inbox *TestInbox) string {
+	emails, err := inbox.Client.GetEmails(inbox.ID)
+	if err != nil {
+		return fmt.Sprintf("Не удалось получить письма ящика: %v", err)
+	}
+	if len(emails) == 0 {
+		return "Ящик пуст."
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Письма в ящике (%d), ни одно не подошло:", len(emails))
+	for i := range emails {
+		b.WriteString("\n")
+		b.WriteString(describeEmail(&emails[i]))
+	}
+	return b.String()
+}
+
+// describeEmail кратко описывает письмо: отправитель, тема и начало тела
+func describeEmail(email *mailslurp.Email) string {
+	body := strings.Join(strings.Fields(email.TextBody()), " ")
+	if runes := []rune(body); len(runes) > 120 {
+		body = string(runes[:120]) + "..."
+	}
+	return fmt.Sprintf("  - %s от %s, тема %q\n    %s", email.ID, email.From, email.Subject, body)
+}
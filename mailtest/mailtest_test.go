@@ -0,0 +1,132 @@
+package mailtest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mailslurp "mailslurp-example"
+	"mailslurp-example/mailslurptest"
+)
+
+// recordingTB перехватывает ошибки вспомогательных функций, чтобы проверить их текст
+type recordingTB struct {
+	testing.TB
+	name     string
+	mu       sync.Mutex
+	failures []string
+	cleanups []func()
+}
+
+func (r *recordingTB) Helper()      {}
+func (r *recordingTB) Name() string { return r.name }
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	panic(r)
+}
+
+func (r *recordingTB) Logf(format string, args ...interface{}) {
+	r.Errorf("лог: "+format, args...)
+}
+
+func (r *recordingTB) Cleanup(f func()) { r.cleanups = append(r.cleanups, f) }
+
+// run выполняет f, перехватывая Fatalf, и затем вызывает cleanup-функции
+func (r *recordingTB) run(f func()) {
+	func() {
+		defer func() {
+			if v := recover(); v != nil && v != r {
+				panic(v)
+			}
+		}()
+		f()
+	}()
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+// TestInboxCleanup проверяет имя ящика и удаление после теста
+func TestInboxCleanup(t *testing.T) {
+	mock := mailslurptest.NewMockClient()
+	tb := &recordingTB{TB: t, name: "TestSignup/ru locale"}
+
+	var inbox *TestInbox
+	tb.run(func() {
+		inbox = Inbox(tb, mock, WithInboxTags("e2e"))
+		// Первая попытка удаления падает, вторая проходит
+		mock.FailNext("DeleteInbox", mailslurp.ErrRateLimited)
+	})
+
+	if inbox.Name != "TestSignup_ru_locale" || len(inbox.Tags) != 1 {
+		t.Errorf("Неверные параметры ящика: %+v", inbox.Inbox)
+	}
+	if got := mock.CallCount("DeleteInbox"); got != 2 {
+		t.Errorf("Ожидалось 2 попытки удаления, получено %d", got)
+	}
+	if inboxes, _ := mock.GetInboxes(); len(inboxes) != 0 {
+		t.Errorf("Ящик не удален после теста")
+	}
+	if len(tb.failures) != 0 {
+		t.Errorf("Неожиданные ошибки: %v", tb.failures)
+	}
+}
+
+// TestInboxOptionsKeepTags проверяет, что WithInboxOptions не теряет теги,
+// заданные раньше через WithInboxTags
+func TestInboxOptionsKeepTags(t *testing.T) {
+	mock := mailslurptest.NewMockClient()
+	inbox := Inbox(t, mock, WithInboxTags("e2e"), WithInboxOptions(mailslurp.CreateInboxOptions{Tags: []string{"signup"}, ExpiresIn: time.Hour}))
+	if !strings.HasPrefix(inbox.Name, "TestInboxOptionsKeepTags") || strings.Join(inbox.Tags, ",") != "e2e,signup" {
+		t.Errorf("Неверные параметры ящика: %+v", inbox.Inbox)
+	}
+}
+
+// TestExpectEmail проверяет ожидание письма и сообщение о неудаче
+func TestExpectEmail(t *testing.T) {
+	mock := mailslurptest.NewMockClient()
+	inbox := Inbox(t, mock)
+
+	mock.DeliverMessage(inbox.ID, "shop@example.com", "Ваш заказ", "Заказ 42 оформлен")
+	email := ExpectEmail(t, inbox, mailslurp.SubjectContains("заказ"), time.Second)
+	AssertBody(t, email, "Заказ 42 оформлен")
+
+	tb := &recordingTB{TB: t, name: t.Name()}
+	tb.run(func() {
+		ExpectEmail(tb, inbox, mailslurp.SubjectContains("Подтвердите"), 50*time.Millisecond)
+	})
+	if len(tb.failures) != 1 {
+		t.Fatalf("Ожидалась одна ошибка, получено: %v", tb.failures)
+	}
+	for _, want := range []string{"не пришло", inbox.EmailAddress, `тема "Ваш заказ"`, "shop@example.com", "Заказ 42 оформлен"} {
+		if !strings.Contains(tb.failures[0], want) {
+			t.Errorf("В сообщении нет %q:\n%s", want, tb.failures[0])
+		}
+	}
+
+	tb = &recordingTB{TB: t, name: t.Name()}
+	tb.run(func() {
+		ExpectNoEmail(tb, inbox, mailslurp.FromContains("shop@"), 50*time.Millisecond)
+	})
+	if len(tb.failures) != 1 || !strings.Contains(tb.failures[0], "Неожиданное письмо") {
+		t.Errorf("Ожидалась ошибка о неожиданном письме: %v", tb.failures)
+	}
+}
+
+// TestAssertSubject проверяет сообщение с построчной разницей
+func TestAssertSubject(t *testing.T) {
+	tb := &recordingTB{TB: t, name: t.Name()}
+	AssertSubject(tb, &mailslurp.Email{ID: "email-1", Subject: "Привет"}, "Здравствуйте")
+	if len(tb.failures) != 1 || !strings.Contains(tb.failures[0], "- Здравствуйте\n+ Привет") {
+		t.Errorf("Неверное сообщение: %v", tb.failures)
+	}
+}